@@ -16,6 +16,40 @@ type DatabaseConfig struct {
 type Config struct {
 	MONGO_URL string `json:"MONGO_URL"`
 	PORT      string `json:"PORT"`
+	// IMPORT_DIR keeps uploaded import files until their job completes
+	IMPORT_DIR string `json:"IMPORT_DIR"`
+	// LORAWAN_WEBHOOK_TOKEN is the bearer token network servers send with
+	// uplinks; webhooks are not authenticated when it is empty
+	LORAWAN_WEBHOOK_TOKEN string `json:"LORAWAN_WEBHOOK_TOKEN"`
+	// ADMIN_TOKEN is the bearer token operators send to admin routes, such as
+	// confirming and refunding investments; those routes are closed when it
+	// is empty
+	ADMIN_TOKEN string `json:"ADMIN_TOKEN"`
+	// STREAM_ALLOWED_ORIGINS lists the origins, comma separated, browser pages
+	// may open event WebSockets from besides the API's own
+	STREAM_ALLOWED_ORIGINS string `json:"STREAM_ALLOWED_ORIGINS"`
+	// SHARE_LOCKUP_DAYS is how long owners hold new shares before they can
+	// sell or transfer them
+	SHARE_LOCKUP_DAYS int `json:"SHARE_LOCKUP_DAYS"`
+	// STELLAR_HORIZON_URL is the Horizon server share tokens live on; an
+	// in-memory ledger stands in when it is empty
+	STELLAR_HORIZON_URL string `json:"STELLAR_HORIZON_URL"`
+	// STELLAR_NETWORK_PASSPHRASE defaults to the test network
+	STELLAR_NETWORK_PASSPHRASE string `json:"STELLAR_NETWORK_PASSPHRASE"`
+	// STELLAR_ISSUER_SECRET is the secret seed of the account issuing share
+	// tokens; tokenization is disabled against Horizon without it
+	STELLAR_ISSUER_SECRET string `json:"STELLAR_ISSUER_SECRET"`
+	// STELLAR_TREASURY_SECRET is the secret seed of the account distributions
+	// are paid from; payouts are disabled against Horizon without it
+	STELLAR_TREASURY_SECRET string `json:"STELLAR_TREASURY_SECRET"`
+	// STELLAR_PAYMENT_ASSETS lists the CODE:ISSUER assets, comma separated,
+	// accepted as payment for investments in currencies other than XLM
+	STELLAR_PAYMENT_ASSETS string `json:"STELLAR_PAYMENT_ASSETS"`
+	// STELLAR_WEB_AUTH_SECRET is the secret seed signing login challenges; a
+	// key generated at startup is used when it is empty
+	STELLAR_WEB_AUTH_SECRET string `json:"STELLAR_WEB_AUTH_SECRET"`
+	// STELLAR_HOME_DOMAIN is the domain login challenges are issued for
+	STELLAR_HOME_DOMAIN string `json:"STELLAR_HOME_DOMAIN"`
 }
 
 // LoadConfig loads configuration from environment variables or a .env file.
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"0xFarms-backend/config"
+	"0xFarms-backend/internal/adapters"
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runImport implements the import subcommand, which imports a CSV or Parquet
+// file of historical readings, or resumes an earlier import, and reports
+// progress until it finishes
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	farmID := fs.String("farm", "", "farm to import the readings into")
+	path := fs.String("file", "", "CSV or Parquet file to import")
+	format := fs.String("format", "", "csv or parquet (default: from the file extension)")
+	mapping := fs.String("map", "", "comma-separated column=target pairs, targets being metric keys, timestamp or deviceId (default: match column names)")
+	units := fs.String("units", "", "comma-separated metric=unit pairs for values not in canonical units")
+	timezone := fs.String("tz", "", "IANA timezone of timestamps without an offset (default UTC)")
+	timestampFormat := fs.String("timestamp-format", "", "Go time layout, unix or unixms (default: common layouts)")
+	resume := fs.String("resume", "", "ID of a failed or cancelled import job to resume instead")
+	fs.Parse(args)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := adapters.NewMongoAdapter(cfg.MONGO_URL)
+	if err != nil {
+		return err
+	}
+	metricRegistry := services.NewMetricRegistry(db)
+	if err := metricRegistry.Load(); err != nil {
+		return err
+	}
+	farmService := services.NewFarmManagementSystemService(db, metricRegistry)
+	importService := services.NewImportService(db, farmService, metricRegistry, services.NewRollupService(db, farmService), cfg.IMPORT_DIR)
+
+	var job *domain.ImportJob
+	if *resume != "" {
+		if job, err = importService.Resume(*resume); err != nil {
+			return err
+		}
+	} else {
+		if *farmID == "" || *path == "" {
+			return errors.New("-farm and -file are required")
+		}
+		spec := domain.ImportJob{
+			FileName:        filepath.Base(*path),
+			Format:          *format,
+			Timezone:        *timezone,
+			TimestampFormat: *timestampFormat,
+		}
+		if spec.Mapping, err = parsePairs(*mapping); err != nil {
+			return fmt.Errorf("invalid -map: %v", err)
+		}
+		if spec.Units, err = parsePairs(*units); err != nil {
+			return fmt.Errorf("invalid -units: %v", err)
+		}
+
+		file, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		job, err = importService.Create(*farmID, spec, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	id := job.ID.Hex()
+	log.Printf("Import job %s started", id)
+
+	// Interrupting leaves the job queued so -resume picks it up
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		importService.Wait(id)
+		close(done)
+	}()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			log.Printf("Stopping after the current batch, resume with -resume %s", id)
+			importService.Shutdown()
+		case <-ticker.C:
+			if current, err := importService.Get(id); err == nil {
+				log.Printf("%.1f%%: %d rows read, %d imported, %d rejected", current.Progress, current.RowsRead, current.RowsImported, current.RowsRejected)
+			}
+			continue
+		case <-done:
+		}
+		break
+	}
+
+	final, err := importService.Get(id)
+	if err != nil {
+		return err
+	}
+	log.Printf("Import job %s %s: %d rows read, %d imported, %d duplicates, %d rejected",
+		id, final.Status, final.RowsRead, final.RowsImported, final.RowsDuplicate, final.RowsRejected)
+	if final.Error != "" {
+		return errors.New(final.Error)
+	}
+	return nil
+}
+
+// parsePairs parses comma-separated key=value pairs
+func parsePairs(s string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	if s == "" {
+		return pairs, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return pairs, nil
+}
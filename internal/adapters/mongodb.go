@@ -16,10 +16,41 @@ import (
 
 // BlogService handles blog operations
 type DB struct {
-	blogCollection     *mongo.Collection
-	farmCollection     *mongo.Collection
-	cropSpecCollection *mongo.Collection
-	userCollection     *mongo.Collection
+	client                    *mongo.Client
+	blogCollection            *mongo.Collection
+	farmCollection            *mongo.Collection
+	cropSpecCollection        *mongo.Collection
+	userCollection            *mongo.Collection
+	metricCollection          *mongo.Collection
+	rollupCollection          *mongo.Collection
+	alertRuleCollection       *mongo.Collection
+	alertCollection           *mongo.Collection
+	deviceCollection          *mongo.Collection
+	gapCollection             *mongo.Collection
+	commandCollection         *mongo.Collection
+	policyCollection          *mongo.Collection
+	decisionCollection        *mongo.Collection
+	dosingCollection          *mongo.Collection
+	readingCollection         *mongo.Collection
+	importCollection          *mongo.Collection
+	rejectionCollection       *mongo.Collection
+	facilityCollection        *mongo.Collection
+	meterCollection           *mongo.Collection
+	meterReadingCollection    *mongo.Collection
+	tariffCollection          *mongo.Collection
+	harvestCollection         *mongo.Collection
+	investmentCollection      *mongo.Collection
+	orderCollection           *mongo.Collection
+	tradeCollection           *mongo.Collection
+	distributionCollection    *mongo.Collection
+	ownershipEventCollection  *mongo.Collection
+	tokenCollection           *mongo.Collection
+	walletCollection          *mongo.Collection
+	walletChallengeCollection *mongo.Collection
+	sessionCollection         *mongo.Collection
+	payoutCollection          *mongo.Collection
+	paymentCollection         *mongo.Collection
+	paymentCursorCollection   *mongo.Collection
 }
 
 // NewBlogService creates a new instance of the blog service
@@ -43,13 +74,211 @@ func NewMongoAdapter(mongoURI string) (*DB, error) {
 	farmCollection := client.Database("0xFarms").Collection("vertical_farms")
 	cropSpecCollection := client.Database("0xFarms").Collection("crop_specs")
 	userCollection := client.Database("0xFarms").Collection("users")
+	metricCollection := client.Database("0xFarms").Collection("sensor_metrics")
+	rollupCollection := client.Database("0xFarms").Collection("reading_rollups")
+	alertRuleCollection := client.Database("0xFarms").Collection("alert_rules")
+	alertCollection := client.Database("0xFarms").Collection("alerts")
+	deviceCollection := client.Database("0xFarms").Collection("devices")
+	gapCollection := client.Database("0xFarms").Collection("data_gaps")
+	commandCollection := client.Database("0xFarms").Collection("actuator_commands")
+	policyCollection := client.Database("0xFarms").Collection("control_policies")
+	decisionCollection := client.Database("0xFarms").Collection("control_decisions")
+	dosingCollection := client.Database("0xFarms").Collection("dosing_recommendations")
+	readingCollection := client.Database("0xFarms").Collection("sensor_readings")
+	importCollection := client.Database("0xFarms").Collection("import_jobs")
+	rejectionCollection := client.Database("0xFarms").Collection("import_rejections")
+	facilityCollection := client.Database("0xFarms").Collection("facilities")
+	meterCollection := client.Database("0xFarms").Collection("meters")
+	meterReadingCollection := client.Database("0xFarms").Collection("meter_readings")
+	tariffCollection := client.Database("0xFarms").Collection("tariffs")
+	harvestCollection := client.Database("0xFarms").Collection("harvests")
+	investmentCollection := client.Database("0xFarms").Collection("investments")
+	orderCollection := client.Database("0xFarms").Collection("share_orders")
+	tradeCollection := client.Database("0xFarms").Collection("share_trades")
+	distributionCollection := client.Database("0xFarms").Collection("distributions")
+	ownershipEventCollection := client.Database("0xFarms").Collection("ownership_events")
+	tokenCollection := client.Database("0xFarms").Collection("farm_tokens")
+	walletCollection := client.Database("0xFarms").Collection("wallets")
+	walletChallengeCollection := client.Database("0xFarms").Collection("wallet_challenges")
+	sessionCollection := client.Database("0xFarms").Collection("auth_sessions")
+	payoutCollection := client.Database("0xFarms").Collection("payouts")
+	paymentCollection := client.Database("0xFarms").Collection("incoming_payments")
+	paymentCursorCollection := client.Database("0xFarms").Collection("payment_cursors")
+
+	// Rollups are upserted on every reading, keep their lookups indexed
+	_, err = rollupCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "farm_id", Value: 1}, {Key: "resolution", Value: 1}, {Key: "bucket_start", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create rollup index: %v", err))
+	}
+
+	// Archived readings are unique per source and time so re-imports skip them
+	_, err = readingCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "farm_id", Value: 1}, {Key: "timestamp", Value: 1}, {Key: "deviceid", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create reading index: %v", err))
+	}
+	_, err = rejectionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "job_id", Value: 1}, {Key: "row", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create import rejection index: %v", err))
+	}
+
+	// Uplinks are matched to devices by EUI, which only LoRaWAN devices have
+	_, err = deviceCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "dev_eui", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"dev_eui": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create device EUI index: %v", err))
+	}
+
+	_, err = meterReadingCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "meter_id", Value: 1}, {Key: "timestamp", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create meter reading index: %v", err))
+	}
+
+	_, err = orderCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "farm_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create share order index: %v", err))
+	}
+
+	// Each harvest is distributed once
+	_, err = distributionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "harvest_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create distribution index: %v", err))
+	}
+
+	_, err = ownershipEventCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "farm_id", Value: 1}, {Key: "occurred_at", Value: 1}},
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create ownership event index: %v", err))
+	}
+
+	for _, key := range []string{"farm_id", "asset_code"} {
+		_, err = tokenCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: key, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to create farm token %s index: %v", key, err))
+		}
+	}
+
+	// An account is bound to a single user
+	_, err = walletCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "account_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create wallet index: %v", err))
+	}
+
+	// Unanswered challenges expire on their own
+	_, err = walletChallengeCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create wallet challenge index: %v", err))
+	}
+
+	// Sessions are looked up by token, each challenge opens one, and they
+	// expire on their own
+	for _, key := range []string{"token_hash", "challenge_hash"} {
+		_, err = sessionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: key, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to create session %s index: %v", key, err))
+		}
+	}
+	_, err = sessionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create session expiry index: %v", err))
+	}
+
+	// Each distribution is paid out once
+	_, err = payoutCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "distribution_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create payout index: %v", err))
+	}
+
+	// Payments are recorded once however often they are read or imported
+	_, err = paymentCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "payment_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create payment index: %v", err))
+	}
+	_, err = paymentCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "investment_id", Value: 1}},
+	})
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to create payment investment index: %v", err))
+	}
 
 	logger.LogInfo(fmt.Sprintf("Successfully connected to database"))
 	return &DB{
-		blogCollection:     blogCollection,
-		farmCollection:     farmCollection,
-		cropSpecCollection: cropSpecCollection,
-		userCollection:     userCollection,
+		client:                    client,
+		blogCollection:            blogCollection,
+		farmCollection:            farmCollection,
+		cropSpecCollection:        cropSpecCollection,
+		userCollection:            userCollection,
+		metricCollection:          metricCollection,
+		rollupCollection:          rollupCollection,
+		alertRuleCollection:       alertRuleCollection,
+		alertCollection:           alertCollection,
+		deviceCollection:          deviceCollection,
+		gapCollection:             gapCollection,
+		commandCollection:         commandCollection,
+		policyCollection:          policyCollection,
+		decisionCollection:        decisionCollection,
+		dosingCollection:          dosingCollection,
+		readingCollection:         readingCollection,
+		importCollection:          importCollection,
+		rejectionCollection:       rejectionCollection,
+		facilityCollection:        facilityCollection,
+		meterCollection:           meterCollection,
+		meterReadingCollection:    meterReadingCollection,
+		tariffCollection:          tariffCollection,
+		harvestCollection:         harvestCollection,
+		investmentCollection:      investmentCollection,
+		orderCollection:           orderCollection,
+		tradeCollection:           tradeCollection,
+		distributionCollection:    distributionCollection,
+		ownershipEventCollection:  ownershipEventCollection,
+		tokenCollection:           tokenCollection,
+		walletCollection:          walletCollection,
+		walletChallengeCollection: walletChallengeCollection,
+		sessionCollection:         sessionCollection,
+		payoutCollection:          payoutCollection,
+		paymentCollection:         paymentCollection,
+		paymentCursorCollection:   paymentCursorCollection,
 	}, nil
 }
 
@@ -371,38 +600,107 @@ func (db *DB) GetFarm(id string) (*domain.VerticalFarm, error) {
 		return nil, err
 	}
 
+	// The document's own id field may hold a stale identifier, the
+	// ObjectID is the one every lookup uses
+	farm.ID = id
 	return &farm, nil
 }
 
-// UpdateFarm updates an existing vertical farm
-func (db *DB) UpdateFarm(id string, farm *domain.VerticalFarm) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// ListFarms retrieves every vertical farm without its stored readings
+func (db *DB) ListFarms() ([]domain.VerticalFarm, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	projection := bson.M{"iotdata": 0, "iot_data": 0}
+	cursor, err := db.farmCollection.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var farms []domain.VerticalFarm
+	for cursor.Next(ctx) {
+		var doc struct {
+			ObjectID            primitive.ObjectID `bson:"_id"`
+			domain.VerticalFarm `bson:",inline"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		doc.VerticalFarm.ID = doc.ObjectID.Hex()
+		farms = append(farms, doc.VerticalFarm)
 	}
 
-	farm.LastUpdated = time.Now()
+	return farms, cursor.Err()
+}
 
-	update := bson.M{
-		"$set": farm,
+// SetFarmFreshness records whether a farm's sensor data has gone stale
+func (db *DB) SetFarmFreshness(id string, stale bool, staleSince *time.Time) error {
+	return db.setFarmFields(id, bson.M{
+		"datastale":  stale,
+		"stalesince": staleSince,
+	})
+}
+
+// SetFarmStatus moves a farm to a lifecycle status
+func (db *DB) SetFarmStatus(id, status string) error {
+	return db.setFarmFields(id, bson.M{
+		"status":      status,
+		"lastupdated": time.Now(),
+	})
+}
+
+// SetFarmReportingInterval sets how often readings are expected from a farm
+func (db *DB) SetFarmReportingInterval(id string, seconds int) error {
+	return db.setFarmFields(id, bson.M{
+		"reportingintervalseconds": seconds,
+		"lastupdated":              time.Now(),
+	})
+}
+
+// SetFarmReservoir describes a farm's nutrient tank
+func (db *DB) SetFarmReservoir(id string, reservoir *domain.Reservoir) error {
+	return db.setFarmFields(id, bson.M{
+		"reservoir":   reservoir,
+		"lastupdated": time.Now(),
+	})
+}
+
+// SetFarmOffering sets the price of a farm's shares, closing it to
+// investment when offering is nil
+func (db *DB) SetFarmOffering(id string, offering *domain.Offering) error {
+	return db.setFarmFields(id, bson.M{
+		"offering":    offering,
+		"lastupdated": time.Now(),
+	})
+}
+
+// setFarmFields sets only the given fields of a farm, so changes made to its
+// other fields in the meantime are kept
+func (db *DB) setFarmFields(id string, fields bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
 	}
 
-	result, err := db.farmCollection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	result, err := db.farmCollection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": fields})
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return false, errors.New("farm not found")
+		return errors.New("farm not found")
 	}
 
-	return true, nil
+	return nil
 }
 
-// AddIoTReading adds a new IoT sensor reading to a farm
+// AddIoTReading appends a reading to a farm along with the health it scored.
+// The latest reading time only moves forward, so late readings do not make
+// the farm look fresher than it is.
 func (db *DB) AddIoTReading(farmID string, reading *domain.IoTReading) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -414,12 +712,27 @@ func (db *DB) AddIoTReading(farmID string, reading *domain.IoTReading) error {
 
 	update := bson.M{
 		"$push": bson.M{
-			"iot_data": reading,
+			"iotdata": reading,
+		},
+		"$set": bson.M{
+			"currenthealth": reading.CropHealth,
+			"lastupdated":   time.Now(),
+		},
+		"$max": bson.M{
+			"lastreadingat": reading.Timestamp,
 		},
 	}
 
-	_, err = db.farmCollection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	return err
+	result, err := db.farmCollection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("farm not found")
+	}
+
+	return nil
 }
 
 // GetCropSpecification retrieves a crop specification by type
@@ -438,3 +751,40 @@ func (db *DB) GetCropSpecification(cropType string) (domain.CropSpecification, e
 
 	return cropSpec, nil
 }
+
+// SaveCropSpecification creates or replaces a crop specification by name
+func (db *DB) SaveCropSpecification(spec *domain.CropSpecification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.cropSpecCollection.ReplaceOne(ctx, bson.M{"name": spec.Name}, spec, options.Replace().SetUpsert(true))
+	return err
+}
+
+// SaveMetricDefinition creates or replaces a sensor metric definition by key
+func (db *DB) SaveMetricDefinition(metric *domain.MetricDefinition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.metricCollection.ReplaceOne(ctx, bson.M{"key": metric.Key}, metric, options.Replace().SetUpsert(true))
+	return err
+}
+
+// RetrieveMetricDefinitions retrieves all sensor metrics registered at runtime
+func (db *DB) RetrieveMetricDefinitions() ([]domain.MetricDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.metricCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var metrics []domain.MetricDefinition
+	if err = cursor.All(ctx, &metrics); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
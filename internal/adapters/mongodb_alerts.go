@@ -0,0 +1,148 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveAlertRule stores a new alert rule
+func (db *DB) SaveAlertRule(rule *domain.AlertRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.alertRuleCollection.InsertOne(ctx, rule)
+	if err != nil {
+		return err
+	}
+
+	rule.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveAlertRules retrieves every alert rule
+func (db *DB) RetrieveAlertRules() ([]domain.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.alertRuleCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []domain.AlertRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// RemoveAlertRule deletes an alert rule
+func (db *DB) RemoveAlertRule(id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.alertRuleCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return false, err
+	}
+
+	if result.DeletedCount == 0 {
+		return false, errors.New("alert rule not found")
+	}
+
+	return true, nil
+}
+
+// SaveAlert stores a newly raised alert
+func (db *DB) SaveAlert(alert *domain.Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.alertCollection.InsertOne(ctx, alert)
+	if err != nil {
+		return err
+	}
+
+	alert.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateAlert replaces a stored alert
+func (db *DB) UpdateAlert(alert *domain.Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.alertCollection.ReplaceOne(ctx, bson.M{"_id": alert.ID}, alert)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("alert not found")
+	}
+
+	return nil
+}
+
+// RetrieveAlert retrieves a single alert by ID
+func (db *DB) RetrieveAlert(id string) (*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var alert domain.Alert
+	err = db.alertCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&alert)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("alert not found")
+		}
+		return nil, err
+	}
+
+	return &alert, nil
+}
+
+// RetrieveAlerts retrieves alerts matching the filters, newest first
+func (db *DB) RetrieveAlerts(filters *domain.AlertFilters) ([]domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filters.FarmID != "" {
+		query["farm_id"] = filters.FarmID
+	}
+	if filters.State != "" {
+		query["state"] = filters.State
+	}
+
+	cursor, err := db.alertCollection.Find(ctx, query, options.Find().SetSort(bson.M{"triggered_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var alerts []domain.Alert
+	if err = cursor.All(ctx, &alerts); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,109 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveCommand stores a new actuator command
+func (db *DB) SaveCommand(command *domain.ActuatorCommand) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.commandCollection.InsertOne(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	command.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateCommand replaces a stored actuator command
+func (db *DB) UpdateCommand(command *domain.ActuatorCommand) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.commandCollection.ReplaceOne(ctx, bson.M{"_id": command.ID}, command)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("command not found")
+	}
+
+	return nil
+}
+
+// RetrieveCommand retrieves a single actuator command by ID
+func (db *DB) RetrieveCommand(id string) (*domain.ActuatorCommand, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var command domain.ActuatorCommand
+	err = db.commandCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&command)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("command not found")
+		}
+		return nil, err
+	}
+
+	return &command, nil
+}
+
+// RetrieveCommands retrieves actuator commands matching the filters, oldest first
+func (db *DB) RetrieveCommands(filters *domain.CommandFilters) ([]domain.ActuatorCommand, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filters.FarmID != "" {
+		query["farm_id"] = filters.FarmID
+	}
+	if filters.DeviceID != "" {
+		query["device_id"] = filters.DeviceID
+	}
+	if filters.Output != "" {
+		query["output"] = filters.Output
+	}
+	if len(filters.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filters.Statuses}
+	}
+	created := bson.M{}
+	if !filters.From.IsZero() {
+		created["$gte"] = filters.From
+	}
+	if !filters.To.IsZero() {
+		created["$lt"] = filters.To
+	}
+	if len(created) > 0 {
+		query["created_at"] = created
+	}
+
+	cursor, err := db.commandCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var commands []domain.ActuatorCommand
+	if err = cursor.All(ctx, &commands); err != nil {
+		return nil, err
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,304 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveFacility stores a new facility
+func (db *DB) SaveFacility(facility *domain.Facility) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.facilityCollection.InsertOne(ctx, facility)
+	if err != nil {
+		return err
+	}
+
+	facility.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveFacility retrieves a single facility by ID
+func (db *DB) RetrieveFacility(id string) (*domain.Facility, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var facility domain.Facility
+	err = db.facilityCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&facility)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("facility not found")
+		}
+		return nil, err
+	}
+
+	return &facility, nil
+}
+
+// RetrieveFacilityOfFarm retrieves the facility a farm belongs to
+func (db *DB) RetrieveFacilityOfFarm(farmID string) (*domain.Facility, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var facility domain.Facility
+	err := db.facilityCollection.FindOne(ctx, bson.M{"farm_ids": farmID}).Decode(&facility)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("facility not found")
+		}
+		return nil, err
+	}
+
+	return &facility, nil
+}
+
+// SaveMeter registers a new utility meter
+func (db *DB) SaveMeter(meter *domain.Meter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.meterCollection.InsertOne(ctx, meter)
+	if err != nil {
+		return err
+	}
+
+	meter.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveMeter retrieves a single meter by ID
+func (db *DB) RetrieveMeter(id string) (*domain.Meter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var meter domain.Meter
+	err = db.meterCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&meter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("meter not found")
+		}
+		return nil, err
+	}
+
+	return &meter, nil
+}
+
+// RetrieveMeters retrieves the meters of a farm together with those of a facility.
+// Empty IDs are left out.
+func (db *DB) RetrieveMeters(farmID, facilityID string) ([]domain.Meter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var sources bson.A
+	if farmID != "" {
+		sources = append(sources, bson.M{"farm_id": farmID})
+	}
+	if facilityID != "" {
+		sources = append(sources, bson.M{"facility_id": facilityID})
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	query := bson.M{"$or": sources}
+
+	cursor, err := db.meterCollection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var meters []domain.Meter
+	if err = cursor.All(ctx, &meters); err != nil {
+		return nil, err
+	}
+
+	return meters, nil
+}
+
+// SaveMeterReading stores the register value of a meter
+func (db *DB) SaveMeterReading(reading *domain.MeterReading) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.meterReadingCollection.InsertOne(ctx, reading)
+	if err != nil {
+		return err
+	}
+
+	reading.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveMeterReadings retrieves the readings of a meter in [from, to],
+// together with the last reading before from and the first after to so
+// consumption can be apportioned at the edges of the range
+func (db *DB) RetrieveMeterReadings(meterID string, from, to time.Time) ([]domain.MeterReading, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var readings []domain.MeterReading
+	var before domain.MeterReading
+	err := db.meterReadingCollection.FindOne(ctx, bson.M{"meter_id": meterID, "timestamp": bson.M{"$lt": from}},
+		options.FindOne().SetSort(bson.M{"timestamp": -1})).Decode(&before)
+	if err == nil {
+		readings = append(readings, before)
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	query := bson.M{"meter_id": meterID, "timestamp": bson.M{"$gte": from, "$lte": to}}
+	cursor, err := db.meterReadingCollection.Find(ctx, query, options.Find().SetSort(bson.M{"timestamp": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var within []domain.MeterReading
+	if err = cursor.All(ctx, &within); err != nil {
+		return nil, err
+	}
+	readings = append(readings, within...)
+
+	var after domain.MeterReading
+	err = db.meterReadingCollection.FindOne(ctx, bson.M{"meter_id": meterID, "timestamp": bson.M{"$gt": to}},
+		options.FindOne().SetSort(bson.M{"timestamp": 1})).Decode(&after)
+	if err == nil {
+		readings = append(readings, after)
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+// SaveTariff stores a new tariff
+func (db *DB) SaveTariff(tariff *domain.Tariff) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.tariffCollection.InsertOne(ctx, tariff)
+	if err != nil {
+		return err
+	}
+
+	tariff.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveTariff retrieves a single tariff by ID
+func (db *DB) RetrieveTariff(id string) (*domain.Tariff, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tariff domain.Tariff
+	err = db.tariffCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&tariff)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("tariff not found")
+		}
+		return nil, err
+	}
+
+	return &tariff, nil
+}
+
+// RetrieveTariffs retrieves every tariff
+func (db *DB) RetrieveTariffs() ([]domain.Tariff, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+
+	cursor, err := db.tariffCollection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tariffs []domain.Tariff
+	if err = cursor.All(ctx, &tariffs); err != nil {
+		return nil, err
+	}
+
+	return tariffs, nil
+}
+
+// SaveHarvest stores a harvest record
+func (db *DB) SaveHarvest(harvest *domain.HarvestRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.harvestCollection.InsertOne(ctx, harvest)
+	if err != nil {
+		return err
+	}
+
+	harvest.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveHarvest retrieves a single harvest by ID
+func (db *DB) RetrieveHarvest(id string) (*domain.HarvestRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var harvest domain.HarvestRecord
+	err = db.harvestCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&harvest)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("harvest not found")
+		}
+		return nil, err
+	}
+
+	return &harvest, nil
+}
+
+// RetrieveHarvests retrieves the harvest records of a farm, oldest first
+func (db *DB) RetrieveHarvests(farmID string) ([]domain.HarvestRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{"farm_id": farmID}
+
+	cursor, err := db.harvestCollection.Find(ctx, query, options.Find().SetSort(bson.M{"harvested_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var harvests []domain.HarvestRecord
+	if err = cursor.All(ctx, &harvests); err != nil {
+		return nil, err
+	}
+
+	return harvests, nil
+}
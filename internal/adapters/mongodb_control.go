@@ -0,0 +1,151 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveControlPolicy stores a new control policy
+func (db *DB) SaveControlPolicy(policy *domain.ControlPolicy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.policyCollection.InsertOne(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	policy.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateControlPolicy replaces a stored control policy
+func (db *DB) UpdateControlPolicy(policy *domain.ControlPolicy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.policyCollection.ReplaceOne(ctx, bson.M{"_id": policy.ID}, policy)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("control policy not found")
+	}
+
+	return nil
+}
+
+// RetrieveControlPolicy retrieves a single control policy by ID
+func (db *DB) RetrieveControlPolicy(id string) (*domain.ControlPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy domain.ControlPolicy
+	err = db.policyCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&policy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("control policy not found")
+		}
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// RetrieveControlPolicies retrieves the control policies of a farm, or of
+// every farm when farmID is empty
+func (db *DB) RetrieveControlPolicies(farmID string) ([]domain.ControlPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if farmID != "" {
+		query["farm_id"] = farmID
+	}
+
+	cursor, err := db.policyCollection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var policies []domain.ControlPolicy
+	if err = cursor.All(ctx, &policies); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// RemoveControlPolicy deletes a control policy
+func (db *DB) RemoveControlPolicy(id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.policyCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return false, err
+	}
+
+	if result.DeletedCount == 0 {
+		return false, errors.New("control policy not found")
+	}
+
+	return true, nil
+}
+
+// SaveControlDecision stores the audit record of a controller decision
+func (db *DB) SaveControlDecision(decision *domain.ControlDecision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.decisionCollection.InsertOne(ctx, decision)
+	if err != nil {
+		return err
+	}
+
+	decision.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveControlDecisions retrieves the controller decisions of a farm in [from, to)
+func (db *DB) RetrieveControlDecisions(farmID string, from, to time.Time) ([]domain.ControlDecision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"farm_id":    farmID,
+		"created_at": bson.M{"$gte": from, "$lt": to},
+	}
+
+	cursor, err := db.decisionCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var decisions []domain.ControlDecision
+	if err = cursor.All(ctx, &decisions); err != nil {
+		return nil, err
+	}
+
+	return decisions, nil
+}
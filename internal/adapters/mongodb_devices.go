@@ -0,0 +1,169 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveDevice registers a new device
+func (db *DB) SaveDevice(device *domain.Device) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.deviceCollection.InsertOne(ctx, device)
+	if err != nil {
+		return err
+	}
+
+	device.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveDevice retrieves a single device by ID
+func (db *DB) RetrieveDevice(id string) (*domain.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var device domain.Device
+	err = db.deviceCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&device)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+
+	return &device, nil
+}
+
+// RetrieveDeviceByEUI retrieves the LoRaWAN device with the given EUI
+func (db *DB) RetrieveDeviceByEUI(devEUI string) (*domain.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var device domain.Device
+	err := db.deviceCollection.FindOne(ctx, bson.M{"dev_eui": devEUI}).Decode(&device)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+
+	return &device, nil
+}
+
+// RetrieveDevices retrieves the devices of a farm, or of every farm when
+// farmID is empty
+func (db *DB) RetrieveDevices(farmID string) ([]domain.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if farmID != "" {
+		query["farm_id"] = farmID
+	}
+
+	cursor, err := db.deviceCollection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var devices []domain.Device
+	if err = cursor.All(ctx, &devices); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// SaveDataGap stores a newly opened data gap
+func (db *DB) SaveDataGap(gap *domain.DataGap) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.gapCollection.InsertOne(ctx, gap)
+	if err != nil {
+		return err
+	}
+
+	gap.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateDataGap replaces a stored data gap
+func (db *DB) UpdateDataGap(gap *domain.DataGap) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.gapCollection.ReplaceOne(ctx, bson.M{"_id": gap.ID}, gap)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("data gap not found")
+	}
+
+	return nil
+}
+
+// RetrieveDataGaps retrieves the gaps of a farm overlapping [from, to)
+func (db *DB) RetrieveDataGaps(farmID string, from, to time.Time) ([]domain.DataGap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"farm_id": farmID,
+		"start":   bson.M{"$lt": to},
+		"$or": bson.A{
+			bson.M{"end": bson.M{"$exists": false}},
+			bson.M{"end": bson.M{"$gte": from}},
+		},
+	}
+
+	cursor, err := db.gapCollection.Find(ctx, query, options.Find().SetSort(bson.M{"start": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var gaps []domain.DataGap
+	if err = cursor.All(ctx, &gaps); err != nil {
+		return nil, err
+	}
+
+	return gaps, nil
+}
+
+// RetrieveOpenDataGaps retrieves every gap that has not ended yet
+func (db *DB) RetrieveOpenDataGaps() ([]domain.DataGap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.gapCollection.Find(ctx, bson.M{"end": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var gaps []domain.DataGap
+	if err = cursor.All(ctx, &gaps); err != nil {
+		return nil, err
+	}
+
+	return gaps, nil
+}
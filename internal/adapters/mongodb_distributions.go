@@ -0,0 +1,89 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveDistribution stores a finalized distribution. A harvest can only be
+// distributed once.
+func (db *DB) SaveDistribution(distribution *domain.Distribution) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.distributionCollection.InsertOne(ctx, distribution)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("harvest has already been distributed")
+		}
+		return err
+	}
+
+	distribution.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateDistribution replaces a stored distribution
+func (db *DB) UpdateDistribution(distribution *domain.Distribution) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.distributionCollection.ReplaceOne(ctx, bson.M{"_id": distribution.ID}, distribution)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("distribution not found")
+	}
+
+	return nil
+}
+
+// RetrieveDistribution retrieves a single distribution by ID
+func (db *DB) RetrieveDistribution(id string) (*domain.Distribution, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var distribution domain.Distribution
+	err = db.distributionCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&distribution)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("distribution not found")
+		}
+		return nil, err
+	}
+
+	return &distribution, nil
+}
+
+// RetrieveDistributions retrieves the distributions of a farm, newest first
+func (db *DB) RetrieveDistributions(farmID string) ([]domain.Distribution, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.distributionCollection.Find(ctx, bson.M{"farm_id": farmID}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var distributions []domain.Distribution
+	if err = cursor.All(ctx, &distributions); err != nil {
+		return nil, err
+	}
+
+	return distributions, nil
+}
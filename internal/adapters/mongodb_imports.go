@@ -0,0 +1,141 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveImportJob stores a new import job
+func (db *DB) SaveImportJob(job *domain.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.importCollection.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateImportJob replaces a stored import job
+func (db *DB) UpdateImportJob(job *domain.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.importCollection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("import job not found")
+	}
+
+	return nil
+}
+
+// RetrieveImportJob retrieves a single import job by ID
+func (db *DB) RetrieveImportJob(id string) (*domain.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var job domain.ImportJob
+	err = db.importCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("import job not found")
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// RetrieveImportJobs retrieves the import jobs of a farm, or of every farm
+// when farmID is empty, optionally restricted to some statuses
+func (db *DB) RetrieveImportJobs(farmID string, statuses []string) ([]domain.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if farmID != "" {
+		query["farm_id"] = farmID
+	}
+	if len(statuses) > 0 {
+		query["status"] = bson.M{"$in": statuses}
+	}
+
+	cursor, err := db.importCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []domain.ImportJob
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// SaveImportRejections stores the rows an import job rejected. Rows already
+// recorded by an earlier attempt at the same batch are skipped.
+func (db *DB) SaveImportRejections(rejections []domain.ImportRejection) error {
+	if len(rejections) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(rejections))
+	for i := range rejections {
+		docs[i] = rejections[i]
+	}
+
+	_, err := db.rejectionCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Code != duplicateKeyCode {
+				return err
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// RetrieveImportRejections retrieves the rows an import job rejected, in file order
+func (db *DB) RetrieveImportRejections(jobID string) ([]domain.ImportRejection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := db.rejectionCollection.Find(ctx, bson.M{"job_id": jobID}, options.Find().SetSort(bson.M{"row": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rejections []domain.ImportRejection
+	if err = cursor.All(ctx, &rejections); err != nil {
+		return nil, err
+	}
+
+	return rejections, nil
+}
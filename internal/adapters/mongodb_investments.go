@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveInvestment stores a new investment
+func (db *DB) SaveInvestment(investment *domain.Investment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.investmentCollection.InsertOne(ctx, investment)
+	if err != nil {
+		return err
+	}
+
+	investment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateInvestment replaces a stored investment
+func (db *DB) UpdateInvestment(investment *domain.Investment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.investmentCollection.ReplaceOne(ctx, bson.M{"_id": investment.ID}, investment)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("investment not found")
+	}
+
+	return nil
+}
+
+// RetrieveInvestment retrieves a single investment by ID
+func (db *DB) RetrieveInvestment(id string) (*domain.Investment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var investment domain.Investment
+	err = db.investmentCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&investment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("investment not found")
+		}
+		return nil, err
+	}
+
+	return &investment, nil
+}
+
+// RetrieveInvestments retrieves investments, newest first, of a farm and/or
+// an investor. Empty IDs do not filter.
+func (db *DB) RetrieveInvestments(farmID, investorID string) ([]domain.Investment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if farmID != "" {
+		query["farm_id"] = farmID
+	}
+	if investorID != "" {
+		query["investor_id"] = investorID
+	}
+
+	cursor, err := db.investmentCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var investments []domain.Investment
+	if err = cursor.All(ctx, &investments); err != nil {
+		return nil, err
+	}
+
+	return investments, nil
+}
@@ -0,0 +1,309 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveShareOrder stores a new order
+func (db *DB) SaveShareOrder(order *domain.ShareOrder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.orderCollection.InsertOne(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	order.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateShareOrder replaces a stored order
+func (db *DB) UpdateShareOrder(order *domain.ShareOrder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.orderCollection.ReplaceOne(ctx, bson.M{"_id": order.ID}, order)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("order not found")
+	}
+
+	return nil
+}
+
+// RetrieveShareOrder retrieves a single order by ID
+func (db *DB) RetrieveShareOrder(id string) (*domain.ShareOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.findShareOrder(ctx, id)
+}
+
+// RetrieveShareOrders retrieves the orders of a farm, oldest first. An
+// empty status does not filter.
+func (db *DB) RetrieveShareOrders(farmID, status string) ([]domain.ShareOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{"farm_id": farmID}
+	if status != "" {
+		query["status"] = status
+	}
+
+	cursor, err := db.orderCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []domain.ShareOrder
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// RetrieveShareTrades retrieves the trades of a farm, newest first
+func (db *DB) RetrieveShareTrades(farmID string) ([]domain.ShareTrade, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.tradeCollection.Find(ctx, bson.M{"farm_id": farmID}, options.Find().SetSort(bson.M{"executed_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var trades []domain.ShareTrade
+	if err = cursor.All(ctx, &trades); err != nil {
+		return nil, err
+	}
+	for i := range trades {
+		settledStatus(&trades[i])
+	}
+
+	return trades, nil
+}
+
+// RetrieveShareTrade retrieves a single trade by ID
+func (db *DB) RetrieveShareTrade(id string) (*domain.ShareTrade, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.findShareTrade(ctx, id)
+}
+
+// UpdateShareTrade changes a trade and the owners of its farm in a
+// transaction. The trade and farm are read inside the transaction and
+// handed to change; the trade, the owners and an ownership event for every
+// owner entry change altered are then written together, or not at all.
+func (db *DB) UpdateShareTrade(id string, change func(farm *domain.VerticalFarm, trade *domain.ShareTrade) error) (*domain.ShareTrade, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	session, err := db.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		trade, err := db.findShareTrade(sc, id)
+		if err != nil {
+			return nil, err
+		}
+		objectID, err := primitive.ObjectIDFromHex(trade.FarmID)
+		if err != nil {
+			return nil, err
+		}
+		var farm domain.VerticalFarm
+		if err := db.farmCollection.FindOne(sc, bson.M{"_id": objectID}).Decode(&farm); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, errors.New("farm not found")
+			}
+			return nil, err
+		}
+		farm.ID = trade.FarmID
+
+		before := append([]domain.Owner(nil), farm.Owners...)
+		if err := change(&farm, trade); err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		update := bson.M{"$set": bson.M{"owners": farm.Owners, "lastupdated": now}}
+		if _, err := db.farmCollection.UpdateOne(sc, bson.M{"_id": objectID}, update); err != nil {
+			return nil, err
+		}
+		if _, err := db.tradeCollection.ReplaceOne(sc, bson.M{"_id": trade.ID}, trade); err != nil {
+			return nil, err
+		}
+
+		events := ownershipEvents(trade.FarmID, before, farm.Owners, trade, now)
+		if len(events) > 0 {
+			if _, err := db.ownershipEventCollection.InsertMany(sc, events); err != nil {
+				return nil, err
+			}
+		}
+		return trade, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*domain.ShareTrade), nil
+}
+
+// UpdateFarmOwners changes the owners of a farm in a transaction. The farm
+// is read inside the transaction and handed to change, and its owners are
+// written back only if nothing else changed them in between.
+func (db *DB) UpdateFarmOwners(farmID string, change func(farm *domain.VerticalFarm) error) error {
+	_, err := db.changeOwnership(farmID, nil, func(farm *domain.VerticalFarm, _ []*domain.ShareOrder) (*domain.ShareTrade, error) {
+		return nil, change(farm)
+	})
+	return err
+}
+
+// ExecuteShareTrade moves shares between owners in a single transaction.
+// The farm and orders are read inside the transaction and handed to apply,
+// which changes them and returns the trade to record. The changed owners,
+// the orders and the trade are then written together, or not at all.
+func (db *DB) ExecuteShareTrade(farmID string, orderIDs []string, apply func(farm *domain.VerticalFarm, orders []*domain.ShareOrder) (*domain.ShareTrade, error)) (*domain.ShareTrade, error) {
+	return db.changeOwnership(farmID, orderIDs, apply)
+}
+
+// changeOwnership runs apply and writes its changes in a transaction,
+// together with an ownership event for every owner entry it changed. A
+// concurrent change to the farm or orders aborts the transaction, which is
+// retried with fresh state.
+func (db *DB) changeOwnership(farmID string, orderIDs []string, apply func(farm *domain.VerticalFarm, orders []*domain.ShareOrder) (*domain.ShareTrade, error)) (*domain.ShareTrade, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := db.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		var farm domain.VerticalFarm
+		if err := db.farmCollection.FindOne(sc, bson.M{"_id": objectID}).Decode(&farm); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, errors.New("farm not found")
+			}
+			return nil, err
+		}
+		farm.ID = farmID
+
+		orders := make([]*domain.ShareOrder, 0, len(orderIDs))
+		for _, id := range orderIDs {
+			order, err := db.findShareOrder(sc, id)
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+		}
+
+		before := append([]domain.Owner(nil), farm.Owners...)
+		trade, err := apply(&farm, orders)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		update := bson.M{"$set": bson.M{"owners": farm.Owners, "lastupdated": now}}
+		if _, err := db.farmCollection.UpdateOne(sc, bson.M{"_id": objectID}, update); err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			if _, err := db.orderCollection.ReplaceOne(sc, bson.M{"_id": order.ID}, order); err != nil {
+				return nil, err
+			}
+		}
+		if trade != nil {
+			inserted, err := db.tradeCollection.InsertOne(sc, trade)
+			if err != nil {
+				return nil, err
+			}
+			trade.ID = inserted.InsertedID.(primitive.ObjectID)
+		}
+
+		events := ownershipEvents(farmID, before, farm.Owners, trade, now)
+		if len(events) > 0 {
+			if _, err := db.ownershipEventCollection.InsertMany(sc, events); err != nil {
+				return nil, err
+			}
+		}
+		return trade, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trade, _ := result.(*domain.ShareTrade)
+	return trade, nil
+}
+
+func (db *DB) findShareOrder(ctx context.Context, id string) (*domain.ShareOrder, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var order domain.ShareOrder
+	err = db.orderCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (db *DB) findShareTrade(ctx context.Context, id string) (*domain.ShareTrade, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var trade domain.ShareTrade
+	err = db.tradeCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&trade)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("trade not found")
+		}
+		return nil, err
+	}
+
+	settledStatus(&trade)
+	return &trade, nil
+}
+
+// settledStatus marks trades recorded before trades were settled by
+// payment, which moved their shares when they executed
+func settledStatus(trade *domain.ShareTrade) {
+	if trade.Status == "" {
+		trade.Status = domain.TradeSettled
+	}
+}
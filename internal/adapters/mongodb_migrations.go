@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/pkg/money"
+	"context"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MigrateShareUnits converts ownership stored as float percentages and
+// amounts stored as floats to share units and fixed-point money. Documents
+// already converted are left alone, so it is safe to run on every start.
+// It returns the number of documents converted.
+func (db *DB) MigrateShareUnits() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	migrated := 0
+	cursor, err := db.farmCollection.Find(ctx, bson.M{"owners.sharesize": bson.M{"$exists": true}})
+	if err != nil {
+		return migrated, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var farm struct {
+			ID     interface{} `bson:"_id"`
+			Owners []bson.M    `bson:"owners"`
+		}
+		if err := cursor.Decode(&farm); err != nil {
+			return migrated, err
+		}
+		for _, owner := range farm.Owners {
+			if percent, ok := legacyFloat(owner["sharesize"]); ok {
+				owner["shares"] = legacyShares(percent)
+			}
+			delete(owner, "sharesize")
+		}
+		if _, err := db.farmCollection.UpdateOne(ctx, bson.M{"_id": farm.ID}, bson.M{"$set": bson.M{"owners": farm.Owners}}); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	if err := cursor.Err(); err != nil {
+		return migrated, err
+	}
+
+	investments, err := db.investmentCollection.Find(ctx, bson.M{"share_size": bson.M{"$exists": true}})
+	if err != nil {
+		return migrated, err
+	}
+	defer investments.Close(ctx)
+
+	for investments.Next(ctx) {
+		var doc bson.M
+		if err := investments.Decode(&doc); err != nil {
+			return migrated, err
+		}
+		set := bson.M{}
+		if percent, ok := legacyFloat(doc["share_size"]); ok {
+			set["shares"] = legacyShares(percent)
+		}
+		for _, field := range []string{"amount", "price_per_share"} {
+			if value, ok := doc[field].(float64); ok {
+				set[field] = legacyAmount(value)
+			}
+		}
+		update := bson.M{"$unset": bson.M{"share_size": ""}}
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+		if _, err := db.investmentCollection.UpdateOne(ctx, bson.M{"_id": doc["_id"]}, update); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, investments.Err()
+}
+
+// legacyFloat reads a number stored by the float schema
+func legacyFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// legacyShares converts a float percentage to share units, rounding to the
+// nearest unit so values like 33.3333 stored as 33.33329999 are not cut short
+func legacyShares(percent float64) int64 {
+	return int64(math.Round(percent * float64(domain.SharesPerPercent)))
+}
+
+// legacyAmount converts a float amount to minor units, rounding to the
+// nearest unit
+func legacyAmount(value float64) int64 {
+	return int64(math.Round(value * money.Scale))
+}
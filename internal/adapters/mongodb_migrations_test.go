@@ -0,0 +1,59 @@
+package adapters
+
+import "testing"
+
+func TestLegacyShares(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent float64
+		want    int64
+	}{
+		{name: "whole farm", percent: 100, want: 1_000_000},
+		{name: "exact", percent: 12.5, want: 125_000},
+		{name: "third", percent: 100.0 / 3, want: 333_333},
+		{name: "float error below", percent: 33.3333 - 1e-9, want: 333_333},
+		{name: "float error above", percent: 0.1 + 0.2, want: 3_000},
+		{name: "nearest unit", percent: 0.00016, want: 2},
+		{name: "below half a unit", percent: 0.00004, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := legacyShares(tt.percent); got != tt.want {
+				t.Errorf("legacyShares(%v) = %d, want %d", tt.percent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyAmount(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		want  int64
+	}{
+		{name: "whole", value: 50, want: 500_000_000},
+		{name: "cents", value: 19.99, want: 199_900_000},
+		{name: "float error", value: 0.1 + 0.2, want: 3_000_000},
+		{name: "finer than a unit", value: 0.00000016, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := legacyAmount(tt.value); got != tt.want {
+				t.Errorf("legacyAmount(%v) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyFloat(t *testing.T) {
+	for _, value := range []interface{}{float64(12), int32(12), int64(12)} {
+		if got, ok := legacyFloat(value); !ok || got != 12 {
+			t.Errorf("legacyFloat(%T 12) = %v, %v", value, got, ok)
+		}
+	}
+	if _, ok := legacyFloat("12"); ok {
+		t.Error("legacyFloat read a string")
+	}
+}
@@ -0,0 +1,149 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RetrieveOwnershipEvents retrieves the ownership events of a farm up to
+// and including a time, oldest first. A zero time does not filter.
+func (db *DB) RetrieveOwnershipEvents(farmID string, until time.Time) ([]domain.OwnershipEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{"farm_id": farmID}
+	if !until.IsZero() {
+		query["occurred_at"] = bson.M{"$lte": until}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := db.ownershipEventCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []domain.OwnershipEvent
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// BackfillOwnershipEvents records the owners of farms that have no
+// ownership events yet as issued when they joined, so farms owned before
+// events were recorded have a history to replay. It returns the number of
+// farms backfilled and is safe to run on every start.
+func (db *DB) BackfillOwnershipEvents() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	recorded, err := db.ownershipEventCollection.Distinct(ctx, "farm_id", bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool, len(recorded))
+	for _, id := range recorded {
+		if farmID, ok := id.(string); ok {
+			seen[farmID] = true
+		}
+	}
+
+	cursor, err := db.farmCollection.Find(ctx, bson.M{"owners.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"owners": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	backfilled := 0
+	for cursor.Next(ctx) {
+		var farm struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Owners []domain.Owner     `bson:"owners"`
+		}
+		if err := cursor.Decode(&farm); err != nil {
+			return backfilled, err
+		}
+		farmID := farm.ID.Hex()
+		if seen[farmID] {
+			continue
+		}
+
+		events := make([]interface{}, 0, len(farm.Owners))
+		for _, owner := range farm.Owners {
+			events = append(events, domain.OwnershipEvent{
+				FarmID:     farmID,
+				Type:       domain.OwnershipIssued,
+				OwnerID:    owner.ID,
+				Address:    owner.Address,
+				Change:     owner.Shares,
+				OccurredAt: owner.JoinedAt,
+			})
+		}
+		if _, err := db.ownershipEventCollection.InsertMany(ctx, events); err != nil {
+			return backfilled, err
+		}
+		backfilled++
+	}
+	return backfilled, cursor.Err()
+}
+
+// ownershipEvents compares the owners before and after a change and returns
+// an event for every owner entry whose shares changed
+func ownershipEvents(farmID string, before, after []domain.Owner, trade *domain.ShareTrade, at time.Time) []interface{} {
+	previous := make(map[string]bool, len(before))
+	for _, owner := range before {
+		previous[owner.ID] = true
+	}
+	current := make(map[string]domain.Owner, len(after))
+	for _, owner := range after {
+		current[owner.ID] = owner
+	}
+
+	var events []interface{}
+	record := func(owner domain.Owner, change domain.Shares) {
+		if change == 0 {
+			return
+		}
+		event := domain.OwnershipEvent{
+			FarmID:     farmID,
+			OwnerID:    owner.ID,
+			Address:    owner.Address,
+			Change:     change,
+			OccurredAt: at,
+		}
+		switch {
+		case trade != nil:
+			event.Type = domain.OwnershipTraded
+			event.TradeID = trade.ID.Hex()
+			event.OccurredAt = trade.ExecutedAt
+			if trade.SettledAt != nil {
+				event.OccurredAt = *trade.SettledAt
+			}
+		case change > 0:
+			event.Type = domain.OwnershipIssued
+		default:
+			event.Type = domain.OwnershipRemoved
+		}
+		events = append(events, event)
+	}
+
+	// Existing entries come first, so a trade reads seller then buyer. An
+	// entry no longer present has lost all its shares.
+	for _, owner := range before {
+		record(owner, current[owner.ID].Shares-owner.Shares)
+	}
+	for _, owner := range after {
+		if !previous[owner.ID] {
+			record(owner, owner.Shares)
+		}
+	}
+	return events
+}
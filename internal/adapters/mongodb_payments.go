@@ -0,0 +1,114 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveIncomingPayment records a received payment, returning false when the
+// payment was recorded before
+func (db *DB) SaveIncomingPayment(payment *domain.IncomingPayment) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.paymentCollection.InsertOne(ctx, payment)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	payment.ID = result.InsertedID.(primitive.ObjectID)
+	return true, nil
+}
+
+// UpdateIncomingPayment replaces a recorded payment
+func (db *DB) UpdateIncomingPayment(payment *domain.IncomingPayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.paymentCollection.ReplaceOne(ctx, bson.M{"_id": payment.ID}, payment)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("payment not found")
+	}
+
+	return nil
+}
+
+// RetrieveIncomingPayments retrieves the payments in any of the statuses,
+// oldest first. No statuses retrieves every payment.
+func (db *DB) RetrieveIncomingPayments(statuses []string) ([]domain.IncomingPayment, error) {
+	query := bson.M{}
+	if len(statuses) > 0 {
+		query["status"] = bson.M{"$in": statuses}
+	}
+	return db.findIncomingPayments(query)
+}
+
+// RetrieveInvestmentPayments retrieves the payments matched to an
+// investment, oldest first
+func (db *DB) RetrieveInvestmentPayments(investmentID string) ([]domain.IncomingPayment, error) {
+	return db.findIncomingPayments(bson.M{"investment_id": investmentID})
+}
+
+// RetrievePaymentCursor retrieves where reading an account's payments left
+// off, empty when it has not started
+func (db *DB) RetrievePaymentCursor(accountID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var cursor struct {
+		Cursor string `bson:"cursor"`
+	}
+	err := db.paymentCursorCollection.FindOne(ctx, bson.M{"account_id": accountID}).Decode(&cursor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return cursor.Cursor, nil
+}
+
+// SavePaymentCursor stores where reading an account's payments left off
+func (db *DB) SavePaymentCursor(accountID, cursor string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.paymentCursorCollection.UpdateOne(ctx,
+		bson.M{"account_id": accountID},
+		bson.M{"$set": bson.M{"cursor": cursor, "updated_at": time.Now()}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (db *DB) findIncomingPayments(query bson.M) ([]domain.IncomingPayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.paymentCollection.Find(ctx, query, options.Find().SetSort(bson.M{"received_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var payments []domain.IncomingPayment
+	if err = cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,80 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SavePayout stores a new payout. A distribution has at most one.
+func (db *DB) SavePayout(payout *domain.Payout) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.payoutCollection.InsertOne(ctx, payout)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("distribution already has a payout")
+		}
+		return err
+	}
+
+	payout.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdatePayout replaces a stored payout
+func (db *DB) UpdatePayout(payout *domain.Payout) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.payoutCollection.ReplaceOne(ctx, bson.M{"_id": payout.ID}, payout)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("payout not found")
+	}
+
+	return nil
+}
+
+// RetrievePayout retrieves a single payout by ID
+func (db *DB) RetrievePayout(id string) (*domain.Payout, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.findPayout(ctx, bson.M{"_id": objectID})
+}
+
+// RetrieveDistributionPayout retrieves the payout of a distribution
+func (db *DB) RetrieveDistributionPayout(distributionID string) (*domain.Payout, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return db.findPayout(ctx, bson.M{"distribution_id": distributionID})
+}
+
+func (db *DB) findPayout(ctx context.Context, filter bson.M) (*domain.Payout, error) {
+	var payout domain.Payout
+	err := db.payoutCollection.FindOne(ctx, filter).Decode(&payout)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("payout not found")
+		}
+		return nil, err
+	}
+
+	return &payout, nil
+}
@@ -0,0 +1,121 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// archivedReading is a reading stored in the archive collection, which keeps
+// history that would not fit in the farm document
+type archivedReading struct {
+	FarmID            string `bson:"farm_id"`
+	domain.IoTReading `bson:",inline"`
+}
+
+// duplicateKeyCode is the server error code for unique index violations
+const duplicateKeyCode = 11000
+
+// InsertReadings archives readings of a farm and returns the ones that were
+// inserted. Readings already archived for the same time and device are skipped.
+func (db *DB) InsertReadings(farmID string, readings []domain.IoTReading) ([]domain.IoTReading, error) {
+	if len(readings) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(readings))
+	for i, reading := range readings {
+		docs[i] = archivedReading{FarmID: farmID, IoTReading: reading}
+	}
+
+	_, err := db.readingCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return readings, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return nil, err
+	}
+	skipped := make(map[int]bool, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != duplicateKeyCode {
+			return nil, err
+		}
+		skipped[writeErr.Index] = true
+	}
+
+	inserted := make([]domain.IoTReading, 0, len(readings)-len(skipped))
+	for i, reading := range readings {
+		if !skipped[i] {
+			inserted = append(inserted, reading)
+		}
+	}
+	return inserted, nil
+}
+
+// RetrieveReadings retrieves the archived readings of a farm in [from, to)
+func (db *DB) RetrieveReadings(farmID string, from, to time.Time) ([]domain.IoTReading, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"farm_id":   farmID,
+		"timestamp": bson.M{"$gte": from, "$lt": to},
+	}
+
+	cursor, err := db.readingCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"timestamp": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var archived []archivedReading
+	if err = cursor.All(ctx, &archived); err != nil {
+		return nil, err
+	}
+
+	readings := make([]domain.IoTReading, len(archived))
+	for i, a := range archived {
+		readings[i] = a.IoTReading
+	}
+	return readings, nil
+}
+
+// StreamReadings calls fn with each archived reading of a farm in [from, to),
+// oldest first, without loading them all into memory. It stops at the first
+// error fn returns.
+func (db *DB) StreamReadings(farmID string, from, to time.Time, fn func(domain.IoTReading) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	filter := bson.M{
+		"farm_id":   farmID,
+		"timestamp": bson.M{"$gte": from, "$lt": to},
+	}
+
+	cursor, err := db.readingCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"timestamp": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var archived archivedReading
+		if err := cursor.Decode(&archived); err != nil {
+			return err
+		}
+		if err := fn(archived.IoTReading); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,115 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveRecommendation stores a new dosing recommendation
+func (db *DB) SaveRecommendation(recommendation *domain.DosingRecommendation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.dosingCollection.InsertOne(ctx, recommendation)
+	if err != nil {
+		return err
+	}
+
+	recommendation.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateRecommendation replaces a stored dosing recommendation
+func (db *DB) UpdateRecommendation(recommendation *domain.DosingRecommendation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.dosingCollection.ReplaceOne(ctx, bson.M{"_id": recommendation.ID}, recommendation)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("recommendation not found")
+	}
+
+	return nil
+}
+
+// RetrieveRecommendation retrieves a single dosing recommendation by ID
+func (db *DB) RetrieveRecommendation(id string) (*domain.DosingRecommendation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var recommendation domain.DosingRecommendation
+	err = db.dosingCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&recommendation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("recommendation not found")
+		}
+		return nil, err
+	}
+
+	return &recommendation, nil
+}
+
+// RetrieveRecommendations retrieves the dosing recommendations of a farm in [from, to)
+func (db *DB) RetrieveRecommendations(farmID string, from, to time.Time) ([]domain.DosingRecommendation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"farm_id":    farmID,
+		"created_at": bson.M{"$gte": from, "$lt": to},
+	}
+
+	cursor, err := db.dosingCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var recommendations []domain.DosingRecommendation
+	if err = cursor.All(ctx, &recommendations); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+// RetrieveUnmeasuredRecommendations retrieves the recommendations technicians
+// responded to whose effect has not been measured yet
+func (db *DB) RetrieveUnmeasuredRecommendations() ([]domain.DosingRecommendation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"followed":    bson.M{"$exists": true},
+		"measured_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := db.dosingCollection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var recommendations []domain.DosingRecommendation
+	if err = cursor.All(ctx, &recommendations); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
@@ -0,0 +1,115 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpsertRollup folds a reading into the rollup bucket of a farm, creating the
+// bucket if it does not exist yet
+func (db *DB) UpsertRollup(farmID, resolution string, bucketStart time.Time, values map[string]float64, health int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	minimums := bson.M{"health.min": health}
+	maximums := bson.M{"health.max": health}
+	increments := bson.M{"health.sum": health, "health.count": 1}
+	for key, value := range values {
+		minimums["metrics."+key+".min"] = value
+		maximums["metrics."+key+".max"] = value
+		increments["metrics."+key+".sum"] = value
+		increments["metrics."+key+".count"] = 1
+	}
+
+	filter := bson.M{
+		"farm_id":      farmID,
+		"resolution":   resolution,
+		"bucket_start": bucketStart,
+	}
+	update := bson.M{
+		"$min": minimums,
+		"$max": maximums,
+		"$inc": increments,
+	}
+
+	_, err := db.rollupCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// RetrieveRollups retrieves the rollup buckets of a farm starting within [from, to)
+func (db *DB) RetrieveRollups(farmID, resolution string, from, to time.Time) ([]domain.ReadingRollup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"farm_id":      farmID,
+		"resolution":   resolution,
+		"bucket_start": bson.M{"$gte": from, "$lt": to},
+	}
+
+	cursor, err := db.rollupCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"bucket_start": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rollups []domain.ReadingRollup
+	if err = cursor.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+
+	return rollups, nil
+}
+
+// RemoveRollups deletes every rollup bucket of a farm
+func (db *DB) RemoveRollups(farmID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := db.rollupCollection.DeleteMany(ctx, bson.M{"farm_id": farmID})
+	return err
+}
+
+// MergeRollups folds pre-aggregated buckets into the stored rollups in one
+// round trip, creating buckets that do not exist yet
+func (db *DB) MergeRollups(rollups []domain.ReadingRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(rollups))
+	for _, rollup := range rollups {
+		minimums := bson.M{"health.min": rollup.Health.Min}
+		maximums := bson.M{"health.max": rollup.Health.Max}
+		increments := bson.M{"health.sum": rollup.Health.Sum, "health.count": rollup.Health.Count}
+		for key, agg := range rollup.Metrics {
+			minimums["metrics."+key+".min"] = agg.Min
+			maximums["metrics."+key+".max"] = agg.Max
+			increments["metrics."+key+".sum"] = agg.Sum
+			increments["metrics."+key+".count"] = agg.Count
+		}
+
+		filter := bson.M{
+			"farm_id":      rollup.FarmID,
+			"resolution":   rollup.Resolution,
+			"bucket_start": rollup.BucketStart,
+		}
+		update := bson.M{
+			"$min": minimums,
+			"$max": maximums,
+			"$inc": increments,
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	_, err := db.rollupCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
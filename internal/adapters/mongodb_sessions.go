@@ -0,0 +1,63 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SaveAuthSession stores a new session. A challenge can only open one.
+func (db *DB) SaveAuthSession(session *domain.AuthSession) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.sessionCollection.InsertOne(ctx, session)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("challenge has already been used")
+		}
+		return err
+	}
+
+	session.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveAuthSession retrieves a session by the hash of its token
+func (db *DB) RetrieveAuthSession(tokenHash string) (*domain.AuthSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var session domain.AuthSession
+	err := db.sessionCollection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("session not found")
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// DeleteAuthSession removes a session by the hash of its token
+func (db *DB) DeleteAuthSession(tokenHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.sessionCollection.DeleteOne(ctx, bson.M{"token_hash": tokenHash})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("session not found")
+	}
+
+	return nil
+}
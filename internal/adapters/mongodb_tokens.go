@@ -0,0 +1,88 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SaveFarmToken stores the token of a farm. A farm has at most one token,
+// and an asset code belongs to a single farm.
+func (db *DB) SaveFarmToken(token *domain.FarmToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.tokenCollection.InsertOne(ctx, token)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "asset_code") {
+			return fmt.Errorf("asset code %s is already in use", token.AssetCode)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("farm is already tokenized")
+		}
+		return err
+	}
+
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdateFarmToken replaces a stored farm token
+func (db *DB) UpdateFarmToken(token *domain.FarmToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.tokenCollection.ReplaceOne(ctx, bson.M{"_id": token.ID}, token)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("farm token not found")
+	}
+
+	return nil
+}
+
+// RetrieveFarmToken retrieves the token of a farm
+func (db *DB) RetrieveFarmToken(farmID string) (*domain.FarmToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var token domain.FarmToken
+	err := db.tokenCollection.FindOne(ctx, bson.M{"farm_id": farmID}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("farm is not tokenized")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RetrieveFarmTokens retrieves every farm token
+func (db *DB) RetrieveFarmTokens() ([]domain.FarmToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.tokenCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []domain.FarmToken
+	if err = cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
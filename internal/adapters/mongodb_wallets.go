@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveWalletChallenge stores a binding challenge, replacing any earlier
+// challenge of the user for the same account
+func (db *DB) SaveWalletChallenge(challenge *domain.WalletChallenge) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": challenge.UserID, "account_id": challenge.AccountID}
+	update := bson.M{"$set": bson.M{"message": challenge.Message, "expires_at": challenge.ExpiresAt}}
+	result, err := db.walletChallengeCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	if id, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		challenge.ID = id
+	}
+	return nil
+}
+
+// TakeWalletChallenge removes and returns the challenge of a user for an
+// account, so each challenge can be answered once
+func (db *DB) TakeWalletChallenge(userID, accountID string) (*domain.WalletChallenge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var challenge domain.WalletChallenge
+	err := db.walletChallengeCollection.FindOneAndDelete(ctx, bson.M{"user_id": userID, "account_id": accountID}).Decode(&challenge)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("no challenge issued for this wallet")
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// SaveWallet stores a bound wallet. An account is bound to one user.
+func (db *DB) SaveWallet(wallet *domain.Wallet) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.walletCollection.InsertOne(ctx, wallet)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("wallet is already bound")
+		}
+		return err
+	}
+
+	wallet.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// RetrieveWallet retrieves the wallet bound to an account
+func (db *DB) RetrieveWallet(accountID string) (*domain.Wallet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wallet domain.Wallet
+	err := db.walletCollection.FindOne(ctx, bson.M{"account_id": accountID}).Decode(&wallet)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("wallet not found")
+		}
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// RetrieveWallets retrieves the wallets bound to a user, oldest first
+func (db *DB) RetrieveWallets(userID string) ([]domain.Wallet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := db.walletCollection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.M{"bound_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var wallets []domain.Wallet
+	if err = cursor.All(ctx, &wallets); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// DeleteWallet unbinds an account from a user
+func (db *DB) DeleteWallet(userID, accountID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.walletCollection.DeleteOne(ctx, bson.M{"user_id": userID, "account_id": accountID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("wallet not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actuator output kinds
+const (
+	OutputPump   = "pump"
+	OutputLight  = "light"
+	OutputFan    = "fan"
+	OutputDoser  = "doser"
+	OutputHeater = "heater"
+	OutputValve  = "valve"
+)
+
+// Command actions
+const (
+	ActionOn   = "on"   // switch on, for DurationSeconds when set
+	ActionOff  = "off"  // switch off
+	ActionSet  = "set"  // set a level such as LED dimming, in the output's unit
+	ActionDose = "dose" // deliver a quantity such as mL of concentrate
+)
+
+// Command statuses
+const (
+	CommandQueued    = "queued"
+	CommandDelivered = "delivered"
+	CommandExecuted  = "executed"
+	CommandFailed    = "failed"
+	CommandExpired   = "expired"
+	CommandCancelled = "cancelled"
+)
+
+// Command sources
+const (
+	SourceManual = "manual"
+	SourcePolicy = "policy"
+)
+
+// ActuatorOutput is a controllable output of a device together with its
+// safety limits
+type ActuatorOutput struct {
+	Key               string  `bson:"key" json:"key"`
+	Kind              string  `bson:"kind" json:"kind"`
+	Name              string  `bson:"name,omitempty" json:"name,omitempty"`
+	Unit              string  `bson:"unit,omitempty" json:"unit,omitempty"`         // unit of set and dose values
+	MinValue          float64 `bson:"min_value" json:"minValue"`                    // lowest set or dose value
+	MaxValue          float64 `bson:"max_value" json:"maxValue"`                    // highest set or dose value, 0 for no limit
+	MaxRuntimeSeconds int     `bson:"max_runtime_seconds" json:"maxRuntimeSeconds"` // longest single run, 0 for no limit
+	MinOffSeconds     int     `bson:"min_off_seconds" json:"minOffSeconds"`         // rest time between runs
+}
+
+// ActuatorCommand is an instruction for one output of a device
+type ActuatorCommand struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID          string             `bson:"farm_id" json:"farmId"`
+	DeviceID        string             `bson:"device_id" json:"deviceId"`
+	Output          string             `bson:"output" json:"output"`
+	Action          string             `bson:"action" json:"action"`
+	Value           float64            `bson:"value" json:"value"`
+	DurationSeconds int                `bson:"duration_seconds" json:"durationSeconds"`
+	Source          string             `bson:"source" json:"source"`
+	IssuedBy        string             `bson:"issued_by,omitempty" json:"issuedBy,omitempty"`
+	Status          string             `bson:"status" json:"status"`
+	Result          string             `bson:"result,omitempty" json:"result,omitempty"` // message reported by the device
+	CreatedAt       time.Time          `bson:"created_at" json:"createdAt"`
+	ExpiresAt       time.Time          `bson:"expires_at" json:"expiresAt"`
+	DeliveredAt     *time.Time         `bson:"delivered_at,omitempty" json:"deliveredAt,omitempty"`
+	CompletedAt     *time.Time         `bson:"completed_at,omitempty" json:"completedAt,omitempty"`
+}
+
+// Pending reports whether the command still waits for the device
+func (c ActuatorCommand) Pending() bool {
+	return c.Status == CommandQueued || c.Status == CommandDelivered
+}
+
+// CommandFilters narrows down command listings
+type CommandFilters struct {
+	FarmID   string
+	DeviceID string
+	Output   string
+	Statuses []string
+	From     time.Time
+	To       time.Time
+}
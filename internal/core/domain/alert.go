@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alert states
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+// Alert severities
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// HealthMetric lets alert rules target the computed crop health score
+const HealthMetric = "health"
+
+// AlertRule raises an alert when a metric stays across a threshold
+type AlertRule struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	FarmID     string             `bson:"farm_id,omitempty" json:"farmId,omitempty"`     // limit to one farm
+	CropType   string             `bson:"crop_type,omitempty" json:"cropType,omitempty"` // limit to farms growing this crop
+	Metric     string             `bson:"metric" json:"metric"`                          // metric key or "health"
+	Operator   string             `bson:"operator" json:"operator"`                      // >, >=, < or <=
+	Threshold  float64            `bson:"threshold" json:"threshold"`
+	ForSeconds int                `bson:"for_seconds" json:"forSeconds"` // how long the condition must hold
+	Hysteresis float64            `bson:"hysteresis" json:"hysteresis"`  // margin to clear before resolving
+	Severity   string             `bson:"severity" json:"severity"`
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// AppliesTo reports whether the rule covers the given farm
+func (r AlertRule) AppliesTo(farmID, cropType string) bool {
+	if r.FarmID != "" {
+		return r.FarmID == farmID
+	}
+	return r.CropType == "" || r.CropType == cropType
+}
+
+// Breached reports whether value is across the rule's threshold
+func (r AlertRule) Breached(value float64) bool {
+	switch r.Operator {
+	case ">":
+		return value > r.Threshold
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	}
+	return false
+}
+
+// Cleared reports whether value is back on the good side of the threshold by
+// more than the hysteresis margin
+func (r AlertRule) Cleared(value float64) bool {
+	switch r.Operator {
+	case ">", ">=":
+		return value < r.Threshold-r.Hysteresis
+	case "<", "<=":
+		return value > r.Threshold+r.Hysteresis
+	}
+	return true
+}
+
+// Alert is a condition on a farm that needs attention
+type Alert struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID         string             `bson:"farm_id" json:"farmId"`
+	RuleID         string             `bson:"rule_id,omitempty" json:"ruleId,omitempty"`
+	DeviceID       string             `bson:"device_id,omitempty" json:"deviceId,omitempty"`
+	Kind           string             `bson:"kind" json:"kind"`
+	Metric         string             `bson:"metric,omitempty" json:"metric,omitempty"`
+	Severity       string             `bson:"severity" json:"severity"`
+	State          string             `bson:"state" json:"state"`
+	Message        string             `bson:"message" json:"message"`
+	Value          float64            `bson:"value" json:"value"` // value that triggered the alert
+	LastValue      float64            `bson:"last_value" json:"lastValue"`
+	TriggeredAt    time.Time          `bson:"triggered_at" json:"triggeredAt"`
+	AcknowledgedAt *time.Time         `bson:"acknowledged_at,omitempty" json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy string             `bson:"acknowledged_by,omitempty" json:"acknowledgedBy,omitempty"`
+	ResolvedAt     *time.Time         `bson:"resolved_at,omitempty" json:"resolvedAt,omitempty"`
+}
+
+// AlertKindRule marks alerts raised by an AlertRule
+const AlertKindRule = "rule"
+
+// AlertFilters narrows down alert listings
+type AlertFilters struct {
+	FarmID string
+	State  string
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Utilities that meters measure
+const (
+	UtilityEnergy = "energy" // in kWh
+	UtilityWater  = "water"  // in litres
+)
+
+// Facility is a building whose farms share meters. Consumption on a
+// facility meter is split across its farms by growing area.
+type Facility struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	FarmIDs   []string           `bson:"farm_ids" json:"farmIds"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// Meter measures the energy or water used by a farm, or by a facility when
+// FacilityID is set instead of FarmID
+type Meter struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	Utility    string             `bson:"utility" json:"utility"`
+	FarmID     string             `bson:"farm_id,omitempty" json:"farmId,omitempty"`
+	FacilityID string             `bson:"facility_id,omitempty" json:"facilityId,omitempty"`
+	TariffID   string             `bson:"tariff_id" json:"tariffId"`
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// MeterReading is the register value of a meter at a point in time, in kWh
+// or litres. Consumption is the difference between successive readings.
+type MeterReading struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	MeterID   string             `bson:"meter_id" json:"meterId"`
+	Timestamp time.Time          `bson:"timestamp" json:"timestamp"`
+	Value     float64            `bson:"value" json:"value"`
+}
+
+// Tariff prices a utility per unit, with time-of-use periods overriding the
+// base price
+type Tariff struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Utility   string             `bson:"utility" json:"utility"`
+	Currency  string             `bson:"currency" json:"currency"`
+	Timezone  string             `bson:"timezone,omitempty" json:"timezone,omitempty"` // IANA zone periods are in, UTC when empty
+	BasePrice float64            `bson:"base_price" json:"basePrice"`                  // per kWh or litre
+	Periods   []TariffPeriod     `bson:"periods,omitempty" json:"periods,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// TariffPeriod is a time-of-use window with its own price. The window runs
+// from StartHour up to EndHour and wraps past midnight when EndHour is not
+// after StartHour.
+type TariffPeriod struct {
+	Name      string         `bson:"name" json:"name"`
+	Weekdays  []time.Weekday `bson:"weekdays,omitempty" json:"weekdays,omitempty"` // 0 is Sunday, every day when empty
+	StartHour int            `bson:"start_hour" json:"startHour"`
+	EndHour   int            `bson:"end_hour" json:"endHour"`
+	Price     float64        `bson:"price" json:"price"`
+}
+
+// PriceAt returns the price per unit at t, given in the tariff's time zone
+func (t Tariff) PriceAt(at time.Time) float64 {
+	hour, weekday := at.Hour(), at.Weekday()
+	for _, p := range t.Periods {
+		// A window wrapping midnight belongs to the day it started on
+		day, inWindow := weekday, false
+		if p.StartHour < p.EndHour {
+			inWindow = hour >= p.StartHour && hour < p.EndHour
+		} else if hour >= p.StartHour {
+			inWindow = true
+		} else if hour < p.EndHour {
+			inWindow, day = true, (weekday+6)%7
+		}
+		if inWindow && p.onDay(day) {
+			return p.Price
+		}
+	}
+	return t.BasePrice
+}
+
+func (p TariffPeriod) onDay(day time.Weekday) bool {
+	if len(p.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range p.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// HarvestRecord is the produce taken from a farm at the end of a growing
+// cycle
+type HarvestRecord struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID      string             `bson:"farm_id" json:"farmId"`
+	CropType    string             `bson:"crop_type" json:"cropType"`
+	CycleStart  time.Time          `bson:"cycle_start" json:"cycleStart"`
+	HarvestedAt time.Time          `bson:"harvested_at" json:"harvestedAt"`
+	YieldKg     float64            `bson:"yield_kg" json:"yieldKg"`
+	Notes       string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// UtilityUsage is the consumption and cost of one utility
+type UtilityUsage struct {
+	Quantity float64 `json:"quantity"` // kWh or litres
+	Cost     float64 `json:"cost"`
+}
+
+// ConsumptionReport is the energy and water a farm used over a period,
+// including its share of facility meters
+type ConsumptionReport struct {
+	FarmID   string       `json:"farmId"`
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Currency string       `json:"currency,omitempty"`
+	Energy   UtilityUsage `json:"energy"`
+	Water    UtilityUsage `json:"water"`
+	Cost     float64      `json:"cost"`
+	// Unmetered lists meters whose readings do not cover the whole period
+	Unmetered []string `json:"unmetered,omitempty"`
+}
+
+// CycleReport relates the consumption of a growing cycle to its harvest
+type CycleReport struct {
+	Harvest         HarvestRecord     `json:"harvest"`
+	Consumption     ConsumptionReport `json:"consumption"`
+	KWhPerKg        float64           `json:"kWhPerKg"`
+	LitersPerKg     float64           `json:"litersPerKg"`
+	CostPerKg       float64           `json:"costPerKg"`
+	EnergyCostPerKg float64           `json:"energyCostPerKg"`
+	WaterCostPerKg  float64           `json:"waterCostPerKg"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Control modes
+const (
+	ControlBangBang = "bang_bang"
+	ControlPID      = "pid"
+)
+
+// Control directions, the effect running the output has on the metric
+const (
+	DirectionLower = "lower" // e.g. fans lower temperature
+	DirectionRaise = "raise" // e.g. dosing pumps raise nutrient level
+)
+
+// Control decision actions besides the actuator actions
+const (
+	ControlOverride = "override" // a technician suspended the policy
+	ControlResume   = "resume"   // a technician lifted the override
+)
+
+// Control actuations, how the controller drives the output
+const (
+	ActuationRun  = "run"  // switch on for a number of seconds
+	ActuationDose = "dose" // dose a quantity
+	ActuationSet  = "set"  // set a level
+)
+
+// ControlPolicy drives one actuator output to keep a metric at its target
+type ControlPolicy struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID    string             `bson:"farm_id" json:"farmId"`
+	Name      string             `bson:"name" json:"name"`
+	Metric    string             `bson:"metric" json:"metric"`
+	Setpoint  *float64           `bson:"setpoint,omitempty" json:"setpoint,omitempty"` // defaults to the crop's optimal value
+	DeviceID  string             `bson:"device_id" json:"deviceId"`
+	Output    string             `bson:"output" json:"output"`
+	Direction string             `bson:"direction" json:"direction"`
+	Mode      string             `bson:"mode" json:"mode"`
+	Actuation string             `bson:"actuation" json:"actuation"`
+	Deadband  float64            `bson:"deadband" json:"deadband"` // errors within it are ignored
+
+	// Bang-bang settings
+	RunSeconds int     `bson:"run_seconds" json:"runSeconds"` // run length each time the metric is out of band
+	DoseAmount float64 `bson:"dose_amount" json:"doseAmount"` // dose each time the metric is out of band
+
+	// PID settings; the controller output is seconds, dose quantity or level
+	// depending on the actuation
+	Kp        float64 `bson:"kp" json:"kp"`
+	Ki        float64 `bson:"ki" json:"ki"`
+	Kd        float64 `bson:"kd" json:"kd"`
+	OutputMin float64 `bson:"output_min" json:"outputMin"`
+	OutputMax float64 `bson:"output_max" json:"outputMax"`
+
+	MinIntervalSeconds int        `bson:"min_interval_seconds" json:"minIntervalSeconds"`          // shortest time between two actions
+	DryRun             bool       `bson:"dry_run" json:"dryRun"`                                   // only log intended actions
+	OverrideUntil      *time.Time `bson:"override_until,omitempty" json:"overrideUntil,omitempty"` // suspended by a technician until
+	OverriddenBy       string     `bson:"overridden_by,omitempty" json:"overriddenBy,omitempty"`
+	OverrideReason     string     `bson:"override_reason,omitempty" json:"overrideReason,omitempty"`
+	CreatedAt          time.Time  `bson:"created_at" json:"createdAt"`
+}
+
+// Overridden reports whether a technician has suspended the policy at t
+func (p ControlPolicy) Overridden(t time.Time) bool {
+	return p.OverrideUntil != nil && t.Before(*p.OverrideUntil)
+}
+
+// ControlDecision is the audit record of an action a policy took, would have
+// taken in dry-run mode or was kept from taking, and of technician overrides
+type ControlDecision struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PolicyID        string             `bson:"policy_id" json:"policyId"`
+	FarmID          string             `bson:"farm_id" json:"farmId"`
+	Metric          string             `bson:"metric" json:"metric"`
+	Value           float64            `bson:"value" json:"value"`
+	Setpoint        float64            `bson:"setpoint" json:"setpoint"`
+	Error           float64            `bson:"error" json:"error"`
+	Action          string             `bson:"action" json:"action"`
+	CommandValue    float64            `bson:"command_value" json:"commandValue"`
+	DurationSeconds int                `bson:"duration_seconds" json:"durationSeconds"`
+	DryRun          bool               `bson:"dry_run" json:"dryRun"`
+	CommandID       string             `bson:"command_id,omitempty" json:"commandId,omitempty"`
+	By              string             `bson:"by,omitempty" json:"by,omitempty"`     // technician behind an override
+	Note            string             `bson:"note,omitempty" json:"note,omitempty"` // why no command was issued, or why the policy was overridden
+	CreatedAt       time.Time          `bson:"created_at" json:"createdAt"`
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Device is a sensor gateway or controller installed on a farm
+type Device struct {
+	ID                       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID                   string             `bson:"farm_id" json:"farmId"`
+	Name                     string             `bson:"name" json:"name"`
+	Model                    string             `bson:"model,omitempty" json:"model,omitempty"`
+	ReportingIntervalSeconds int                `bson:"reporting_interval_seconds" json:"reportingIntervalSeconds"` // how often it should send readings
+	Outputs                  []ActuatorOutput   `bson:"outputs,omitempty" json:"outputs,omitempty"`
+	// LoRaWAN end devices are matched to uplinks by their EUI and decoded
+	// with the named payload decoder
+	DevEUI  string `bson:"dev_eui,omitempty" json:"devEui,omitempty"`
+	Decoder string `bson:"decoder,omitempty" json:"decoder,omitempty"`
+	// ChannelMetrics maps payload channels to metric keys, overriding the
+	// metric the decoder picks for the channel's data type
+	ChannelMetrics map[string]string `bson:"channel_metrics,omitempty" json:"channelMetrics,omitempty"`
+	CreatedAt      time.Time         `bson:"created_at" json:"createdAt"`
+}
+
+// Output returns the controllable output with the given key
+func (d Device) Output(key string) (ActuatorOutput, bool) {
+	for _, o := range d.Outputs {
+		if o.Key == key {
+			return o, true
+		}
+	}
+	return ActuatorOutput{}, false
+}
+
+// DataGap is a period in which a farm or one of its devices sent no readings
+type DataGap struct {
+	ID                      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID                  string             `bson:"farm_id" json:"farmId"`
+	DeviceID                string             `bson:"device_id,omitempty" json:"deviceId,omitempty"` // empty for the farm as a whole
+	Start                   time.Time          `bson:"start" json:"start"`                            // last reading before the gap
+	End                     *time.Time         `bson:"end,omitempty" json:"end,omitempty"`            // first reading after it, nil while open
+	ExpectedIntervalSeconds int                `bson:"expected_interval_seconds" json:"expectedIntervalSeconds"`
+	AlertID                 string             `bson:"alert_id,omitempty" json:"alertId,omitempty"`
+}
+
+// DataFreshness describes how recent the data of a farm or device is
+type DataFreshness struct {
+	DeviceID                string     `json:"deviceId,omitempty"`
+	LastReadingAt           *time.Time `json:"lastReadingAt,omitempty"`
+	AgeSeconds              int64      `json:"ageSeconds"`
+	ExpectedIntervalSeconds int        `json:"expectedIntervalSeconds"`
+	Stale                   bool       `json:"stale"`
+	StaleSince              *time.Time `json:"staleSince,omitempty"`
+}
+
+// FarmStatus summarises the current state of a farm and its data freshness
+type FarmStatus struct {
+	FarmID        string          `json:"farmId"`
+	Status        string          `json:"status"`
+	CurrentHealth int             `json:"currentHealth"`
+	Freshness     DataFreshness   `json:"freshness"`
+	Devices       []DataFreshness `json:"devices"`
+}
+
+// AlertKindStaleData marks alerts raised when a farm or device stops reporting
+const AlertKindStaleData = "stale_data"
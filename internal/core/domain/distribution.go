@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"0xFarms-backend/pkg/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Distribution statuses
+const (
+	DistributionPreview   = "preview"   // computed but not stored
+	DistributionFinalized = "finalized" // stored, payouts are owed
+)
+
+// DistributionRequest is the sale of a harvest to share among the owners
+type DistributionRequest struct {
+	Revenue  money.Amount `json:"revenue"`
+	Costs    money.Amount `json:"costs"`
+	Currency string       `json:"currency"`
+	// SnapshotAt is when ownership is taken, the harvest time when empty
+	SnapshotAt *time.Time `json:"snapshotAt,omitempty"`
+}
+
+// Distribution shares the profit of a harvest among the farm's owners in
+// proportion to their shares at the snapshot time
+type Distribution struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID     string             `bson:"farm_id" json:"farmId"`
+	HarvestID  string             `bson:"harvest_id" json:"harvestId"`
+	Currency   string             `bson:"currency" json:"currency"`
+	Revenue    money.Amount       `bson:"revenue" json:"revenue"`
+	Costs      money.Amount       `bson:"costs" json:"costs"`
+	Profit     money.Amount       `bson:"profit" json:"profit"`
+	SnapshotAt time.Time          `bson:"snapshot_at" json:"snapshotAt"`
+	Lines      []DistributionLine `bson:"lines" json:"lines"`
+	// Retained is the profit of shares nobody owned, kept by the operator
+	Retained    money.Amount `bson:"retained" json:"retained"`
+	Status      string       `bson:"status" json:"status"`
+	CreatedAt   time.Time    `bson:"created_at" json:"createdAt"`
+	FinalizedAt *time.Time   `bson:"finalized_at,omitempty" json:"finalizedAt,omitempty"`
+}
+
+// DistributionLine is the payout owed to one owner entry
+type DistributionLine struct {
+	OwnerID string       `bson:"owner_id" json:"ownerId"`
+	Address string       `bson:"address" json:"address"`
+	Shares  Shares       `bson:"shares" json:"shareSize"`
+	Amount  money.Amount `bson:"amount" json:"amount"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Export file formats
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+	ExportXLSX   = "xlsx"
+)
+
+// ResolutionAuto picks the rollup resolution from the exported time range
+const ResolutionAuto = "auto"
+
+// ExportRequest selects the readings of a farm to export
+type ExportRequest struct {
+	Format  string
+	From    time.Time
+	To      time.Time
+	Metrics []string // metric keys to include, every registered metric when empty
+	// Resolution exports rollup buckets instead of raw readings when set
+	Resolution string
+}
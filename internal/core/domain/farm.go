@@ -1,24 +1,154 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Farm lifecycle statuses
+const (
+	FarmActive      = "active"
+	FarmHarvested   = "harvested"
+	FarmMaintenance = "maintenance"
+	// FarmSettling is set while harvest proceeds are settled with the
+	// owners, and freezes ownership until it ends
+	FarmSettling = "settling"
+)
+
+// ValidFarmStatus reports whether status is a farm lifecycle status
+func ValidFarmStatus(status string) bool {
+	switch status {
+	case FarmActive, FarmHarvested, FarmMaintenance, FarmSettling:
+		return true
+	}
+	return false
+}
 
 // Owner represents a stakeholder in the farm
 type Owner struct {
-	ID        string    `json:"id"`
-	Address   string    `json:"address"`
-	ShareSize float64   `json:"shareSize"` // Percentage of ownership
-	JoinedAt  time.Time `json:"joinedAt"`
+	ID       string    `json:"id"`
+	Address  string    `json:"address"`
+	Shares   Shares    `json:"shareSize"` // Share units, rendered as a percentage of ownership
+	JoinedAt time.Time `json:"joinedAt"`
 }
 
 // IoTReading represents a single data point from IoT sensors
 type IoTReading struct {
 	Timestamp     time.Time `json:"timestamp"`
+	DeviceID      string    `json:"deviceId,omitempty"`
 	SoilPH        float64   `json:"soilPH"`
 	Humidity      float64   `json:"humidity"`
 	NutrientLevel float64   `json:"nutrientLevel"`
 	CropHealth    int       `json:"cropHealth"`    // Scale of 1-100
 	ExpectedYield float64   `json:"expectedYield"` // in kgs
 	Temperature   float64   `json:"temperature"`   // in Celsius
+	// Metrics holds any registered metric keyed by MetricDefinition.Key,
+	// in the metric's canonical unit
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// Value returns the reading for a metric key. Every value set through
+// SetValue or decoded from JSON is carried in Metrics, so a zero there is a
+// real measurement; the fixed fields are only consulted for readings stored
+// before Metrics existed, which always carried all of them.
+func (r IoTReading) Value(key string) (float64, bool) {
+	if r.Metrics != nil {
+		v, ok := r.Metrics[key]
+		return v, ok
+	}
+
+	switch key {
+	case MetricPH:
+		return r.SoilPH, true
+	case MetricHumidity:
+		return r.Humidity, true
+	case MetricNutrientLevel:
+		return r.NutrientLevel, true
+	case MetricTemperature:
+		return r.Temperature, true
+	}
+	return 0, false
+}
+
+// SetValue stores the value of a metric key in Metrics, mirroring the legacy
+// keys into their fixed fields for clients that still read them
+func (r *IoTReading) SetValue(key string, value float64) {
+	if r.Metrics == nil {
+		r.Metrics = r.legacyValues()
+	}
+	r.Metrics[key] = value
+
+	switch key {
+	case MetricPH:
+		r.SoilPH = value
+	case MetricHumidity:
+		r.Humidity = value
+	case MetricNutrientLevel:
+		r.NutrientLevel = value
+	case MetricTemperature:
+		r.Temperature = value
+	}
+}
+
+// legacyValues returns the fixed fields of a reading that predates Metrics
+// keyed by metric, or an empty map for a reading that carries none of them
+func (r IoTReading) legacyValues() map[string]float64 {
+	values := make(map[string]float64)
+	if r.SoilPH == 0 && r.Humidity == 0 && r.NutrientLevel == 0 && r.Temperature == 0 {
+		return values
+	}
+	values[MetricPH] = r.SoilPH
+	values[MetricHumidity] = r.Humidity
+	values[MetricNutrientLevel] = r.NutrientLevel
+	values[MetricTemperature] = r.Temperature
+	return values
+}
+
+// MetricValues returns every metric carried by the reading
+func (r IoTReading) MetricValues() map[string]float64 {
+	if r.Metrics == nil {
+		return r.legacyValues()
+	}
+	values := make(map[string]float64, len(r.Metrics))
+	for key, v := range r.Metrics {
+		values[key] = v
+	}
+	return values
+}
+
+// UnmarshalJSON decodes a reading, moving the legacy fields that are present
+// in the document into Metrics so that a zero is kept apart from an absent
+// value
+func (r *IoTReading) UnmarshalJSON(data []byte) error {
+	type plain IoTReading
+	var legacy struct {
+		SoilPH        *float64 `json:"soilPH"`
+		Humidity      *float64 `json:"humidity"`
+		NutrientLevel *float64 `json:"nutrientLevel"`
+		Temperature   *float64 `json:"temperature"`
+	}
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	if r.Metrics == nil {
+		r.Metrics = make(map[string]float64)
+	}
+	for key, v := range map[string]*float64{
+		MetricPH:            legacy.SoilPH,
+		MetricHumidity:      legacy.Humidity,
+		MetricNutrientLevel: legacy.NutrientLevel,
+		MetricTemperature:   legacy.Temperature,
+	} {
+		if _, ok := r.Metrics[key]; v != nil && !ok {
+			r.SetValue(key, *v)
+		}
+	}
+	return nil
 }
 
 // VerticalFarm represents a single vertical farming unit
@@ -32,9 +162,19 @@ type VerticalFarm struct {
 	EstimatedHarvestTime time.Time    `json:"estimatedHarvestTime"`
 	Owners               []Owner      `json:"owners"`
 	IoTData              []IoTReading `json:"iotData"`
-	Status               string       `json:"status"` // active, harvested, maintenance
+	Status               string       `json:"status"` // active, harvested, maintenance, settling
 	CurrentHealth        int          `json:"currentHealth"`
 	LastUpdated          time.Time    `json:"lastUpdated"`
+	// Data freshness, maintained by the reporting watchdog
+	LastReadingAt            time.Time  `json:"lastReadingAt"`
+	ReportingIntervalSeconds int        `json:"reportingIntervalSeconds,omitempty"` // expected time between readings
+	DataStale                bool       `json:"dataStale"`
+	StaleSince               *time.Time `json:"staleSince,omitempty"`
+	// Reservoir describes the nutrient tank, used for dosing recommendations
+	Reservoir *Reservoir `json:"reservoir,omitempty"`
+	// Offering is the price of the farm's shares, nil when not open to
+	// investment
+	Offering *Offering `json:"offering,omitempty"`
 }
 
 // CropSpecification contains default parameters for different crops
@@ -46,4 +186,85 @@ type CropSpecification struct {
 	OptimalTemp        float64       `json:"optimalTemp"`
 	NutrientNeeds      float64       `json:"nutrientNeeds"`
 	ExpectedYieldPerM2 float64       `json:"expectedYieldPerM2"`
+	// Targets lists the metrics used for health scoring. When empty the
+	// optimal pH, humidity, temperature and nutrient fields are used.
+	Targets []MetricTarget `json:"targets,omitempty"`
+	// Stages adjusts targets as the crop grows, ordered by StartDay
+	Stages []CropStage `json:"stages,omitempty"`
+	// BaseTemperature is the temperature below which the crop stops
+	// developing, in Celsius. DefaultBaseTemperature is used when it is zero.
+	BaseTemperature float64 `json:"baseTemperature,omitempty"`
+}
+
+// DefaultBaseTemperature is the base temperature of crops that set none,
+// typical for leafy greens
+const DefaultBaseTemperature = 4.0
+
+// CropStage is a growth stage with its own optimal values for some metrics
+type CropStage struct {
+	Name     string         `json:"name"`
+	StartDay int            `json:"startDay"` // days after planting
+	Targets  []MetricTarget `json:"targets"`
+}
+
+// MetricTargets returns the scoring targets for the crop, falling back to the
+// fixed optimal fields when no explicit targets are configured
+func (c CropSpecification) MetricTargets() []MetricTarget {
+	if len(c.Targets) > 0 {
+		return c.Targets
+	}
+	return []MetricTarget{
+		{Metric: MetricPH, Optimal: c.OptimalPH, Scoring: ScoringDeviation, Penalty: 10, Weight: 0.25},
+		{Metric: MetricHumidity, Optimal: c.OptimalHumidity, Scoring: ScoringDeviation, Penalty: 1, Weight: 0.25},
+		{Metric: MetricTemperature, Optimal: c.OptimalTemp, Scoring: ScoringDeviation, Penalty: 2, Weight: 0.25},
+		{Metric: MetricNutrientLevel, Optimal: c.NutrientNeeds, Scoring: ScoringRatio, Weight: 0.25},
+	}
+}
+
+// Target returns the scoring target for a metric, if the crop has one
+func (c CropSpecification) Target(metric string) (MetricTarget, bool) {
+	for _, t := range c.MetricTargets() {
+		if t.Metric == metric {
+			return t, true
+		}
+	}
+	return MetricTarget{}, false
+}
+
+// BaseTemp returns the temperature below which the crop stops developing
+func (c CropSpecification) BaseTemp() float64 {
+	if c.BaseTemperature != 0 {
+		return c.BaseTemperature
+	}
+	return DefaultBaseTemperature
+}
+
+// DegreeDays returns the thermal time a day at the given mean temperature
+// contributes to the crop's development
+func (c CropSpecification) DegreeDays(meanTemp float64) float64 {
+	return math.Max(0, meanTemp-c.BaseTemp())
+}
+
+// RequiredDegreeDays returns the thermal time from planting to harvest,
+// assuming the growth period was measured at the optimal temperature. It is
+// zero when the optimal temperature is not above the base temperature.
+func (c CropSpecification) RequiredDegreeDays() float64 {
+	optimal := c.OptimalTemp
+	if t, ok := c.Target(MetricTemperature); ok {
+		optimal = t.Optimal
+	}
+	return c.GrowthPeriod.Hours() / 24 * c.DegreeDays(optimal)
+}
+
+// StageAt returns the growth stage the crop is in the given number of days
+// after planting, if the crop has stages
+func (c CropSpecification) StageAt(day int) (CropStage, bool) {
+	var stage CropStage
+	found := false
+	for _, s := range c.Stages {
+		if s.StartDay <= day && (!found || s.StartDay >= stage.StartDay) {
+			stage, found = s, true
+		}
+	}
+	return stage, found
 }
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Import file formats
+const (
+	ImportCSV     = "csv"
+	ImportParquet = "parquet"
+)
+
+// Import job statuses
+const (
+	ImportQueued    = "queued"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+	ImportCancelled = "cancelled"
+)
+
+// Column mapping targets besides metric keys
+const (
+	ColumnTimestamp = "timestamp"
+	ColumnDeviceID  = "deviceId"
+)
+
+// ImportJob imports historical readings from a CSV or Parquet file. Jobs
+// commit their progress in batches and resume from the last batch after a
+// restart or failure.
+type ImportJob struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID   string             `bson:"farm_id" json:"farmId"`
+	Format   string             `bson:"format" json:"format"`
+	FileName string             `bson:"file_name" json:"fileName"`
+	Path     string             `bson:"path" json:"-"` // where the uploaded file is kept until the job completes
+	// Mapping maps column names to metric keys, timestamp or deviceId.
+	// Columns are matched by name when it is empty.
+	Mapping         map[string]string `bson:"mapping" json:"mapping"`
+	Units           map[string]string `bson:"units" json:"units"`                                          // unit each metric is reported in
+	Timezone        string            `bson:"timezone,omitempty" json:"timezone,omitempty"`                // for timestamps without an offset
+	TimestampFormat string            `bson:"timestamp_format,omitempty" json:"timestampFormat,omitempty"` // Go layout, unix or unixms
+	Status          string            `bson:"status" json:"status"`
+	Offset          int64             `bson:"offset" json:"-"` // byte offset for CSV, row index for Parquet
+	BytesTotal      int64             `bson:"bytes_total" json:"bytesTotal"`
+	RowsTotal       int64             `bson:"rows_total" json:"rowsTotal,omitempty"` // known up front for Parquet only
+	RowsRead        int64             `bson:"rows_read" json:"rowsRead"`
+	RowsImported    int64             `bson:"rows_imported" json:"rowsImported"`
+	RowsDuplicate   int64             `bson:"rows_duplicate" json:"rowsDuplicate"` // already imported before
+	RowsRejected    int64             `bson:"rows_rejected" json:"rowsRejected"`
+	Progress        float64           `bson:"progress" json:"progress"` // percent done
+	Error           string            `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt       time.Time         `bson:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time         `bson:"updated_at" json:"updatedAt"`
+	CompletedAt     *time.Time        `bson:"completed_at,omitempty" json:"completedAt,omitempty"`
+}
+
+// Finished reports whether the job has stopped for good or until resumed
+func (j ImportJob) Finished() bool {
+	return j.Status == ImportCompleted || j.Status == ImportFailed || j.Status == ImportCancelled
+}
+
+// ImportRejection is a row an import job could not turn into a reading
+type ImportRejection struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	JobID  string             `bson:"job_id" json:"jobId"`
+	Row    int64              `bson:"row" json:"row"` // 1-based, not counting the header
+	Reason string             `bson:"reason" json:"reason"`
+	Values map[string]string  `bson:"values" json:"values"`
+}
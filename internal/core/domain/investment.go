@@ -1,8 +1,49 @@
 package domain
 
-// Investment represents an investment made by an investor
+import (
+	"0xFarms-backend/pkg/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Investment statuses
+const (
+	InvestmentPending   = "pending"   // awaiting payment, shares are reserved
+	InvestmentConfirmed = "confirmed" // paid, the investor owns the shares
+	InvestmentRefunded  = "refunded"  // money returned, shares released
+)
+
+// Investment represents an investment made by an investor in a farm, in
+// exchange for a share of its ownership. Investors paying on Stellar send
+// the investment's ID as a text memo for the payment to be matched.
 type Investment struct {
-	InvestorID int
-	FarmID     int
-	Amount     float64
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	InvestorID string             `bson:"investor_id" json:"investorId"`
+	FarmID     string             `bson:"farm_id" json:"farmId"`
+	// Address is the bound wallet receiving the ownership, the investor's
+	// only wallet when not given
+	Address       string       `bson:"address" json:"address"`
+	Amount        money.Amount `bson:"amount" json:"amount"`
+	Currency      string       `bson:"currency" json:"currency"`
+	Shares        Shares       `bson:"shares" json:"shareSize"`              // ownership purchased, rendered as a percentage
+	PricePerShare money.Amount `bson:"price_per_share" json:"pricePerShare"` // amount per percentage point
+	Status        string       `bson:"status" json:"status"`
+	OwnerID       string       `bson:"owner_id,omitempty" json:"ownerId,omitempty"` // farm owner entry created on confirmation
+	CreatedAt     time.Time    `bson:"created_at" json:"createdAt"`
+	ConfirmedAt   *time.Time   `bson:"confirmed_at,omitempty" json:"confirmedAt,omitempty"`
+	RefundedAt    *time.Time   `bson:"refunded_at,omitempty" json:"refundedAt,omitempty"`
+}
+
+// Offering is the price an operator sells a farm's shares to investors at.
+// Investment amounts are derived from it, never taken from the investor.
+type Offering struct {
+	Currency      string       `bson:"currency" json:"currency"`
+	PricePerShare money.Amount `bson:"price_per_share" json:"pricePerShare"` // amount per percentage point
+	UpdatedAt     time.Time    `bson:"updated_at" json:"updatedAt"`
+}
+
+// Amount returns the price of shares at the offering
+func (o *Offering) Amount(shares Shares) (money.Amount, error) {
+	return o.PricePerShare.MulDiv(int64(shares), int64(SharesPerPercent))
 }
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"0xFarms-backend/pkg/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Order sides
+const (
+	OrderAsk = "ask" // an owner offering shares
+	OrderBid = "bid" // a buyer wanting shares
+)
+
+// Order statuses
+const (
+	OrderOpen      = "open"
+	OrderFilled    = "filled"
+	OrderCancelled = "cancelled"
+)
+
+// Trade statuses
+const (
+	TradePending   = "pending"   // matched, awaiting the buyer's payment
+	TradeSettled   = "settled"   // paid for, the shares are the buyer's
+	TradeCancelled = "cancelled" // never paid for, the shares stay the seller's
+)
+
+// ShareOrder is an offer to sell or buy shares of a farm at a price per
+// percentage point. Orders fill in part as they match and rest in the
+// farm's order book until filled or cancelled.
+type ShareOrder struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID string             `bson:"farm_id" json:"farmId"`
+	Side   string             `bson:"side" json:"side"`
+	// OwnerID is the owner entry an ask sells from
+	OwnerID string `bson:"owner_id,omitempty" json:"ownerId,omitempty"`
+	// Address is the seller of an ask or receives the shares of a bid
+	Address       string       `bson:"address" json:"address"`
+	Shares        Shares       `bson:"shares" json:"shareSize"`
+	Remaining     Shares       `bson:"remaining" json:"remaining"`
+	PricePerShare money.Amount `bson:"price_per_share" json:"pricePerShare"` // amount per percentage point
+	Currency      string       `bson:"currency" json:"currency"`
+	Status        string       `bson:"status" json:"status"`
+	CreatedAt     time.Time    `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time    `bson:"updated_at" json:"updatedAt"`
+}
+
+// ShareTrade records shares moving from one owner to another, either by
+// orders matching or by a direct transfer without an order. Matched trades
+// stay pending, the shares reserved from the seller, until a ledger payment
+// from the buyer to the seller settles them; transfers settle at once.
+type ShareTrade struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID        string             `bson:"farm_id" json:"farmId"`
+	AskID         string             `bson:"ask_id,omitempty" json:"askId,omitempty"`
+	BidID         string             `bson:"bid_id,omitempty" json:"bidId,omitempty"`
+	SellerOwnerID string             `bson:"seller_owner_id" json:"sellerOwnerId"`
+	SellerAddress string             `bson:"seller_address" json:"sellerAddress"`
+	BuyerOwnerID  string             `bson:"buyer_owner_id" json:"buyerOwnerId"` // owner entry created for the buyer once settled
+	BuyerAddress  string             `bson:"buyer_address" json:"buyerAddress"`
+	Shares        Shares             `bson:"shares" json:"shareSize"`
+	PricePerShare money.Amount       `bson:"price_per_share" json:"pricePerShare"`
+	Amount        money.Amount       `bson:"amount" json:"amount"`
+	Currency      string             `bson:"currency,omitempty" json:"currency,omitempty"`
+	Status        string             `bson:"status" json:"status"`
+	PaymentID     string             `bson:"payment_id,omitempty" json:"paymentId,omitempty"` // ledger operation that paid for the shares
+	ExecutedAt    time.Time          `bson:"executed_at" json:"executedAt"`
+	SettledAt     *time.Time         `bson:"settled_at,omitempty" json:"settledAt,omitempty"`
+}
+
+// OrderBook is the open orders of a farm, best price first
+type OrderBook struct {
+	FarmID string       `json:"farmId"`
+	Asks   []ShareOrder `json:"asks"`
+	Bids   []ShareOrder `json:"bids"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+// Well-known metric keys. The first four are mirrored into the fixed
+// IoTReading fields, the rest are only carried in IoTReading.Metrics.
+const (
+	MetricPH              = "ph"
+	MetricHumidity        = "humidity"
+	MetricNutrientLevel   = "nutrient_level"
+	MetricTemperature     = "temperature"
+	MetricCO2             = "co2"
+	MetricPPFD            = "ppfd"
+	MetricEC              = "ec"
+	MetricWaterTemp       = "water_temperature"
+	MetricDissolvedOxygen = "dissolved_oxygen"
+	MetricVPD             = "vpd"
+)
+
+// Scoring modes for a MetricTarget
+const (
+	// ScoringDeviation loses Penalty points for every unit away from Optimal
+	ScoringDeviation = "deviation"
+	// ScoringRatio scores the reading as a percentage of Optimal
+	ScoringRatio = "ratio"
+)
+
+// MetricDefinition describes a sensor metric that readings may carry
+type MetricDefinition struct {
+	Key         string  `bson:"key" json:"key"`
+	Name        string  `bson:"name" json:"name"`
+	Unit        string  `bson:"unit" json:"unit"` // canonical unit values are stored in
+	Dimension   string  `bson:"dimension,omitempty" json:"dimension,omitempty"`
+	Min         float64 `bson:"min" json:"min"` // lowest physically valid value
+	Max         float64 `bson:"max" json:"max"` // highest physically valid value
+	Description string  `bson:"description,omitempty" json:"description,omitempty"`
+}
+
+// InRange reports whether value lies within the metric's valid range
+func (m MetricDefinition) InRange(value float64) bool {
+	return value >= m.Min && value <= m.Max
+}
+
+// MetricTarget is the optimal value of one metric for a crop and how much it
+// contributes to the health score
+type MetricTarget struct {
+	Metric  string  `json:"metric"`
+	Optimal float64 `json:"optimal"`
+	Scoring string  `json:"scoring"` // deviation or ratio
+	Penalty float64 `json:"penalty"` // points lost per unit of deviation
+	Weight  float64 `json:"weight"`
+}
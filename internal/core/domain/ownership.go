@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ownership event types
+const (
+	OwnershipIssued  = "issued"  // shares given to a new owner entry, such as by an investment
+	OwnershipRemoved = "removed" // shares taken back, such as by a refund
+	OwnershipTraded  = "traded"  // shares moved between owners by a trade or transfer
+)
+
+// OwnershipEvent is an immutable record of a change to one owner entry of a
+// farm. Replaying a farm's events up to a time gives its holders then.
+type OwnershipEvent struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID  string             `bson:"farm_id" json:"farmId"`
+	Type    string             `bson:"type" json:"type"`
+	OwnerID string             `bson:"owner_id" json:"ownerId"`
+	Address string             `bson:"address" json:"address"`
+	// Change is the shares gained by the owner entry, negative when lost
+	Change     Shares    `bson:"change" json:"change"`
+	TradeID    string    `bson:"trade_id,omitempty" json:"tradeId,omitempty"`
+	OccurredAt time.Time `bson:"occurred_at" json:"occurredAt"`
+}
+
+// CapTable is who owned a farm at a point in time
+type CapTable struct {
+	FarmID  string    `json:"farmId"`
+	At      time.Time `json:"at"`
+	Holders []Owner   `json:"holders"` // owner entries, largest first
+	// Addresses adds up the entries held by each address, largest first
+	Addresses []AddressHolding `json:"addresses"`
+	Issued    Shares           `json:"issued"`
+	Unissued  Shares           `json:"unissued"`
+}
+
+// AddressHolding is the total shares held by an address
+type AddressHolding struct {
+	Address string `json:"address"`
+	Shares  Shares `json:"shareSize"`
+}
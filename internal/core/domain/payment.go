@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"0xFarms-backend/pkg/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Incoming payment statuses. Every status but received and matched needs
+// review.
+const (
+	PaymentReceived    = "received"    // recorded, not yet matched
+	PaymentMatched     = "matched"     // paid its investment, alone or with earlier payments
+	PaymentUnderpaid   = "underpaid"   // short of its investment so far
+	PaymentOverpaid    = "overpaid"    // paid more than its investment owed
+	PaymentUnmatched   = "unmatched"   // names no pending investment, or is in the wrong asset
+	PaymentUnconfirmed = "unconfirmed" // paid its investment, which then failed to confirm
+)
+
+// IncomingPayment is a payment received on Stellar for a share purchase.
+// Investors send the ID of their investment as a text memo.
+type IncomingPayment struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PaymentID       string             `bson:"payment_id" json:"paymentId"` // ledger operation ID
+	TransactionHash string             `bson:"transaction_hash" json:"transactionHash"`
+	From            string             `bson:"from" json:"from"`
+	To              string             `bson:"to" json:"to"`
+	// AssetCode is XLM for lumens, which have no issuer
+	AssetCode    string       `bson:"asset_code" json:"assetCode"`
+	AssetIssuer  string       `bson:"asset_issuer,omitempty" json:"assetIssuer,omitempty"`
+	Amount       money.Amount `bson:"amount" json:"amount"`
+	Memo         string       `bson:"memo" json:"memo"`
+	ReceivedAt   time.Time    `bson:"received_at" json:"receivedAt"`
+	InvestmentID string       `bson:"investment_id,omitempty" json:"investmentId,omitempty"`
+	Status       string       `bson:"status" json:"status"`
+	Note         string       `bson:"note,omitempty" json:"note,omitempty"` // why the payment needs review
+	RecordedAt   time.Time    `bson:"recorded_at" json:"recordedAt"`
+}
+
+// PaymentReviewStatuses are the statuses of payments needing review
+var PaymentReviewStatuses = []string{PaymentUnderpaid, PaymentOverpaid, PaymentUnmatched, PaymentUnconfirmed}
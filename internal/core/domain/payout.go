@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Payout statuses
+const (
+	PayoutOpen      = "open"      // some lines are unpaid
+	PayoutCompleted = "completed" // every line is paid
+)
+
+// Payout batch statuses
+const (
+	BatchPending   = "pending"   // built, not yet submitted
+	BatchSubmitted = "submitted" // sent, outcome unknown
+	BatchConfirmed = "confirmed" // included in the ledger and successful
+	BatchFailed    = "failed"    // rejected or included unsuccessfully, nothing was paid
+)
+
+// Payout pays the lines of a distribution on Stellar. Lines are grouped into
+// batches of one transaction each, with the distribution's ID as memo.
+type Payout struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DistributionID string             `bson:"distribution_id" json:"distributionId"`
+	FarmID         string             `bson:"farm_id" json:"farmId"`
+	AssetCode      string             `bson:"asset_code" json:"assetCode"`
+	// AssetIssuer is empty for lumens
+	AssetIssuer string        `bson:"asset_issuer,omitempty" json:"assetIssuer,omitempty"`
+	Source      string        `bson:"source" json:"source"` // the paying account
+	Batches     []PayoutBatch `bson:"batches" json:"batches"`
+	// Held lists lines that cannot be paid yet, such as to accounts without
+	// a trustline to the asset, so they do not fail a whole batch
+	Held      []PayoutHold `bson:"held" json:"held"`
+	Status    string       `bson:"status" json:"status"`
+	CreatedAt time.Time    `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time    `bson:"updated_at" json:"updatedAt"`
+}
+
+// PayoutBatch is one payout transaction. The signed envelope is stored
+// before it is submitted, so a retry can find out whether it landed
+// instead of paying twice.
+type PayoutBatch struct {
+	Number      int                `bson:"number" json:"number"`
+	Lines       []DistributionLine `bson:"lines" json:"lines"`
+	Sequence    int64              `bson:"sequence,omitempty" json:"sequence,omitempty"`
+	Envelope    string             `bson:"envelope,omitempty" json:"-"`
+	Hash        string             `bson:"hash,omitempty" json:"hash,omitempty"`
+	Status      string             `bson:"status" json:"status"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	Ledger      int32              `bson:"ledger,omitempty" json:"ledger,omitempty"`
+	SubmittedAt *time.Time         `bson:"submitted_at,omitempty" json:"submittedAt,omitempty"`
+	ConfirmedAt *time.Time         `bson:"confirmed_at,omitempty" json:"confirmedAt,omitempty"`
+}
+
+// PayoutHold is a distribution line left out of the batches
+type PayoutHold struct {
+	DistributionLine `bson:",inline"`
+	Reason           string `bson:"reason" json:"reason"`
+}
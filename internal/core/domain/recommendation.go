@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dosing action kinds
+const (
+	DoseConcentrate = "concentrate" // nutrient concentrate, in mL
+	DoseWater       = "water"       // fresh water to dilute the tank, in L
+	DosePHDown      = "ph_down"     // pH down solution, in mL
+	DosePHUp        = "ph_up"       // pH up solution, in mL
+)
+
+// Reservoir describes a farm's nutrient tank and the solutions dosed into it.
+// Strengths are the change one mL per litre of tank causes.
+type Reservoir struct {
+	VolumeLiters        float64 `json:"volumeLiters"`
+	ConcentrateStrength float64 `json:"concentrateStrength"` // mS/cm per mL/L
+	PHDownStrength      float64 `json:"phDownStrength"`      // pH units per mL/L
+	PHUpStrength        float64 `json:"phUpStrength"`        // pH units per mL/L
+}
+
+// DosingAction is one addition to the nutrient tank
+type DosingAction struct {
+	Kind   string  `bson:"kind" json:"kind"`
+	Amount float64 `bson:"amount" json:"amount"`
+	Unit   string  `bson:"unit" json:"unit"`
+}
+
+// DosingRecommendation is the advice given to technicians for a farm's tank,
+// together with whether it was followed and what happened to crop health
+type DosingRecommendation struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID         string             `bson:"farm_id" json:"farmId"`
+	Stage          string             `bson:"stage,omitempty" json:"stage,omitempty"`
+	Reservoir      Reservoir          `bson:"reservoir" json:"reservoir"`
+	Nutrient       float64            `bson:"nutrient" json:"nutrient"` // EC in mS/cm
+	TargetNutrient float64            `bson:"target_nutrient" json:"targetNutrient"`
+	PH             float64            `bson:"ph" json:"ph"`
+	TargetPH       float64            `bson:"target_ph" json:"targetPH"`
+	Actions        []DosingAction     `bson:"actions" json:"actions"`
+	HealthBefore   int                `bson:"health_before" json:"healthBefore"`
+	CreatedAt      time.Time          `bson:"created_at" json:"createdAt"`
+	Followed       *bool              `bson:"followed,omitempty" json:"followed,omitempty"`
+	RespondedBy    string             `bson:"responded_by,omitempty" json:"respondedBy,omitempty"`
+	RespondedAt    *time.Time         `bson:"responded_at,omitempty" json:"respondedAt,omitempty"`
+	HealthAfter    *int               `bson:"health_after,omitempty" json:"healthAfter,omitempty"`
+	NutrientAfter  *float64           `bson:"nutrient_after,omitempty" json:"nutrientAfter,omitempty"`
+	PHAfter        *float64           `bson:"ph_after,omitempty" json:"phAfter,omitempty"`
+	MeasuredAt     *time.Time         `bson:"measured_at,omitempty" json:"measuredAt,omitempty"`
+}
+
+// RecommendationEffect summarises the health change after recommendations
+// that were followed or ignored
+type RecommendationEffect struct {
+	Count             int     `json:"count"`
+	AverageHealthGain float64 `json:"averageHealthGain"`
+}
+
+// RecommendationSummary compares the health change after followed and
+// ignored recommendations
+type RecommendationSummary struct {
+	Followed RecommendationEffect `json:"followed"`
+	Ignored  RecommendationEffect `json:"ignored"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Rollup resolutions
+const (
+	Resolution5Min   = "5m"
+	ResolutionHourly = "1h"
+	ResolutionDaily  = "1d"
+)
+
+// ResolutionDurations maps each rollup resolution to its bucket width
+var ResolutionDurations = map[string]time.Duration{
+	Resolution5Min:   5 * time.Minute,
+	ResolutionHourly: time.Hour,
+	ResolutionDaily:  24 * time.Hour,
+}
+
+// MetricAggregate summarises the values of one metric within a rollup bucket
+type MetricAggregate struct {
+	Min   float64 `bson:"min" json:"min"`
+	Max   float64 `bson:"max" json:"max"`
+	Sum   float64 `bson:"sum" json:"sum"`
+	Count int64   `bson:"count" json:"count"`
+}
+
+// Avg returns the mean value of the bucket
+func (a MetricAggregate) Avg() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// MarshalJSON renders the aggregate with its average
+func (a MetricAggregate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Min   float64 `json:"min"`
+		Max   float64 `json:"max"`
+		Avg   float64 `json:"avg"`
+		Count int64   `json:"count"`
+	}{a.Min, a.Max, a.Avg(), a.Count})
+}
+
+// ReadingRollup aggregates the readings of a farm over one time bucket
+type ReadingRollup struct {
+	FarmID      string                     `bson:"farm_id" json:"farmId"`
+	Resolution  string                     `bson:"resolution" json:"resolution"`
+	BucketStart time.Time                  `bson:"bucket_start" json:"bucketStart"`
+	Metrics     map[string]MetricAggregate `bson:"metrics" json:"metrics"`
+	Health      MetricAggregate            `bson:"health" json:"health"`
+}
+
+// RollupSeries is a run of rollup buckets together with the periods in which
+// no data was received, so charts can show gaps instead of interpolating
+type RollupSeries struct {
+	Resolution string          `json:"resolution"`
+	Buckets    []ReadingRollup `json:"buckets"`
+	Gaps       []DataGap       `json:"gaps"`
+}
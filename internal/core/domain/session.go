@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuthSession is a login by Stellar web authentication. Only a hash of the
+// session token is stored, so the database alone cannot be used to log in.
+type AuthSession struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	// ChallengeHash is the hash of the challenge transaction the session was
+	// issued for, each challenge logging in once
+	ChallengeHash string `bson:"challenge_hash" json:"-"`
+	AccountID     string `bson:"account_id" json:"accountId"`
+	// UserID is the user the account is bound to as a wallet, if any
+	UserID    string    `bson:"user_id,omitempty" json:"userId,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"createdAt"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expiresAt"`
+}
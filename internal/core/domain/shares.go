@@ -0,0 +1,48 @@
+package domain
+
+import "0xFarms-backend/pkg/money"
+
+// SharePercentDecimals is the precision ownership percentages are kept to
+const SharePercentDecimals = 4
+
+// SharesPerFarm is the number of share units every farm is divided into, so
+// one unit is 0.0001% of the farm
+const SharesPerFarm Shares = 1_000_000
+
+// SharesPerPercent is the number of share units in one percent of a farm
+const SharesPerPercent = SharesPerFarm / 100
+
+// Shares is an exact amount of farm ownership in share units. It is stored
+// as an integer and renders in JSON as a percentage of the farm.
+type Shares int64
+
+// ParseSharePercent reads a percentage such as "12.5" into share units
+func ParseSharePercent(percent string) (Shares, error) {
+	v, err := money.ParseDecimal(percent, SharePercentDecimals)
+	return Shares(v), err
+}
+
+// Percent renders the shares as a percentage of the farm
+func (s Shares) Percent() string {
+	return money.FormatDecimal(int64(s), SharePercentDecimals)
+}
+
+// MarshalJSON renders the shares as a percentage number
+func (s Shares) MarshalJSON() ([]byte, error) {
+	return []byte(s.Percent()), nil
+}
+
+// UnmarshalJSON reads a percentage given as a number or string
+func (s *Shares) UnmarshalJSON(data []byte) error {
+	v, err := money.UnmarshalDecimalJSON(data, SharePercentDecimals)
+	if err != nil {
+		return err
+	}
+	*s = Shares(v)
+	return nil
+}
+
+// String renders the shares as a percentage with a percent sign
+func (s Shares) String() string {
+	return s.Percent() + "%"
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSharesJSON(t *testing.T) {
+	marshal := []struct {
+		shares Shares
+		want   string
+	}{
+		{shares: 125_000, want: "12.5"},
+		{shares: SharesPerFarm, want: "100"},
+		{shares: 1, want: "0.0001"},
+		{shares: 0, want: "0"},
+	}
+	for _, tt := range marshal {
+		data, err := json.Marshal(tt.shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%d shares marshalled as %s, want %s", int64(tt.shares), data, tt.want)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    Shares
+		wantErr bool
+	}{
+		{name: "number", in: `12.5`, want: 125_000},
+		{name: "string", in: `"33.3333"`, want: 333_333},
+		{name: "whole farm", in: `100`, want: SharesPerFarm},
+		{name: "smallest unit", in: `0.0001`, want: 1},
+		{name: "finer than a unit", in: `0.00001`, wantErr: true},
+		{name: "not a number", in: `"half"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Shares
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("unmarshalled %s as %d, want an error", tt.in, int64(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("unmarshalled %s as %d, want %d", tt.in, int64(got), int64(tt.want))
+			}
+		})
+	}
+}
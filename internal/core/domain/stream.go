@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Stream event types
+const (
+	EventReading = "reading"
+	EventHealth  = "health"
+	EventAlert   = "alert"
+	EventCommand = "command"
+	// EventReset tells a resuming client that events were missed and it
+	// should reload the farm state
+	EventReset = "reset"
+)
+
+// StreamEvent is a change on a farm pushed to live subscribers
+type StreamEvent struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	FarmID    string      `json:"farmId"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// HealthChange is the data of a health event
+type HealthChange struct {
+	Previous *int `json:"previous,omitempty"`
+	Current  int  `json:"current"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FarmToken is the Stellar asset representing a farm's shares. One token
+// is one percentage point of the farm.
+type FarmToken struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FarmID       string             `bson:"farm_id" json:"farmId"`
+	AssetCode    string             `bson:"asset_code" json:"assetCode"`
+	Issuer       string             `bson:"issuer" json:"issuer"`
+	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`
+	LastSyncedAt *time.Time         `bson:"last_synced_at,omitempty" json:"lastSyncedAt,omitempty"`
+}
+
+// TokenMovement is tokens paid to or clawed back from an address to match
+// its recorded shares
+type TokenMovement struct {
+	Address string `json:"address"`
+	Shares  Shares `json:"shareSize"`
+}
+
+// TokenSyncReport is the outcome of bringing token balances in line with a
+// farm's owners
+type TokenSyncReport struct {
+	FarmID    string          `json:"farmId"`
+	AssetCode string          `json:"assetCode"`
+	Issued    []TokenMovement `json:"issued"`
+	Clawed    []TokenMovement `json:"clawedBack"`
+	// AwaitingTrustline lists owners who must trust the asset before their
+	// tokens can be issued
+	AwaitingTrustline []string  `json:"awaitingTrustline"`
+	Transactions      []string  `json:"transactions"` // hashes of the submitted transactions
+	SyncedAt          time.Time `json:"syncedAt"`
+}
@@ -13,4 +13,7 @@ type OrdinaryUser struct {
 	GoogleID  string             `bson:"google_id"` // Google unique ID
 	CreatedAt time.Time          `bson:"created_at"`
 	UserType  string             `bson:"user_type"` // "technician" or "ordinary_user"
+	// CreatedBy is the Stellar account that signed the user up, empty for
+	// users who signed up otherwise
+	CreatedBy string `bson:"created_by,omitempty"`
 }
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Wallet is a Stellar account a user has proven control of. Owner entries
+// can only be registered to bound wallets.
+type Wallet struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	AccountID string             `bson:"account_id" json:"accountId"`
+	BoundAt   time.Time          `bson:"bound_at" json:"boundAt"`
+}
+
+// WalletChallenge is the message a user signs with a wallet's key to bind
+// it to their account
+type WalletChallenge struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	AccountID string             `bson:"account_id" json:"accountId"`
+	Message   string             `bson:"message" json:"message"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expiresAt"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// WhatIfScenario describes hypothetical conditions for a farm from now on.
+// Setpoints hold metrics at a value for the whole projection; readings
+// change metrics at their timestamps, each value holding until the next
+// reading that carries the metric. Metrics neither sets keep their latest
+// measured value.
+type WhatIfScenario struct {
+	Setpoints map[string]float64 `json:"setpoints,omitempty"`
+	Readings  []IoTReading       `json:"readings,omitempty"`
+	// HorizonDays limits the projection, which otherwise runs until the
+	// projected harvest
+	HorizonDays int `json:"horizonDays,omitempty"`
+}
+
+// HealthPoint is the projected state of a crop at the end of a day
+type HealthPoint struct {
+	Time       time.Time `json:"time"`
+	Health     int       `json:"health"`
+	DegreeDays float64   `json:"degreeDays"` // accumulated since planting
+}
+
+// Projection is how a crop develops under one set of conditions
+type Projection struct {
+	Health        []HealthPoint `json:"health"`
+	AverageHealth float64       `json:"averageHealth"`
+	HarvestDate   *time.Time    `json:"harvestDate,omitempty"` // nil when not reached within the horizon
+	ExpectedYield float64       `json:"expectedYield"`         // in kgs
+}
+
+// WhatIfResult compares a scenario with the farm's current baseline
+type WhatIfResult struct {
+	FarmID             string     `json:"farmId"`
+	DegreeDaysToDate   float64    `json:"degreeDaysToDate"`
+	RequiredDegreeDays float64    `json:"requiredDegreeDays"`
+	Baseline           Projection `json:"baseline"`
+	Scenario           Projection `json:"scenario"`
+	HarvestShiftDays   *float64   `json:"harvestShiftDays,omitempty"` // positive when the scenario harvests later
+	YieldChange        float64    `json:"yieldChange"`                // in kgs
+}
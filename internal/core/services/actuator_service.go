@@ -0,0 +1,323 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultCommandTTL is how long a command waits for its device by default
+	defaultCommandTTL = 5 * time.Minute
+	// interlockWindow is how much command history interlocks get to see
+	interlockWindow = 24 * time.Hour
+)
+
+// Interlock vetoes commands that would be unsafe for an output. recent holds
+// the commands for the same output over the last day, oldest first.
+type Interlock interface {
+	Check(output domain.ActuatorOutput, command *domain.ActuatorCommand, recent []domain.ActuatorCommand) error
+}
+
+// InterlockFunc adapts a function to the Interlock interface
+type InterlockFunc func(output domain.ActuatorOutput, command *domain.ActuatorCommand, recent []domain.ActuatorCommand) error
+
+// Check calls f
+func (f InterlockFunc) Check(output domain.ActuatorOutput, command *domain.ActuatorCommand, recent []domain.ActuatorCommand) error {
+	return f(output, command, recent)
+}
+
+// ActuatorService queues commands for device outputs and tracks their execution
+type ActuatorService struct {
+	db         ports.MongoDB
+	events     EventPublisher
+	interlocks []Interlock
+
+	// mu serialises enqueueing so interlocks see every earlier command
+	mu sync.Mutex
+}
+
+// NewActuatorService creates a new instance of the actuator service with the
+// built-in runtime, rest time and value range interlocks. Command changes are
+// published to events when it is not nil.
+func NewActuatorService(db ports.MongoDB, events EventPublisher) *ActuatorService {
+	return &ActuatorService{
+		db:     db,
+		events: events,
+		interlocks: []Interlock{
+			InterlockFunc(maxRuntimeInterlock),
+			InterlockFunc(minOffInterlock),
+			InterlockFunc(valueRangeInterlock),
+		},
+	}
+}
+
+// AddInterlock registers an additional safety check for every command
+func (s *ActuatorService) AddInterlock(interlock Interlock) {
+	s.interlocks = append(s.interlocks, interlock)
+}
+
+// Enqueue validates a command against the device's outputs and interlocks and
+// queues it until ttl passes. Commands still pending for the same output are
+// superseded by it.
+func (s *ActuatorService) Enqueue(farmID string, command domain.ActuatorCommand, ttl time.Duration) (*domain.ActuatorCommand, error) {
+	device, err := s.db.RetrieveDevice(command.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.FarmID != farmID {
+		return nil, errors.New("device does not belong to this farm")
+	}
+	output, ok := device.Output(command.Output)
+	if !ok {
+		return nil, fmt.Errorf("device has no output %q", command.Output)
+	}
+
+	switch command.Action {
+	case domain.ActionOn, domain.ActionOff, domain.ActionSet:
+	case domain.ActionDose:
+		if output.Kind != domain.OutputDoser && output.Kind != domain.OutputPump {
+			return nil, fmt.Errorf("a %s output cannot dose", output.Kind)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported action %q", command.Action)
+	}
+	if command.DurationSeconds < 0 {
+		return nil, errors.New("duration cannot be negative")
+	}
+	if ttl <= 0 {
+		ttl = defaultCommandTTL
+	}
+	if command.Source == "" {
+		command.Source = domain.SourceManual
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	recent, err := s.db.RetrieveCommands(&domain.CommandFilters{
+		DeviceID: command.DeviceID,
+		Output:   command.Output,
+		From:     now.Add(-interlockWindow),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, interlock := range s.interlocks {
+		if err := interlock.Check(output, &command, recent); err != nil {
+			return nil, err
+		}
+	}
+
+	command.ID = primitive.NilObjectID
+	command.FarmID = farmID
+	command.Status = domain.CommandQueued
+	command.Result = ""
+	command.CreatedAt = now
+	command.ExpiresAt = now.Add(ttl)
+	command.DeliveredAt = nil
+	command.CompletedAt = nil
+	if err := s.db.SaveCommand(&command); err != nil {
+		return nil, err
+	}
+	s.publish(&command)
+
+	for i := range recent {
+		previous := &recent[i]
+		if !previous.Pending() {
+			continue
+		}
+		previous.Status = domain.CommandCancelled
+		previous.Result = "superseded by " + command.ID.Hex()
+		previous.CompletedAt = &now
+		if err := s.db.UpdateCommand(previous); err != nil {
+			return nil, err
+		}
+		s.publish(previous)
+	}
+
+	return &command, nil
+}
+
+// Pending hands the device its outstanding commands, marking them delivered.
+// Commands delivered earlier but not acknowledged are handed out again until
+// they expire, so devices must treat the command ID as an idempotency key.
+func (s *ActuatorService) Pending(deviceID string) ([]domain.ActuatorCommand, error) {
+	commands, err := s.db.RetrieveCommands(&domain.CommandFilters{
+		DeviceID: deviceID,
+		Statuses: []string{domain.CommandQueued, domain.CommandDelivered},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pending := make([]domain.ActuatorCommand, 0, len(commands))
+	for i := range commands {
+		command := &commands[i]
+		switch {
+		case now.After(command.ExpiresAt):
+			command.Status = domain.CommandExpired
+			command.CompletedAt = &now
+		case command.Status == domain.CommandQueued:
+			command.Status = domain.CommandDelivered
+			command.DeliveredAt = &now
+		default:
+			pending = append(pending, *command)
+			continue
+		}
+
+		if err := s.db.UpdateCommand(command); err != nil {
+			return nil, err
+		}
+		s.publish(command)
+		if command.Status == domain.CommandDelivered {
+			pending = append(pending, *command)
+		}
+	}
+	return pending, nil
+}
+
+// Acknowledge records the outcome reported by the device for a command.
+// Acknowledgements arriving after the command expired are rejected; the
+// command is marked expired with the late outcome kept in its result.
+func (s *ActuatorService) Acknowledge(id string, executed bool, result string) (*domain.ActuatorCommand, error) {
+	command, err := s.db.RetrieveCommand(id)
+	if err != nil {
+		return nil, err
+	}
+	if !command.Pending() {
+		return nil, fmt.Errorf("command is already %s", command.Status)
+	}
+
+	now := time.Now()
+	outcome := domain.CommandFailed
+	if executed {
+		outcome = domain.CommandExecuted
+	}
+	if now.After(command.ExpiresAt) {
+		command.Status = domain.CommandExpired
+		command.Result = fmt.Sprintf("%s after expiry: %s", outcome, result)
+		command.CompletedAt = &now
+		if err := s.db.UpdateCommand(command); err != nil {
+			return nil, err
+		}
+		s.publish(command)
+		return nil, fmt.Errorf("command expired at %s", command.ExpiresAt.Format(time.RFC3339))
+	}
+
+	command.Status = outcome
+	command.Result = result
+	command.CompletedAt = &now
+	if command.DeliveredAt == nil {
+		command.DeliveredAt = &now
+	}
+
+	if err := s.db.UpdateCommand(command); err != nil {
+		return nil, err
+	}
+	s.publish(command)
+	return command, nil
+}
+
+// Cancel withdraws a command the device has not acknowledged yet
+func (s *ActuatorService) Cancel(id, by string) (*domain.ActuatorCommand, error) {
+	command, err := s.db.RetrieveCommand(id)
+	if err != nil {
+		return nil, err
+	}
+	if !command.Pending() {
+		return nil, fmt.Errorf("command is already %s", command.Status)
+	}
+
+	now := time.Now()
+	command.Status = domain.CommandCancelled
+	command.Result = "cancelled by " + by
+	command.CompletedAt = &now
+
+	if err := s.db.UpdateCommand(command); err != nil {
+		return nil, err
+	}
+	s.publish(command)
+	return command, nil
+}
+
+// History retrieves the commands issued for a farm in [from, to)
+func (s *ActuatorService) History(farmID string, from, to time.Time) ([]domain.ActuatorCommand, error) {
+	commands, err := s.db.RetrieveCommands(&domain.CommandFilters{FarmID: farmID, From: from, To: to})
+	if err != nil {
+		return []domain.ActuatorCommand{}, err
+	}
+	return commands, nil
+}
+
+// publish sends a command change to live subscribers, devices included
+func (s *ActuatorService) publish(command *domain.ActuatorCommand) {
+	if s.events != nil {
+		s.events.Publish(command.FarmID, domain.EventCommand, *command)
+	}
+}
+
+// maxRuntimeInterlock caps how long an output may run in one go. Open-ended
+// runs of a limited output are given the maximum runtime so they always stop.
+func maxRuntimeInterlock(output domain.ActuatorOutput, command *domain.ActuatorCommand, recent []domain.ActuatorCommand) error {
+	if command.Action != domain.ActionOn || output.MaxRuntimeSeconds == 0 {
+		return nil
+	}
+	if command.DurationSeconds == 0 {
+		command.DurationSeconds = output.MaxRuntimeSeconds
+	}
+	if command.DurationSeconds > output.MaxRuntimeSeconds {
+		return fmt.Errorf("%s may run for at most %d seconds", output.Key, output.MaxRuntimeSeconds)
+	}
+	return nil
+}
+
+// minOffInterlock enforces a rest time between two runs of an output
+func minOffInterlock(output domain.ActuatorOutput, command *domain.ActuatorCommand, recent []domain.ActuatorCommand) error {
+	if output.MinOffSeconds == 0 || (command.Action != domain.ActionOn && command.Action != domain.ActionDose) {
+		return nil
+	}
+
+	var lastEnd time.Time
+	for _, previous := range recent {
+		if previous.Status != domain.CommandExecuted || previous.CompletedAt == nil {
+			continue
+		}
+		switch previous.Action {
+		case domain.ActionOn, domain.ActionDose:
+			lastEnd = previous.CompletedAt.Add(time.Duration(previous.DurationSeconds) * time.Second)
+		case domain.ActionOff:
+			if previous.CompletedAt.Before(lastEnd) {
+				lastEnd = *previous.CompletedAt
+			}
+		}
+	}
+
+	if rest := time.Since(lastEnd); rest < time.Duration(output.MinOffSeconds)*time.Second {
+		return fmt.Errorf("%s must rest %d seconds between runs", output.Key, output.MinOffSeconds)
+	}
+	return nil
+}
+
+// valueRangeInterlock keeps set levels and dose quantities within the
+// output's limits
+func valueRangeInterlock(output domain.ActuatorOutput, command *domain.ActuatorCommand, recent []domain.ActuatorCommand) error {
+	if command.Action != domain.ActionSet && command.Action != domain.ActionDose {
+		return nil
+	}
+	if command.Action == domain.ActionDose && command.Value <= 0 {
+		return errors.New("dose must be positive")
+	}
+	if command.Value < output.MinValue || (output.MaxValue > 0 && command.Value > output.MaxValue) {
+		return fmt.Errorf("%s accepts values between %g and %g %s", output.Key, output.MinValue, output.MaxValue, output.Unit)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDevice registers a device on farm-1 with a limited pump and a doser
+// and returns a simulated controller for it
+func newTestDevice(t *testing.T) (*ActuatorService, *SimulatedDevice, string) {
+	t.Helper()
+	db := newMemoryDB()
+	device := &domain.Device{
+		FarmID: "farm-1",
+		Name:   "controller",
+		Outputs: []domain.ActuatorOutput{
+			{Key: "pump", Kind: domain.OutputPump, MaxRuntimeSeconds: 600, MinOffSeconds: 300},
+			{Key: "doser", Kind: domain.OutputDoser, Unit: "mL", MinValue: 1, MaxValue: 50},
+			{Key: "light", Kind: domain.OutputLight, Unit: "%", MaxValue: 100},
+		},
+	}
+	if err := db.SaveDevice(device); err != nil {
+		t.Fatal(err)
+	}
+	actuators := NewActuatorService(db, nil)
+	return actuators, NewSimulatedDevice(actuators, device.ID.Hex()), device.ID.Hex()
+}
+
+func TestActuatorMaxRuntime(t *testing.T) {
+	actuators, device, deviceID := newTestDevice(t)
+
+	_, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "pump", Action: domain.ActionOn, DurationSeconds: 601}, 0)
+	if err == nil || !strings.Contains(err.Error(), "at most 600 seconds") {
+		t.Fatalf("over-long run: got %v", err)
+	}
+
+	// Open-ended runs are capped at the maximum runtime
+	command, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "pump", Action: domain.ActionOn}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if command.DurationSeconds != 600 {
+		t.Fatalf("duration = %d, want 600", command.DurationSeconds)
+	}
+
+	now := time.Now()
+	acks, err := device.Poll(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acks) != 1 || acks[0].Status != domain.CommandExecuted {
+		t.Fatalf("acknowledged %+v", acks)
+	}
+	if state := device.State("pump", now.Add(599*time.Second)); !state.On {
+		t.Fatal("pump stopped before its runtime ended")
+	}
+	if state := device.State("pump", now.Add(600*time.Second)); state.On {
+		t.Fatal("pump still running after its maximum runtime")
+	}
+}
+
+func TestActuatorMinOff(t *testing.T) {
+	actuators, device, deviceID := newTestDevice(t)
+
+	if _, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "pump", Action: domain.ActionOn, DurationSeconds: 60}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Queued commands do not count as runs, the next one supersedes it
+	if _, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "pump", Action: domain.ActionOn, DurationSeconds: 60}, 0); err != nil {
+		t.Fatalf("rest time applied to an unexecuted command: %v", err)
+	}
+	if _, err := device.Poll(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "pump", Action: domain.ActionOn, DurationSeconds: 60}, 0)
+	if err == nil || !strings.Contains(err.Error(), "must rest 300 seconds") {
+		t.Fatalf("run during rest time: got %v", err)
+	}
+
+	history, err := actuators.History("farm-1", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]int{}
+	for _, command := range history {
+		statuses[command.Status]++
+	}
+	if statuses[domain.CommandCancelled] != 1 || statuses[domain.CommandExecuted] != 1 {
+		t.Fatalf("history statuses = %v", statuses)
+	}
+}
+
+func TestActuatorValueRange(t *testing.T) {
+	actuators, device, deviceID := newTestDevice(t)
+
+	for _, command := range []domain.ActuatorCommand{
+		{DeviceID: deviceID, Output: "doser", Action: domain.ActionDose, Value: 0},
+		{DeviceID: deviceID, Output: "doser", Action: domain.ActionDose, Value: 51},
+		{DeviceID: deviceID, Output: "light", Action: domain.ActionSet, Value: 120},
+		{DeviceID: deviceID, Output: "light", Action: domain.ActionDose, Value: 5},
+	} {
+		if _, err := actuators.Enqueue("farm-1", command, 0); err == nil {
+			t.Fatalf("%s %s %g accepted", command.Action, command.Output, command.Value)
+		}
+	}
+
+	for _, command := range []domain.ActuatorCommand{
+		{DeviceID: deviceID, Output: "doser", Action: domain.ActionDose, Value: 20},
+		{DeviceID: deviceID, Output: "light", Action: domain.ActionSet, Value: 75},
+	} {
+		if _, err := actuators.Enqueue("farm-1", command, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	if _, err := device.Poll(now); err != nil {
+		t.Fatal(err)
+	}
+	if state := device.State("doser", now); state.Value != 20 {
+		t.Fatalf("dosed %g mL, want 20", state.Value)
+	}
+	if state := device.State("light", now); !state.On || state.Value != 75 {
+		t.Fatalf("light = %+v", state)
+	}
+}
+
+func TestActuatorExpiry(t *testing.T) {
+	actuators, device, deviceID := newTestDevice(t)
+
+	command, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "light", Action: domain.ActionSet, Value: 50}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	acks, err := device.Poll(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acks) != 0 {
+		t.Fatalf("expired command delivered: %+v", acks)
+	}
+	if state := device.State("light", time.Now()); state.On {
+		t.Fatal("expired command was applied")
+	}
+	if _, err := actuators.Acknowledge(command.ID.Hex(), true, "ok"); err == nil || !strings.Contains(err.Error(), domain.CommandExpired) {
+		t.Fatalf("acknowledging an expired command: got %v", err)
+	}
+}
+
+func TestActuatorDeliveredExpiry(t *testing.T) {
+	actuators, _, deviceID := newTestDevice(t)
+
+	unacknowledged, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "light", Action: domain.ActionSet, Value: 50}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := actuators.Pending(deviceID); err != nil || len(pending) != 1 {
+		t.Fatalf("pending = %+v, %v", pending, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// A delivered command the device never acknowledged is not handed out again
+	pending, err := actuators.Pending(deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expired delivered command handed out again: %+v", pending)
+	}
+	if stored, _ := actuators.db.RetrieveCommand(unacknowledged.ID.Hex()); stored.Status != domain.CommandExpired {
+		t.Errorf("unacknowledged command is %s, want expired", stored.Status)
+	}
+
+	late, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "light", Action: domain.ActionSet, Value: 60}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := actuators.Pending(deviceID); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := actuators.Acknowledge(late.ID.Hex(), true, "ok"); err == nil {
+		t.Fatal("late acknowledgement accepted")
+	}
+	stored, _ := actuators.db.RetrieveCommand(late.ID.Hex())
+	if stored.Status != domain.CommandExpired || stored.Result != "executed after expiry: ok" {
+		t.Errorf("late acknowledged command is %s %q", stored.Status, stored.Result)
+	}
+}
+
+func TestActuatorAcknowledge(t *testing.T) {
+	actuators, device, deviceID := newTestDevice(t)
+	device.FailOutput("light", true)
+
+	command, err := actuators.Enqueue("farm-1", domain.ActuatorCommand{DeviceID: deviceID, Output: "light", Action: domain.ActionSet, Value: 50}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Delivered but unacknowledged commands are handed out again
+	pending, err := actuators.Pending(deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Status != domain.CommandDelivered {
+		t.Fatalf("pending = %+v", pending)
+	}
+	acks, err := device.Poll(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acks) != 1 || acks[0].ID != command.ID {
+		t.Fatalf("redelivery acknowledged %+v", acks)
+	}
+	if acks[0].Status != domain.CommandFailed || acks[0].Result != "simulated fault" {
+		t.Fatalf("failed command recorded as %s %q", acks[0].Status, acks[0].Result)
+	}
+	if acks[0].DeliveredAt == nil || acks[0].CompletedAt == nil {
+		t.Fatal("acknowledged command missing delivery or completion time")
+	}
+
+	if _, err := actuators.Acknowledge(command.ID.Hex(), true, "ok"); err == nil {
+		t.Fatal("command acknowledged twice")
+	}
+	if _, err := actuators.Cancel(command.ID.Hex(), "operator"); err == nil {
+		t.Fatal("completed command cancelled")
+	}
+}
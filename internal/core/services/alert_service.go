@@ -0,0 +1,316 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ruleState tracks one alert rule on one farm between readings
+type ruleState struct {
+	breachSince time.Time     // first reading of the current breach
+	alert       *domain.Alert // open or acknowledged alert, if any
+}
+
+// AlertService evaluates alert rules against incoming readings and manages
+// the lifecycle of the alerts they raise
+type AlertService struct {
+	db      ports.MongoDB
+	metrics *MetricRegistry
+	events  EventPublisher
+
+	mu     sync.Mutex
+	rules  []domain.AlertRule
+	states map[string]*ruleState
+}
+
+// NewAlertService creates a new instance of the alert service. Alert changes
+// are published to events when it is not nil.
+func NewAlertService(db ports.MongoDB, metrics *MetricRegistry, events EventPublisher) *AlertService {
+	return &AlertService{
+		db:      db,
+		metrics: metrics,
+		events:  events,
+		states:  make(map[string]*ruleState),
+	}
+}
+
+// Load reads the alert rules and the alerts that are still active so rule
+// evaluation carries on where it stopped
+func (s *AlertService) Load() error {
+	rules, err := s.db.RetrieveAlertRules()
+	if err != nil {
+		return err
+	}
+
+	var active []domain.Alert
+	for _, state := range []string{domain.AlertOpen, domain.AlertAcknowledged} {
+		alerts, err := s.db.RetrieveAlerts(&domain.AlertFilters{State: state})
+		if err != nil {
+			return err
+		}
+		active = append(active, alerts...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+	for i := range active {
+		alert := active[i]
+		if alert.Kind != domain.AlertKindRule {
+			continue
+		}
+		s.state(alert.RuleID, alert.FarmID).alert = &alert
+	}
+	return nil
+}
+
+// CreateRule validates and stores a new alert rule
+func (s *AlertService) CreateRule(rule domain.AlertRule) (*domain.AlertRule, error) {
+	if rule.Metric != domain.HealthMetric {
+		if _, ok := s.metrics.Get(rule.Metric); !ok {
+			return nil, fmt.Errorf("unregistered metric %q", rule.Metric)
+		}
+	}
+	switch rule.Operator {
+	case ">", ">=", "<", "<=":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", rule.Operator)
+	}
+	if rule.ForSeconds < 0 || rule.Hysteresis < 0 {
+		return nil, errors.New("duration and hysteresis cannot be negative")
+	}
+	if rule.Severity == "" {
+		rule.Severity = domain.SeverityWarning
+	}
+	if rule.Name == "" {
+		rule.Name = fmt.Sprintf("%s %s %g", rule.Metric, rule.Operator, rule.Threshold)
+	}
+	rule.CreatedAt = time.Now()
+
+	if err := s.db.SaveAlertRule(&rule); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.rules = append(s.rules, rule)
+	s.mu.Unlock()
+	return &rule, nil
+}
+
+// ListRules returns every alert rule
+func (s *AlertService) ListRules() []domain.AlertRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.AlertRule(nil), s.rules...)
+}
+
+// DeleteRule removes an alert rule. Alerts it already raised stay as they are.
+func (s *AlertService) DeleteRule(id string) error {
+	if _, err := s.db.RemoveAlertRule(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, rule := range s.rules {
+		if rule.ID.Hex() == id {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			break
+		}
+	}
+	for key := range s.states {
+		if strings.HasPrefix(key, id+"/") {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
+
+// ReadingAccepted evaluates every rule covering the farm against the reading
+func (s *AlertService) ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rule := range s.rules {
+		if !rule.AppliesTo(farmID, farm.CropType) {
+			continue
+		}
+
+		value := float64(reading.CropHealth)
+		if rule.Metric != domain.HealthMetric {
+			var ok bool
+			if value, ok = reading.Value(rule.Metric); !ok {
+				continue
+			}
+		}
+
+		if err := s.evaluate(rule, farmID, value, reading.Timestamp); err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to evaluate alert rule %s on farm %s: %v", rule.ID.Hex(), farmID, err))
+		}
+	}
+}
+
+// evaluate applies one value to a rule. A breach must last ForSeconds before
+// an alert opens (debounce), and an alert only resolves once the value has
+// cleared the threshold by the hysteresis margin.
+func (s *AlertService) evaluate(rule domain.AlertRule, farmID string, value float64, at time.Time) error {
+	state := s.state(rule.ID.Hex(), farmID)
+
+	if state.alert != nil {
+		state.alert.LastValue = value
+		if !rule.Cleared(value) {
+			return nil
+		}
+
+		state.alert.State = domain.AlertResolved
+		state.alert.ResolvedAt = &at
+		alert := state.alert
+		state.alert = nil
+		state.breachSince = time.Time{}
+		if err := s.db.UpdateAlert(alert); err != nil {
+			return err
+		}
+		s.publish(alert)
+		return nil
+	}
+
+	if !rule.Breached(value) {
+		state.breachSince = time.Time{}
+		return nil
+	}
+	if state.breachSince.IsZero() {
+		state.breachSince = at
+	}
+	if at.Sub(state.breachSince) < time.Duration(rule.ForSeconds)*time.Second {
+		return nil
+	}
+
+	alert := &domain.Alert{
+		FarmID:      farmID,
+		RuleID:      rule.ID.Hex(),
+		Kind:        domain.AlertKindRule,
+		Metric:      rule.Metric,
+		Severity:    rule.Severity,
+		State:       domain.AlertOpen,
+		Message:     fmt.Sprintf("%s: %s is %g (%s %g)", rule.Name, rule.Metric, value, rule.Operator, rule.Threshold),
+		Value:       value,
+		LastValue:   value,
+		TriggeredAt: at,
+	}
+	if err := s.db.SaveAlert(alert); err != nil {
+		return err
+	}
+	state.alert = alert
+	s.publish(alert)
+	return nil
+}
+
+// state returns the tracking state of a rule on a farm, creating it if needed.
+// Callers must hold s.mu.
+func (s *AlertService) state(ruleID, farmID string) *ruleState {
+	key := ruleID + "/" + farmID
+	state, ok := s.states[key]
+	if !ok {
+		state = &ruleState{}
+		s.states[key] = state
+	}
+	return state
+}
+
+// RaiseAlert stores an alert raised outside of rule evaluation, such as a
+// farm that stopped reporting
+func (s *AlertService) RaiseAlert(alert *domain.Alert) error {
+	alert.State = domain.AlertOpen
+	if alert.TriggeredAt.IsZero() {
+		alert.TriggeredAt = time.Now()
+	}
+	if err := s.db.SaveAlert(alert); err != nil {
+		return err
+	}
+	s.publish(alert)
+	return nil
+}
+
+// ListAlerts retrieves alerts matching the filters
+func (s *AlertService) ListAlerts(filters *domain.AlertFilters) ([]domain.Alert, error) {
+	alerts, err := s.db.RetrieveAlerts(filters)
+	if err != nil {
+		return []domain.Alert{}, err
+	}
+	return alerts, nil
+}
+
+// Acknowledge marks an open alert as seen by someone working on it
+func (s *AlertService) Acknowledge(id, by string) (*domain.Alert, error) {
+	return s.transition(id, func(alert *domain.Alert) error {
+		if alert.State != domain.AlertOpen {
+			return fmt.Errorf("cannot acknowledge a %s alert", alert.State)
+		}
+		now := time.Now()
+		alert.State = domain.AlertAcknowledged
+		alert.AcknowledgedAt = &now
+		alert.AcknowledgedBy = by
+		return nil
+	})
+}
+
+// Resolve closes an alert by hand
+func (s *AlertService) Resolve(id string) (*domain.Alert, error) {
+	return s.transition(id, func(alert *domain.Alert) error {
+		if alert.State == domain.AlertResolved {
+			return errors.New("alert is already resolved")
+		}
+		now := time.Now()
+		alert.State = domain.AlertResolved
+		alert.ResolvedAt = &now
+		return nil
+	})
+}
+
+// transition applies a state change to a stored alert, keeping the tracked
+// rule state in sync
+func (s *AlertService) transition(id string, apply func(alert *domain.Alert) error) (*domain.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alert, err := s.db.RetrieveAlert(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on the tracked copy so evaluation sees the change
+	var state *ruleState
+	if alert.Kind == domain.AlertKindRule {
+		state = s.state(alert.RuleID, alert.FarmID)
+		if state.alert != nil && state.alert.ID == alert.ID {
+			alert = state.alert
+		}
+	}
+
+	if err := apply(alert); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateAlert(alert); err != nil {
+		return nil, err
+	}
+
+	if state != nil && alert.State == domain.AlertResolved && state.alert == alert {
+		state.alert = nil
+		state.breachSince = time.Time{}
+	}
+	s.publish(alert)
+	return alert, nil
+}
+
+// publish sends an alert change to live subscribers
+func (s *AlertService) publish(alert *domain.Alert) {
+	if s.events != nil {
+		s.events.Publish(alert.FarmID, domain.EventAlert, *alert)
+	}
+}
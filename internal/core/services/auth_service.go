@@ -0,0 +1,119 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/internal/stellar"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+const (
+	// authChallengeTTL is how long a login challenge can be signed and
+	// returned, the five minutes SEP-10 recommends
+	authChallengeTTL = 5 * time.Minute
+	// authSessionTTL is how long a login lasts
+	authSessionTTL = 24 * time.Hour
+)
+
+// AuthService logs investors in with their Stellar wallet, following SEP-10
+// web authentication. The server signs a challenge transaction with a
+// random nonce and short time bounds, which is never submitted; the client
+// signing it too proves control of the account.
+type AuthService struct {
+	db         ports.MongoDB
+	signer     *keypair.Full
+	passphrase string
+	domain     string
+}
+
+// NewAuthService creates a new instance of the auth service. Challenges are
+// signed by signer for the network of passphrase and the given home domain.
+func NewAuthService(db ports.MongoDB, signer *keypair.Full, passphrase, domain string) *AuthService {
+	return &AuthService{db: db, signer: signer, passphrase: passphrase, domain: domain}
+}
+
+// Challenge builds the challenge transaction for an account, for the client
+// to sign. Muxed addresses log in as their underlying account.
+func (s *AuthService) Challenge(address string) (string, error) {
+	if _, err := stellar.AccountID(address); err != nil {
+		return "", err
+	}
+	tx, err := txnbuild.BuildChallengeTx(s.signer.Seed(), address, s.domain, s.domain, s.passphrase, authChallengeTTL, nil)
+	if err != nil {
+		return "", err
+	}
+	return tx.Base64()
+}
+
+// Login verifies a signed challenge and opens a session for its account,
+// returning the session token. The challenge must be signed by the server
+// and the account's master key, and still be within its time bounds.
+func (s *AuthService) Login(envelope string) (string, *domain.AuthSession, error) {
+	tx, address, _, _, err := txnbuild.ReadChallengeTx(envelope, s.signer.Address(), s.passphrase, s.domain, []string{s.domain})
+	if err != nil {
+		return "", nil, err
+	}
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := txnbuild.VerifyChallengeTxSigners(envelope, s.signer.Address(), s.passphrase, s.domain, []string{s.domain}, accountID); err != nil {
+		return "", nil, err
+	}
+	challengeHash, err := tx.HashHex(s.passphrase)
+	if err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	token := hex.EncodeToString(secret)
+
+	now := time.Now()
+	session := &domain.AuthSession{
+		TokenHash:     hashToken(token),
+		ChallengeHash: challengeHash,
+		AccountID:     accountID,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(authSessionTTL),
+	}
+	if wallet, err := s.db.RetrieveWallet(accountID); err == nil {
+		session.UserID = wallet.UserID
+	}
+	if err := s.db.SaveAuthSession(session); err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+// Session returns the live session of a token
+func (s *AuthService) Session(token string) (*domain.AuthSession, error) {
+	session, err := s.db.RetrieveAuthSession(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	// Expired sessions are removed in the background, not necessarily yet
+	if time.Now().After(session.ExpiresAt) {
+		return nil, errors.New("session has expired")
+	}
+	return session, nil
+}
+
+// Logout ends the session of a token
+func (s *AuthService) Logout(token string) error {
+	return s.db.DeleteAuthSession(hashToken(token))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
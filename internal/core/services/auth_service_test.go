@@ -0,0 +1,144 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/stellar"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// signChallenge fetches a login challenge for address and signs it with key
+func signChallenge(t *testing.T, auth *AuthService, address string, key *keypair.Full) string {
+	t.Helper()
+	envelope, err := auth.Challenge(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.ParseTransaction(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, key); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := tx.Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newTestAuth(db *memoryDB) *AuthService {
+	return NewAuthService(db, keypair.MustRandom(), network.TestNetworkPassphrase, "0xfarms.test")
+}
+
+func TestLogin(t *testing.T) {
+	db := newMemoryDB()
+	auth := newTestAuth(db)
+	investor := keypair.MustRandom()
+
+	envelope := signChallenge(t, auth, investor.Address(), investor)
+	token, session, err := auth.Login(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccountID != investor.Address() || session.UserID != "" {
+		t.Fatalf("session %+v", session)
+	}
+	if session.TokenHash == token {
+		t.Fatal("session token stored in the clear")
+	}
+
+	live, err := auth.Session(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.AccountID != investor.Address() {
+		t.Fatalf("token resolves to %s", live.AccountID)
+	}
+
+	// A challenge logs in once
+	if _, _, err := auth.Login(envelope); err == nil {
+		t.Fatal("challenge replayed")
+	}
+
+	if err := auth.Logout(token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Session(token); err == nil {
+		t.Fatal("session outlived logout")
+	}
+}
+
+func TestLoginBoundWallet(t *testing.T) {
+	db := newMemoryDB()
+	auth := newTestAuth(db)
+	investor := keypair.MustRandom()
+	userID := db.putUser("investor")
+	if err := db.SaveWallet(&domain.Wallet{UserID: userID, AccountID: investor.Address()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Muxed addresses log in as their underlying account
+	muxed, err := xdr.MuxedAccountFromAccountId(investor.Address(), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, session, err := auth.Login(signChallenge(t, auth, muxed.Address(), investor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccountID != investor.Address() || session.UserID != userID {
+		t.Fatalf("session %+v", session)
+	}
+}
+
+func TestLoginRejects(t *testing.T) {
+	db := newMemoryDB()
+	auth := newTestAuth(db)
+	investor, other := keypair.MustRandom(), keypair.MustRandom()
+
+	if _, _, err := auth.Login(signChallenge(t, auth, investor.Address(), other)); err == nil {
+		t.Fatal("challenge signed by another key accepted")
+	}
+
+	// Challenges are only good on the server that issued them
+	elsewhere := newTestAuth(db)
+	if _, _, err := auth.Login(signChallenge(t, elsewhere, investor.Address(), investor)); err == nil {
+		t.Fatal("challenge of another server accepted")
+	}
+
+	unsigned, err := auth.Challenge(investor.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := auth.Login(unsigned); err == nil {
+		t.Fatal("unsigned challenge accepted")
+	}
+
+	if _, err := auth.Challenge("SB" + investor.Address()[2:]); err == nil {
+		t.Fatal("challenge issued for a non-account")
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	db := newMemoryDB()
+	auth := newTestAuth(db)
+	if err := db.SaveAuthSession(&domain.AuthSession{
+		TokenHash: hashToken("expired"),
+		AccountID: keypair.MustRandom().Address(),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Session("expired"); err == nil {
+		t.Fatal("expired session accepted")
+	}
+	if _, err := auth.Session(""); err == nil {
+		t.Fatal("empty token accepted")
+	}
+}
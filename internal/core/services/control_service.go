@@ -0,0 +1,504 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultControlInterval is the shortest time between two actions of a
+	// policy that does not set its own
+	defaultControlInterval = time.Minute
+	// manualHold keeps policies off an output for a while after a technician
+	// commanded it by hand
+	manualHold = 30 * time.Minute
+	// controlMaxReadingAge keeps imported and late readings from driving outputs
+	controlMaxReadingAge = 10 * time.Minute
+)
+
+// controlState tracks one policy between readings
+type controlState struct {
+	integral     float64
+	prevError    float64
+	prevAt       time.Time
+	lastActionAt time.Time
+	running      bool    // the last bang-bang action switched the output on
+	lastValue    float64 // last level set by a PID policy
+}
+
+// ControlService runs closed-loop control policies that keep farm metrics at
+// the targets of their crop specification by commanding actuator outputs
+type ControlService struct {
+	db        ports.MongoDB
+	metrics   *MetricRegistry
+	actuators *ActuatorService
+
+	mu       sync.Mutex
+	policies []domain.ControlPolicy
+	states   map[string]*controlState
+}
+
+// NewControlService creates a new instance of the control service issuing
+// commands through the given actuator service
+func NewControlService(db ports.MongoDB, metrics *MetricRegistry, actuators *ActuatorService) *ControlService {
+	return &ControlService{
+		db:        db,
+		metrics:   metrics,
+		actuators: actuators,
+		states:    make(map[string]*controlState),
+	}
+}
+
+// Load reads the stored control policies
+func (s *ControlService) Load() error {
+	policies, err := s.db.RetrieveControlPolicies("")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = policies
+	return nil
+}
+
+// CreatePolicy validates and stores a new control policy for a farm
+func (s *ControlService) CreatePolicy(farmID string, policy domain.ControlPolicy) (*domain.ControlPolicy, error) {
+	if _, ok := s.metrics.Get(policy.Metric); !ok {
+		return nil, fmt.Errorf("unregistered metric %q", policy.Metric)
+	}
+
+	device, err := s.db.RetrieveDevice(policy.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.FarmID != farmID {
+		return nil, errors.New("device does not belong to this farm")
+	}
+	output, ok := device.Output(policy.Output)
+	if !ok {
+		return nil, fmt.Errorf("device has no output %q", policy.Output)
+	}
+
+	if policy.Direction != domain.DirectionLower && policy.Direction != domain.DirectionRaise {
+		return nil, fmt.Errorf("unsupported direction %q", policy.Direction)
+	}
+	if policy.Actuation == "" {
+		policy.Actuation = domain.ActuationRun
+		if output.Kind == domain.OutputDoser {
+			policy.Actuation = domain.ActuationDose
+		}
+	}
+	switch policy.Actuation {
+	case domain.ActuationRun, domain.ActuationSet:
+	case domain.ActuationDose:
+		if output.Kind != domain.OutputDoser && output.Kind != domain.OutputPump {
+			return nil, fmt.Errorf("a %s output cannot dose", output.Kind)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported actuation %q", policy.Actuation)
+	}
+	if policy.Deadband < 0 || policy.MinIntervalSeconds < 0 {
+		return nil, errors.New("deadband and interval cannot be negative")
+	}
+
+	switch policy.Mode {
+	case domain.ControlBangBang:
+		if policy.Actuation == domain.ActuationRun && policy.RunSeconds <= 0 {
+			return nil, errors.New("bang-bang run policies need a positive run time")
+		}
+		if policy.Actuation == domain.ActuationDose && policy.DoseAmount <= 0 {
+			return nil, errors.New("bang-bang dose policies need a positive dose")
+		}
+		if policy.Actuation == domain.ActuationSet && policy.OutputMax <= policy.OutputMin {
+			return nil, errors.New("bang-bang set policies need an output max above the output min")
+		}
+	case domain.ControlPID:
+		if policy.Kp == 0 && policy.Ki == 0 && policy.Kd == 0 {
+			return nil, errors.New("PID policies need at least one non-zero gain")
+		}
+		if policy.OutputMax <= policy.OutputMin {
+			return nil, errors.New("PID policies need an output max above the output min")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported control mode %q", policy.Mode)
+	}
+
+	if policy.Name == "" {
+		policy.Name = fmt.Sprintf("%s %s %s via %s", policy.Mode, policy.Direction, policy.Metric, policy.Output)
+	}
+	policy.FarmID = farmID
+	policy.OverrideUntil = nil
+	policy.OverriddenBy = ""
+	policy.OverrideReason = ""
+	policy.CreatedAt = time.Now()
+
+	if err := s.db.SaveControlPolicy(&policy); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.policies = append(s.policies, policy)
+	s.mu.Unlock()
+	return &policy, nil
+}
+
+// ListPolicies returns the control policies of a farm
+func (s *ControlService) ListPolicies(farmID string) []domain.ControlPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policies := make([]domain.ControlPolicy, 0)
+	for _, policy := range s.policies {
+		if policy.FarmID == farmID {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// DeletePolicy removes a control policy. Commands it already issued stay queued.
+func (s *ControlService) DeletePolicy(id string) error {
+	if _, err := s.db.RemoveControlPolicy(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, policy := range s.policies {
+		if policy.ID.Hex() == id {
+			s.policies = append(s.policies[:i], s.policies[i+1:]...)
+			break
+		}
+	}
+	delete(s.states, id)
+	return nil
+}
+
+// Override suspends a policy until the given time so a technician can take
+// over its output by hand
+func (s *ControlService) Override(id, by, reason string, until time.Time) (*domain.ControlPolicy, error) {
+	if by == "" {
+		return nil, errors.New("technician is required")
+	}
+	if !until.After(time.Now()) {
+		return nil, errors.New("override must end in the future")
+	}
+
+	return s.updateOverride(id, func(policy *domain.ControlPolicy) *domain.ControlDecision {
+		policy.OverrideUntil = &until
+		policy.OverriddenBy = by
+		policy.OverrideReason = reason
+		return &domain.ControlDecision{
+			Action: domain.ControlOverride,
+			By:     by,
+			Note:   fmt.Sprintf("suspended until %s: %s", until.Format(time.RFC3339), reason),
+		}
+	})
+}
+
+// Resume lifts the override of a policy
+func (s *ControlService) Resume(id, by string) (*domain.ControlPolicy, error) {
+	return s.updateOverride(id, func(policy *domain.ControlPolicy) *domain.ControlDecision {
+		policy.OverrideUntil = nil
+		policy.OverriddenBy = ""
+		policy.OverrideReason = ""
+		return &domain.ControlDecision{Action: domain.ControlResume, By: by}
+	})
+}
+
+// Decisions retrieves the audit trail of a farm's policies in [from, to)
+func (s *ControlService) Decisions(farmID string, from, to time.Time) ([]domain.ControlDecision, error) {
+	decisions, err := s.db.RetrieveControlDecisions(farmID, from, to)
+	if err != nil {
+		return []domain.ControlDecision{}, err
+	}
+	return decisions, nil
+}
+
+// ReadingAccepted runs every policy of the farm against the reading
+func (s *ControlService) ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading) {
+	now := time.Now()
+	if now.Sub(reading.Timestamp) > controlMaxReadingAge {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var spec *domain.CropSpecification
+	for i := range s.policies {
+		policy := &s.policies[i]
+		if policy.FarmID != farmID {
+			continue
+		}
+		state := s.state(policy.ID.Hex())
+		if policy.Overridden(now) {
+			// Start from a clean slate once the technician hands back control
+			*state = controlState{lastActionAt: state.lastActionAt}
+			continue
+		}
+
+		value, ok := reading.Value(policy.Metric)
+		if !ok {
+			continue
+		}
+
+		setpoint := 0.0
+		if policy.Setpoint != nil {
+			setpoint = *policy.Setpoint
+		} else {
+			if spec == nil {
+				cropSpec, err := s.db.GetCropSpecification(farm.CropType)
+				if err != nil {
+					logger.LogWarning(fmt.Sprintf("Failed to load crop specification for farm %s: %v", farmID, err))
+					return
+				}
+				spec = &cropSpec
+			}
+			target, ok := spec.Target(policy.Metric)
+			if !ok {
+				continue
+			}
+			setpoint = target.Optimal
+		}
+
+		decision, next := s.decide(policy, state, value, setpoint, reading.Timestamp, now)
+		if decision == nil {
+			continue
+		}
+		issued, err := s.act(policy, decision, now)
+		if err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to run control policy %s on farm %s: %v", policy.ID.Hex(), farmID, err))
+		}
+		// A command held back or vetoed leaves the output as it was, so the
+		// policy keeps trying on the next reading
+		if issued {
+			*state = next
+		}
+	}
+}
+
+// decide works out the command a policy calls for, if any, and the state
+// the policy is in once that command is issued. The error is signed so that
+// a positive error always asks for the output to run. Only the PID history
+// of readings is updated in place.
+func (s *ControlService) decide(policy *domain.ControlPolicy, state *controlState, value, setpoint float64, at, now time.Time) (*domain.ControlDecision, controlState) {
+	e := value - setpoint
+	if policy.Direction == domain.DirectionRaise {
+		e = setpoint - value
+	}
+
+	decision := &domain.ControlDecision{
+		Metric:   policy.Metric,
+		Value:    value,
+		Setpoint: setpoint,
+		Error:    e,
+	}
+
+	interval := defaultControlInterval
+	if policy.MinIntervalSeconds > 0 {
+		interval = time.Duration(policy.MinIntervalSeconds) * time.Second
+	}
+	rested := now.Sub(state.lastActionAt) >= interval
+
+	if policy.Mode == domain.ControlBangBang {
+		next := *state
+		switch {
+		case e > policy.Deadband && rested:
+			if policy.Actuation == domain.ActuationSet && state.running {
+				return nil, *state
+			}
+			switch policy.Actuation {
+			case domain.ActuationRun:
+				decision.Action = domain.ActionOn
+				decision.DurationSeconds = policy.RunSeconds
+			case domain.ActuationDose:
+				decision.Action = domain.ActionDose
+				decision.CommandValue = policy.DoseAmount
+			case domain.ActuationSet:
+				decision.Action = domain.ActionSet
+				decision.CommandValue = policy.OutputMax
+			}
+			next.running = true
+		case e < -policy.Deadband && state.running:
+			// Dosing is a one-off, there is nothing to switch off
+			next.running = false
+			switch policy.Actuation {
+			case domain.ActuationRun:
+				decision.Action = domain.ActionOff
+			case domain.ActuationSet:
+				decision.Action = domain.ActionSet
+				decision.CommandValue = policy.OutputMin
+			default:
+				return nil, *state
+			}
+		default:
+			return nil, *state
+		}
+		next.lastActionAt = now
+		return decision, next
+	}
+
+	// PID with the deadband zeroing small errors and conditional integration
+	// keeping the integral from winding up while the output is saturated
+	if math.Abs(e) <= policy.Deadband {
+		e = 0
+	}
+	var dt, derivative float64
+	if !state.prevAt.IsZero() && at.After(state.prevAt) {
+		dt = at.Sub(state.prevAt).Seconds()
+		derivative = (e - state.prevError) / dt
+	}
+	integral := state.integral + e*dt
+	u := policy.Kp*e + policy.Ki*integral + policy.Kd*derivative
+	clamped := math.Max(policy.OutputMin, math.Min(policy.OutputMax, u))
+	if clamped == u {
+		state.integral = integral
+	}
+	state.prevError = e
+	state.prevAt = at
+
+	if !rested {
+		return nil, *state
+	}
+	next := *state
+	switch policy.Actuation {
+	case domain.ActuationRun:
+		seconds := int(math.Round(clamped))
+		if seconds < 1 {
+			return nil, *state
+		}
+		decision.Action = domain.ActionOn
+		decision.DurationSeconds = seconds
+	case domain.ActuationDose:
+		if clamped <= 0 {
+			return nil, *state
+		}
+		decision.Action = domain.ActionDose
+		decision.CommandValue = clamped
+	case domain.ActuationSet:
+		if clamped == state.lastValue && !state.lastActionAt.IsZero() {
+			return nil, *state
+		}
+		decision.Action = domain.ActionSet
+		decision.CommandValue = clamped
+		next.lastValue = clamped
+	}
+	next.lastActionAt = now
+	return decision, next
+}
+
+// act issues the decided command, or only logs it for dry-run policies, and
+// records the decision. It reports whether the command was issued, which it
+// is not while the output is under manual control or when an interlock
+// rejects it.
+func (s *ControlService) act(policy *domain.ControlPolicy, decision *domain.ControlDecision, now time.Time) (bool, error) {
+	decision.PolicyID = policy.ID.Hex()
+	decision.FarmID = policy.FarmID
+	decision.DryRun = policy.DryRun
+	decision.CreatedAt = now
+
+	issued := false
+	switch {
+	case policy.DryRun:
+		issued = true
+		logger.LogInfo(fmt.Sprintf("Dry run: policy %s would %s %s on device %s (%s %g, setpoint %g)",
+			policy.Name, decision.Action, policy.Output, policy.DeviceID, policy.Metric, decision.Value, decision.Setpoint))
+	default:
+		held, err := s.manuallyHeld(policy, now)
+		if err != nil {
+			return false, err
+		}
+		if held {
+			decision.Note = "output under manual control"
+			break
+		}
+
+		command, err := s.actuators.Enqueue(policy.FarmID, domain.ActuatorCommand{
+			DeviceID:        policy.DeviceID,
+			Output:          policy.Output,
+			Action:          decision.Action,
+			Value:           decision.CommandValue,
+			DurationSeconds: decision.DurationSeconds,
+			Source:          domain.SourcePolicy,
+			IssuedBy:        policy.ID.Hex(),
+		}, 0)
+		if err != nil {
+			decision.Note = err.Error()
+			break
+		}
+		decision.CommandID = command.ID.Hex()
+		issued = true
+	}
+
+	return issued, s.db.SaveControlDecision(decision)
+}
+
+// manuallyHeld reports whether a technician commanded the policy's output by
+// hand recently enough that the policy should keep off it
+func (s *ControlService) manuallyHeld(policy *domain.ControlPolicy, now time.Time) (bool, error) {
+	commands, err := s.db.RetrieveCommands(&domain.CommandFilters{
+		DeviceID: policy.DeviceID,
+		Output:   policy.Output,
+		From:     now.Add(-manualHold),
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, command := range commands {
+		if command.Source == domain.SourceManual && command.Status != domain.CommandCancelled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// updateOverride applies an override change to a policy and records it
+func (s *ControlService) updateOverride(id string, apply func(policy *domain.ControlPolicy) *domain.ControlDecision) (*domain.ControlPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var policy *domain.ControlPolicy
+	for i := range s.policies {
+		if s.policies[i].ID.Hex() == id {
+			policy = &s.policies[i]
+			break
+		}
+	}
+	if policy == nil {
+		return nil, errors.New("control policy not found")
+	}
+
+	updated := *policy
+	decision := apply(&updated)
+	if err := s.db.UpdateControlPolicy(&updated); err != nil {
+		return nil, err
+	}
+	*policy = updated
+
+	decision.PolicyID = id
+	decision.FarmID = policy.FarmID
+	decision.Metric = policy.Metric
+	decision.CreatedAt = time.Now()
+	if err := s.db.SaveControlDecision(decision); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// state returns the tracking state of a policy, creating it on first use
+func (s *ControlService) state(policyID string) *controlState {
+	state, ok := s.states[policyID]
+	if !ok {
+		state = &controlState{}
+		s.states[policyID] = state
+	}
+	return state
+}
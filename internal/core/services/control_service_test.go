@@ -0,0 +1,49 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestControlRetriesVetoedCommand(t *testing.T) {
+	actuators, _, deviceID := newTestDevice(t)
+	db := actuators.db.(*memoryDB)
+	vetoed := true
+	actuators.AddInterlock(InterlockFunc(func(domain.ActuatorOutput, *domain.ActuatorCommand, []domain.ActuatorCommand) error {
+		if vetoed {
+			return errors.New("reservoir is empty")
+		}
+		return nil
+	}))
+
+	setpoint := 50.0
+	controls := NewControlService(db, nil, actuators)
+	controls.policies = []domain.ControlPolicy{{
+		ID: primitive.NewObjectID(), FarmID: "farm-1", Metric: domain.MetricHumidity, Setpoint: &setpoint,
+		DeviceID: deviceID, Output: "pump", Direction: domain.DirectionRaise,
+		Mode: domain.ControlBangBang, Actuation: domain.ActuationRun, RunSeconds: 60, MinIntervalSeconds: 600,
+	}}
+	farm := &domain.VerticalFarm{ID: "farm-1"}
+
+	controls.ReadingAccepted("farm-1", farm, domain.IoTReading{Humidity: 40, Timestamp: time.Now()})
+	if len(db.decisions) != 1 || db.decisions[0].CommandID != "" || db.decisions[0].Note != "reservoir is empty" {
+		t.Fatalf("decisions after veto = %+v", db.decisions)
+	}
+
+	// The vetoed run neither started the interval nor marked the output running
+	vetoed = false
+	controls.ReadingAccepted("farm-1", farm, domain.IoTReading{Humidity: 40, Timestamp: time.Now()})
+	if len(db.decisions) != 2 || db.decisions[1].CommandID == "" {
+		t.Fatalf("decisions after the veto lifted = %+v", db.decisions)
+	}
+
+	// The issued run does
+	controls.ReadingAccepted("farm-1", farm, domain.IoTReading{Humidity: 40, Timestamp: time.Now()})
+	if len(db.decisions) != 2 {
+		t.Errorf("policy acted again within its interval: %+v", db.decisions[2:])
+	}
+}
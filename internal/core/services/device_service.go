@@ -0,0 +1,102 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/lorawan"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DeviceService handles the sensor gateways and controllers installed on farms
+type DeviceService struct {
+	db ports.MongoDB
+}
+
+// NewDeviceService creates a new instance of the device service
+func NewDeviceService(db ports.MongoDB) *DeviceService {
+	return &DeviceService{db: db}
+}
+
+// RegisterDevice adds a device to a farm
+func (s *DeviceService) RegisterDevice(farmID string, device domain.Device) (*domain.Device, error) {
+	if device.Name == "" {
+		return nil, errors.New("device name is required")
+	}
+	if device.ReportingIntervalSeconds < 0 {
+		return nil, errors.New("reporting interval cannot be negative")
+	}
+	if err := validateOutputs(device.Outputs); err != nil {
+		return nil, err
+	}
+	if device.DevEUI != "" {
+		eui, err := lorawan.NormalizeEUI(device.DevEUI)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.db.RetrieveDeviceByEUI(eui); err == nil {
+			return nil, fmt.Errorf("a device with EUI %s is already registered", eui)
+		}
+		device.DevEUI = eui
+		if device.Decoder == "" {
+			return nil, errors.New("LoRaWAN devices need a payload decoder")
+		}
+	}
+	if device.Decoder != "" {
+		if _, ok := lorawan.Lookup(device.Decoder); !ok {
+			return nil, fmt.Errorf("unknown payload decoder %q", device.Decoder)
+		}
+	}
+	if _, err := s.db.GetFarm(farmID); err != nil {
+		return nil, err
+	}
+
+	device.FarmID = farmID
+	device.CreatedAt = time.Now()
+	if err := s.db.SaveDevice(&device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetDevice retrieves a device by ID
+func (s *DeviceService) GetDevice(id string) (*domain.Device, error) {
+	return s.db.RetrieveDevice(id)
+}
+
+// ListDevices retrieves the devices of a farm
+func (s *DeviceService) ListDevices(farmID string) ([]domain.Device, error) {
+	devices, err := s.db.RetrieveDevices(farmID)
+	if err != nil {
+		return []domain.Device{}, err
+	}
+	return devices, nil
+}
+
+// validateOutputs checks the controllable outputs a device declares
+func validateOutputs(outputs []domain.ActuatorOutput) error {
+	seen := make(map[string]bool, len(outputs))
+	for _, o := range outputs {
+		if o.Key == "" {
+			return errors.New("output key is required")
+		}
+		if seen[o.Key] {
+			return fmt.Errorf("duplicate output %q", o.Key)
+		}
+		seen[o.Key] = true
+
+		switch o.Kind {
+		case domain.OutputPump, domain.OutputLight, domain.OutputFan, domain.OutputDoser, domain.OutputHeater, domain.OutputValve:
+		default:
+			return fmt.Errorf("unsupported output kind %q", o.Kind)
+		}
+		if o.MaxRuntimeSeconds < 0 || o.MinOffSeconds < 0 {
+			return fmt.Errorf("output %q has negative limits", o.Key)
+		}
+		if o.MaxValue > 0 && o.MaxValue < o.MinValue {
+			return fmt.Errorf("output %q max value is below its min value", o.Key)
+		}
+	}
+	return nil
+}
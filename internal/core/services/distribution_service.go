@@ -0,0 +1,140 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"sort"
+	"time"
+)
+
+// DistributionService shares harvest profits among farm owners
+type DistributionService struct {
+	db        ports.MongoDB
+	ownership *OwnershipService
+}
+
+// NewDistributionService creates a new instance of the distribution service
+func NewDistributionService(db ports.MongoDB, ownership *OwnershipService) *DistributionService {
+	return &DistributionService{db: db, ownership: ownership}
+}
+
+// Preview computes the distribution of a harvest's profit without storing it
+func (s *DistributionService) Preview(harvestID string, req domain.DistributionRequest) (*domain.Distribution, error) {
+	return s.compute(harvestID, req, time.Now())
+}
+
+// Finalize computes the distribution of a harvest's profit and stores it,
+// fixing the payouts owed. A harvest can only be distributed once.
+func (s *DistributionService) Finalize(harvestID string, req domain.DistributionRequest) (*domain.Distribution, error) {
+	now := time.Now()
+	distribution, err := s.compute(harvestID, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	distribution.Status = domain.DistributionFinalized
+	distribution.FinalizedAt = &now
+	if err := s.db.SaveDistribution(distribution); err != nil {
+		return nil, err
+	}
+	return distribution, nil
+}
+
+// Distribution retrieves a stored distribution
+func (s *DistributionService) Distribution(id string) (*domain.Distribution, error) {
+	return s.db.RetrieveDistribution(id)
+}
+
+// FarmDistributions retrieves the distributions of a farm, newest first
+func (s *DistributionService) FarmDistributions(farmID string) ([]domain.Distribution, error) {
+	distributions, err := s.db.RetrieveDistributions(farmID)
+	if err != nil {
+		return []domain.Distribution{}, err
+	}
+	if distributions == nil {
+		distributions = []domain.Distribution{}
+	}
+	return distributions, nil
+}
+
+// compute splits the profit among the owners at the snapshot in proportion
+// to their shares. Shares nobody owned keep their part as retained profit.
+// Rounding remainders go to the largest fractional parts, and ties to
+// larger holdings and then lower owner IDs, so the result is the same every
+// time and adds up to the profit exactly.
+func (s *DistributionService) compute(harvestID string, req domain.DistributionRequest, now time.Time) (*domain.Distribution, error) {
+	if req.Currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	if req.Revenue < 0 || req.Costs < 0 {
+		return nil, errors.New("revenue and costs cannot be negative")
+	}
+	profit := req.Revenue - req.Costs
+	if profit <= 0 {
+		return nil, errors.New("costs exceed revenue, there is no profit to distribute")
+	}
+
+	harvest, err := s.db.RetrieveHarvest(harvestID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := harvest.HarvestedAt
+	if req.SnapshotAt != nil {
+		snapshot = *req.SnapshotAt
+	}
+	if snapshot.After(now) {
+		return nil, errors.New("snapshot cannot be in the future")
+	}
+
+	capTable, err := s.ownership.CapTable(harvest.FarmID, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	owners := capTable.Holders
+	sort.SliceStable(owners, func(i, j int) bool {
+		if owners[i].Shares != owners[j].Shares {
+			return owners[i].Shares > owners[j].Shares
+		}
+		return owners[i].ID < owners[j].ID
+	})
+
+	weights := make([]int64, 0, len(owners)+1)
+	var owned domain.Shares
+	for _, owner := range owners {
+		weights = append(weights, int64(owner.Shares))
+		owned += owner.Shares
+	}
+	if owned > domain.SharesPerFarm {
+		return nil, errors.New("owners hold more than the whole farm")
+	}
+	weights = append(weights, int64(domain.SharesPerFarm-owned))
+
+	parts, err := profit.Allocate(weights)
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := &domain.Distribution{
+		FarmID:     harvest.FarmID,
+		HarvestID:  harvestID,
+		Currency:   req.Currency,
+		Revenue:    req.Revenue,
+		Costs:      req.Costs,
+		Profit:     profit,
+		SnapshotAt: snapshot,
+		Lines:      make([]domain.DistributionLine, 0, len(owners)),
+		Retained:   parts[len(owners)],
+		Status:     domain.DistributionPreview,
+		CreatedAt:  now,
+	}
+	for i, owner := range owners {
+		distribution.Lines = append(distribution.Lines, domain.DistributionLine{
+			OwnerID: owner.ID,
+			Address: owner.Address,
+			Shares:  owner.Shares,
+			Amount:  parts[i],
+		})
+	}
+	return distribution, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/pkg/money"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestDistributions returns a distribution service for harvest-1 of
+// farm-1, whose owners are issued the shares by owner ID before the harvest
+func newTestDistributions(t *testing.T, shares map[string]domain.Shares) *DistributionService {
+	t.Helper()
+	db := newMemoryDB()
+	harvestedAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	db.putFarm(domain.VerticalFarm{ID: "farm-1"})
+	db.harvests["harvest-1"] = domain.HarvestRecord{FarmID: "farm-1", HarvestedAt: harvestedAt}
+	for ownerID, owned := range shares {
+		db.events = append(db.events, domain.OwnershipEvent{
+			FarmID: "farm-1", OwnerID: ownerID, Address: "address-" + ownerID, Type: domain.OwnershipIssued,
+			Change: owned, OccurredAt: harvestedAt.Add(-24 * time.Hour),
+		})
+	}
+	return NewDistributionService(db, NewOwnershipService(db))
+}
+
+func TestDistributionRemainder(t *testing.T) {
+	tests := []struct {
+		name     string
+		shares   map[string]domain.Shares
+		profit   money.Amount // in minor units
+		want     map[string]money.Amount
+		retained money.Amount
+	}{
+		{
+			name:   "largest fraction gets the remainder",
+			shares: map[string]domain.Shares{"a": 333_333, "b": 333_333, "c": 333_334},
+			profit: 10,
+			want:   map[string]money.Amount{"a": 3, "b": 3, "c": 4},
+		},
+		{
+			name:   "ties go to the lower owner ID",
+			shares: map[string]domain.Shares{"b": 500_000, "a": 500_000},
+			profit: 5,
+			want:   map[string]money.Amount{"a": 3, "b": 2},
+		},
+		{
+			name:     "unissued shares are retained",
+			shares:   map[string]domain.Shares{"a": 250_000, "b": 250_000},
+			profit:   7,
+			want:     map[string]money.Amount{"a": 2, "b": 2},
+			retained: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distributions := newTestDistributions(t, tt.shares)
+			req := domain.DistributionRequest{Revenue: tt.profit + money.FromInt(1), Costs: money.FromInt(1), Currency: "XLM"}
+
+			var first map[string]money.Amount
+			for run := 0; run < 5; run++ {
+				distribution, err := distributions.Preview("harvest-1", req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := make(map[string]money.Amount)
+				total := distribution.Retained
+				for _, line := range distribution.Lines {
+					got[line.OwnerID] = line.Amount
+					total += line.Amount
+				}
+				if !reflect.DeepEqual(got, tt.want) || distribution.Retained != tt.retained {
+					t.Fatalf("lines = %v retaining %d, want %v retaining %d", got, distribution.Retained, tt.want, tt.retained)
+				}
+				if total != tt.profit {
+					t.Errorf("distributed %d of a profit of %d", total, tt.profit)
+				}
+				if first != nil && !reflect.DeepEqual(got, first) {
+					t.Errorf("run %d gave %v, first run %v", run, got, first)
+				}
+				first = got
+			}
+		})
+	}
+}
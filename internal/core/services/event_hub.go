@@ -0,0 +1,184 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// hubHistorySize is how many events are kept per farm so reconnecting
+	// clients can resume from their Last-Event-ID
+	hubHistorySize = 256
+	// subscriberBuffer is how many events a subscriber may fall behind before
+	// it is dropped as too slow
+	subscriberBuffer = 64
+)
+
+// EventPublisher accepts farm events for live subscribers
+type EventPublisher interface {
+	Publish(farmID, eventType string, data interface{})
+}
+
+// Subscription receives the live events of the farms it is subscribed to.
+// Events is closed when the subscriber falls too far behind or unsubscribes.
+type Subscription struct {
+	Events <-chan domain.StreamEvent
+
+	events chan domain.StreamEvent
+	farms  map[string]bool
+	closed bool
+}
+
+// EventHub fans out farm events to live subscribers
+type EventHub struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     map[string][]domain.StreamEvent
+	evicted     map[string]uint64 // newest event ID dropped from each farm's history
+	subscribers map[*Subscription]struct{}
+	health      map[string]int
+}
+
+// NewEventHub creates an empty event hub
+func NewEventHub() *EventHub {
+	return &EventHub{
+		// Seed IDs from the clock so they keep increasing across restarts
+		// and a stale Last-Event-ID never skips new events
+		nextID:      uint64(time.Now().UnixMicro()),
+		history:     make(map[string][]domain.StreamEvent),
+		evicted:     make(map[string]uint64),
+		subscribers: make(map[*Subscription]struct{}),
+		health:      make(map[string]int),
+	}
+}
+
+// Publish assigns an ID to a new event and delivers it to the subscribers of
+// the farm. Subscribers whose buffer is full are dropped instead of blocking
+// the publisher.
+func (h *EventHub) Publish(farmID, eventType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event := domain.StreamEvent{
+		ID:        h.nextID,
+		Type:      eventType,
+		FarmID:    farmID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+
+	history := append(h.history[farmID], event)
+	if len(history) > hubHistorySize {
+		h.evicted[farmID] = history[len(history)-hubHistorySize-1].ID
+		history = history[len(history)-hubHistorySize:]
+	}
+	h.history[farmID] = history
+
+	for sub := range h.subscribers {
+		if !sub.farms[farmID] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// ReadingAccepted publishes every accepted reading, and a health event when
+// the farm's health score changed
+func (h *EventHub) ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading) {
+	h.Publish(farmID, domain.EventReading, reading)
+
+	h.mu.Lock()
+	previous, known := h.health[farmID]
+	h.health[farmID] = farm.CurrentHealth
+	h.mu.Unlock()
+
+	if known && previous == farm.CurrentHealth {
+		return
+	}
+	change := domain.HealthChange{Current: farm.CurrentHealth}
+	if known {
+		change.Previous = &previous
+	}
+	h.Publish(farmID, domain.EventHealth, change)
+}
+
+// Subscribe registers a subscriber for the given farms. When lastEventID is
+// set, the events after it that are still in the history are returned to be
+// sent before the live ones; reset reports that some were already evicted.
+func (h *EventHub) Subscribe(farmIDs []string, lastEventID uint64) (sub *Subscription, backlog []domain.StreamEvent, reset bool) {
+	events := make(chan domain.StreamEvent, subscriberBuffer)
+	sub = &Subscription{
+		Events: events,
+		events: events,
+		farms:  make(map[string]bool),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, farmID := range farmIDs {
+		sub.farms[farmID] = true
+		missed, truncated := h.since(farmID, lastEventID)
+		backlog = append(backlog, missed...)
+		reset = reset || truncated
+	}
+	sort.Slice(backlog, func(i, j int) bool { return backlog[i].ID < backlog[j].ID })
+
+	h.subscribers[sub] = struct{}{}
+	return sub, backlog, reset
+}
+
+// AddFarm subscribes an existing subscriber to another farm, returning the
+// backlog the same way Subscribe does
+func (h *EventHub) AddFarm(sub *Subscription, farmID string, lastEventID uint64) (backlog []domain.StreamEvent, reset bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sub.closed || sub.farms[farmID] {
+		return nil, false
+	}
+	sub.farms[farmID] = true
+	return h.since(farmID, lastEventID)
+}
+
+// RemoveFarm stops delivering a farm's events to a subscriber
+func (h *EventHub) RemoveFarm(sub *Subscription, farmID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(sub.farms, farmID)
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (h *EventHub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub)
+}
+
+// since returns the history of a farm after lastEventID. Callers must hold h.mu.
+func (h *EventHub) since(farmID string, lastEventID uint64) (events []domain.StreamEvent, truncated bool) {
+	if lastEventID == 0 {
+		return nil, false
+	}
+
+	history := h.history[farmID]
+	i := sort.Search(len(history), func(i int) bool { return history[i].ID > lastEventID })
+	return append([]domain.StreamEvent(nil), history[i:]...), lastEventID < h.evicted[farmID]
+}
+
+// drop removes a subscriber. Callers must hold h.mu.
+func (h *EventHub) drop(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(h.subscribers, sub)
+	close(sub.events)
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// ExportService streams the readings and rollups of a farm as files
+type ExportService struct {
+	db      ports.MongoDB
+	metrics *MetricRegistry
+	rollups *RollupService
+}
+
+// NewExportService creates a new instance of the export service
+func NewExportService(db ports.MongoDB, metrics *MetricRegistry, rollups *RollupService) *ExportService {
+	return &ExportService{db: db, metrics: metrics, rollups: rollups}
+}
+
+// Export writes the farm's readings in the requested range to w, one row per
+// reading, or one row per rollup bucket when a resolution is requested. Next
+// to the values each row carries the health score and how every targeted
+// metric scored. The request is validated before anything is written.
+func (s *ExportService) Export(farmID string, req domain.ExportRequest, w io.Writer) error {
+	if !req.From.Before(req.To) {
+		return errors.New("from must be before to")
+	}
+	if req.Resolution == domain.ResolutionAuto {
+		req.Resolution = ResolutionFor(req.To.Sub(req.From))
+	}
+	if _, ok := domain.ResolutionDurations[req.Resolution]; req.Resolution != "" && !ok {
+		return fmt.Errorf("unknown resolution %q", req.Resolution)
+	}
+
+	metrics, err := s.exportMetrics(req.Metrics)
+	if err != nil {
+		return err
+	}
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return err
+	}
+
+	// Score columns explain the health score, so they use the same targets
+	var targets []domain.MetricTarget
+	if spec, err := s.db.GetCropSpecification(farm.CropType); err == nil {
+		for _, metric := range metrics {
+			if target, ok := spec.Target(metric); ok {
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	table, err := newTableWriter(req.Format, w)
+	if err != nil {
+		return err
+	}
+	if req.Resolution != "" {
+		err = s.exportRollups(farmID, req, metrics, targets, table)
+	} else {
+		err = s.exportReadings(farmID, farm, req, metrics, targets, table)
+	}
+	if err != nil {
+		return err
+	}
+	return table.Close()
+}
+
+// exportMetrics validates the requested metric keys, defaulting to every
+// registered metric
+func (s *ExportService) exportMetrics(keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		for _, metric := range s.metrics.List() {
+			keys = append(keys, metric.Key)
+		}
+		sort.Strings(keys)
+		return keys, nil
+	}
+	for _, key := range keys {
+		if _, ok := s.metrics.Get(key); !ok {
+			return nil, fmt.Errorf("unknown metric %q", key)
+		}
+	}
+	return keys, nil
+}
+
+// exportReadings writes raw readings, merging the readings kept on the farm
+// with the archived ones in time order
+func (s *ExportService) exportReadings(farmID string, farm *domain.VerticalFarm, req domain.ExportRequest, metrics []string, targets []domain.MetricTarget, table tableWriter) error {
+	columns := []string{"timestamp", "deviceId"}
+	columns = append(columns, metrics...)
+	columns = append(columns, "health", "expectedYield")
+	for _, target := range targets {
+		columns = append(columns, target.Metric+"_score")
+	}
+	if err := table.WriteHeader(columns); err != nil {
+		return err
+	}
+
+	type readingKey struct {
+		timestamp int64
+		deviceID  string
+	}
+	var recent []domain.IoTReading
+	kept := make(map[readingKey]bool)
+	for _, reading := range farm.IoTData {
+		if !reading.Timestamp.Before(req.From) && reading.Timestamp.Before(req.To) {
+			recent = append(recent, reading)
+			kept[readingKey{reading.Timestamp.UnixNano(), reading.DeviceID}] = true
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].Timestamp.Before(recent[j].Timestamp) })
+
+	cells := make([]interface{}, len(columns))
+	write := func(reading domain.IoTReading) error {
+		cells[0], cells[1] = reading.Timestamp, nil
+		if reading.DeviceID != "" {
+			cells[1] = reading.DeviceID
+		}
+		i := 2
+		for _, metric := range metrics {
+			cells[i] = nil
+			if v, ok := reading.Value(metric); ok {
+				cells[i] = v
+			}
+			i++
+		}
+		cells[i], cells[i+1] = reading.CropHealth, reading.ExpectedYield
+		i += 2
+		for _, target := range targets {
+			cells[i] = nil
+			if v, ok := reading.Value(target.Metric); ok {
+				cells[i] = exportScore(v, target)
+			}
+			i++
+		}
+		return table.WriteRow(cells)
+	}
+
+	next := 0
+	err := s.db.StreamReadings(farmID, req.From, req.To, func(reading domain.IoTReading) error {
+		for ; next < len(recent) && recent[next].Timestamp.Before(reading.Timestamp); next++ {
+			if err := write(recent[next]); err != nil {
+				return err
+			}
+		}
+		if kept[readingKey{reading.Timestamp.UnixNano(), reading.DeviceID}] {
+			return nil
+		}
+		return write(reading)
+	})
+	if err != nil {
+		return err
+	}
+	for ; next < len(recent); next++ {
+		if err := write(recent[next]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportRollups writes one row per rollup bucket with the average, minimum
+// and maximum of each metric. Scores are computed from the averages.
+func (s *ExportService) exportRollups(farmID string, req domain.ExportRequest, metrics []string, targets []domain.MetricTarget, table tableWriter) error {
+	series, err := s.rollups.Query(farmID, req.From, req.To, req.Resolution)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"bucketStart", "readings"}
+	for _, metric := range metrics {
+		columns = append(columns, metric+"_avg", metric+"_min", metric+"_max")
+	}
+	columns = append(columns, "health_avg", "health_min", "health_max")
+	for _, target := range targets {
+		columns = append(columns, target.Metric+"_score")
+	}
+	if err := table.WriteHeader(columns); err != nil {
+		return err
+	}
+
+	cells := make([]interface{}, len(columns))
+	for _, bucket := range series.Buckets {
+		for i := range cells {
+			cells[i] = nil
+		}
+		cells[0], cells[1] = bucket.BucketStart, bucket.Health.Count
+		i := 2
+		for _, metric := range metrics {
+			if agg, ok := bucket.Metrics[metric]; ok && agg.Count > 0 {
+				cells[i], cells[i+1], cells[i+2] = agg.Avg(), agg.Min, agg.Max
+			}
+			i += 3
+		}
+		if bucket.Health.Count > 0 {
+			cells[i], cells[i+1], cells[i+2] = round1(bucket.Health.Avg()), bucket.Health.Min, bucket.Health.Max
+		}
+		i += 3
+		for _, target := range targets {
+			if agg, ok := bucket.Metrics[target.Metric]; ok && agg.Count > 0 {
+				cells[i] = exportScore(agg.Avg(), target)
+			}
+			i++
+		}
+		if err := table.WriteRow(cells); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportScore is a metric's contribution to the health score, on the same
+// 0-100 scale
+func exportScore(value float64, target domain.MetricTarget) float64 {
+	return round1(math.Max(0, math.Min(100, metricScore(value, target))))
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tableWriter streams the rows of an export in one file format. Cells are
+// nil when a value is missing, otherwise a string, time, int or float64.
+type tableWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(cells []interface{}) error
+	Close() error
+}
+
+// newTableWriter creates a writer for an export format
+func newTableWriter(format string, w io.Writer) (tableWriter, error) {
+	switch format {
+	case domain.ExportCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case domain.ExportNDJSON:
+		return &ndjsonWriter{writer: bufio.NewWriter(w)}, nil
+	case domain.ExportXLSX:
+		return &xlsxWriter{archive: zip.NewWriter(w)}, nil
+	}
+	return nil, errors.New("unsupported export format " + format)
+}
+
+// ExportContentType returns the MIME type of an export format
+func ExportContentType(format string) string {
+	switch format {
+	case domain.ExportNDJSON:
+		return "application/x-ndjson"
+	case domain.ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// formatCell renders a cell as text
+func formatCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// csvWriter writes comma separated values with a header row
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func (w *csvWriter) WriteHeader(columns []string) error {
+	w.record = make([]string, len(columns))
+	return w.writer.Write(columns)
+}
+
+func (w *csvWriter) WriteRow(cells []interface{}) error {
+	for i, cell := range cells {
+		w.record[i] = formatCell(cell)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonWriter writes one JSON object per row, keeping the column order and
+// leaving out missing values
+type ndjsonWriter struct {
+	writer  *bufio.Writer
+	columns [][]byte // column names encoded as JSON keys
+	value   bytes.Buffer
+	encoder *json.Encoder
+}
+
+// encode renders v as JSON without escaping HTML characters
+func (w *ndjsonWriter) encode(v interface{}) ([]byte, error) {
+	if w.encoder == nil {
+		w.encoder = json.NewEncoder(&w.value)
+		w.encoder.SetEscapeHTML(false)
+	}
+	w.value.Reset()
+	if err := w.encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(w.value.Bytes(), []byte("\n")), nil
+}
+
+func (w *ndjsonWriter) WriteHeader(columns []string) error {
+	w.columns = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := w.encode(column)
+		if err != nil {
+			return err
+		}
+		w.columns[i] = append(append([]byte{}, key...), ':')
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) WriteRow(cells []interface{}) error {
+	w.writer.WriteByte('{')
+	first := true
+	for i, cell := range cells {
+		if cell == nil {
+			continue
+		}
+		if t, ok := cell.(time.Time); ok {
+			cell = t.UTC().Format(time.RFC3339)
+		}
+		value, err := w.encode(cell)
+		if err != nil {
+			return err
+		}
+		if !first {
+			w.writer.WriteByte(',')
+		}
+		first = false
+		w.writer.Write(w.columns[i])
+		w.writer.Write(value)
+	}
+	w.writer.WriteString("}\n")
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error { return w.writer.Flush() }
+
+// xlsxWriter writes a single-sheet Excel workbook. The package parts are
+// written up front and the sheet is streamed row by row using inline
+// strings, so the workbook never has to be held in memory.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	row     int
+}
+
+// xlsxParts are the fixed parts of the workbook package
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Readings" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func (w *xlsxWriter) WriteHeader(columns []string) error {
+	for _, part := range xlsxParts {
+		f, err := w.archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	// The sheet must be the last part as it is still being written
+	f, err := w.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(f)
+	w.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	cells := make([]interface{}, len(columns))
+	for i, column := range columns {
+		cells[i] = column
+	}
+	return w.WriteRow(cells)
+}
+
+func (w *xlsxWriter) WriteRow(cells []interface{}) error {
+	w.row++
+	w.sheet.WriteString(`<row r="` + strconv.Itoa(w.row) + `">`)
+	for i, cell := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(w.row)
+		switch v := cell.(type) {
+		case nil:
+			continue
+		case int, int64, float64:
+			w.sheet.WriteString(`<c r="` + ref + `"><v>` + formatCell(v) + `</v></c>`)
+		default:
+			w.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t>`)
+			if err := xml.EscapeText(w.sheet, []byte(formatCell(v))); err != nil {
+				return err
+			}
+			w.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if w.sheet == nil {
+		return w.archive.Close()
+	}
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+// xlsxColumn returns the spreadsheet letters of a zero-based column index
+func xlsxColumn(i int) string {
+	var letters strings.Builder
+	for i++; i > 0; i = (i - 1) / 26 {
+		letters.WriteByte(byte('A' + (i-1)%26))
+	}
+	name := []byte(letters.String())
+	for l, r := 0, len(name)-1; l < r; l, r = l+1, r-1 {
+		name[l], name[r] = name[r], name[l]
+	}
+	return string(name)
+}
@@ -1,29 +1,77 @@
 package services
 
 import (
-	"0xFarms-backend/internal/adapters"
 	"0xFarms-backend/internal/core/domain"
 	"0xFarms-backend/internal/ports"
 	"errors"
+	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ReadingObserver is notified after a reading has been accepted and the farm
+// updated. Observers run synchronously on the ingest path and must not block.
+type ReadingObserver interface {
+	ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading)
+}
+
 // FarmManagementSystemService handles all farm operations
 type FarmManagementSystemService struct {
-	db ports.MongoDB
+	db        ports.MongoDB
+	metrics   *MetricRegistry
+	observers []ReadingObserver
+
+	ingestMu sync.Mutex
+	ingest   map[string]*sync.RWMutex // by farm ID, read-held while a reading is stored and observed
 }
 
 // NewFarmManagementSystemService initializes a new farm management system
-func NewFarmManagementSystemService(db ports.MongoDB) *FarmManagementSystemService {
+func NewFarmManagementSystemService(db ports.MongoDB, metrics *MetricRegistry) *FarmManagementSystemService {
 	system := &FarmManagementSystemService{
-		db: db,
+		db:      db,
+		metrics: metrics,
+		ingest:  make(map[string]*sync.RWMutex),
 	}
 	return system
 }
 
+// AddReadingObserver registers an observer for accepted readings
+func (fms *FarmManagementSystemService) AddReadingObserver(observer ReadingObserver) {
+	fms.observers = append(fms.observers, observer)
+}
+
+// PauseIngest waits for readings of the farm being stored to be observed and
+// keeps new ones from being stored until resume is called. Work that must
+// see each reading of the farm exactly once, such as rebuilding its rollups,
+// runs in between.
+func (fms *FarmManagementSystemService) PauseIngest(farmID string) (resume func()) {
+	lock := fms.ingestLock(farmID)
+	lock.Lock()
+	return lock.Unlock
+}
+
+// ingesting holds off PauseIngest for the farm until done is called.
+// Readings are stored and handed to observers in between.
+func (fms *FarmManagementSystemService) ingesting(farmID string) (done func()) {
+	lock := fms.ingestLock(farmID)
+	lock.RLock()
+	return lock.RUnlock
+}
+
+func (fms *FarmManagementSystemService) ingestLock(farmID string) *sync.RWMutex {
+	fms.ingestMu.Lock()
+	defer fms.ingestMu.Unlock()
+	lock, ok := fms.ingest[farmID]
+	if !ok {
+		lock = &sync.RWMutex{}
+		fms.ingest[farmID] = lock
+	}
+	return lock
+}
+
 // // initializeCropSpecs sets up default crop specifications
 // func (fms *FarmManagementSystemService) initializeCropSpecs() {
 // 	fms.crops["lettuce"] = domain.CropSpecification{
@@ -59,7 +107,7 @@ func (fms *FarmManagementSystemService) CreateFarm(width, height float64, cropTy
 		EstimatedHarvestTime: time.Now().Add(cropSpec.GrowthPeriod),
 		Owners:               make([]domain.Owner, 0),
 		IoTData:              make([]domain.IoTReading, 0),
-		Status:               "active",
+		Status:               domain.FarmActive,
 		CurrentHealth:        100,
 		LastUpdated:          time.Now(),
 	}
@@ -72,33 +120,70 @@ func (fms *FarmManagementSystemService) CreateFarm(width, height float64, cropTy
 	return farm, nil
 }
 
-// AddOwner adds a new owner to the farm
-func (fms *FarmManagementSystemService) AddOwner(farmID, address string, shareSize float64) error {
-	farm, err := fms.db.GetFarm(farmID)
-	if err != nil {
-		return err
+// AddOwner adds a new owner to the farm and returns the owner entry. The
+// address must be a wallet bound to a user.
+func (fms *FarmManagementSystemService) AddOwner(farmID, address string, shares domain.Shares) (*domain.Owner, error) {
+	if shares <= 0 {
+		return nil, errors.New("share size must be positive")
 	}
-
-	// Calculate total existing shares
-	var totalShares float64
-	for _, owner := range farm.Owners {
-		totalShares += owner.ShareSize
+	if err := checkOwnerAddress(fms.db, address); err != nil {
+		return nil, err
 	}
 
-	if totalShares+shareSize > 100 {
-		return errors.New("ownership share exceeds 100%")
+	owner := domain.Owner{
+		ID:       uuid.New().String(),
+		Address:  address,
+		Shares:   shares,
+		JoinedAt: time.Now(),
+	}
+	err := fms.db.UpdateFarmOwners(farmID, func(farm *domain.VerticalFarm) error {
+		if farm.Status == domain.FarmSettling {
+			return errors.New("ownership is frozen while the farm is settling")
+		}
+
+		// Calculate total existing shares
+		var totalShares domain.Shares
+		for _, existing := range farm.Owners {
+			totalShares += existing.Shares
+		}
+
+		if totalShares+shares > domain.SharesPerFarm {
+			return errors.New("ownership share exceeds 100%")
+		}
+
+		farm.Owners = append(farm.Owners, owner)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &owner, nil
+}
 
-	owner := domain.Owner{
-		ID:        uuid.New().String(),
-		Address:   address,
-		ShareSize: shareSize,
-		JoinedAt:  time.Now(),
+// RemoveOwner removes an owner entry from the farm, releasing its share
+func (fms *FarmManagementSystemService) RemoveOwner(farmID, ownerID string) error {
+	return fms.db.UpdateFarmOwners(farmID, func(farm *domain.VerticalFarm) error {
+		if farm.Status == domain.FarmSettling {
+			return errors.New("ownership is frozen while the farm is settling")
+		}
+
+		for i, owner := range farm.Owners {
+			if owner.ID == ownerID {
+				farm.Owners = append(farm.Owners[:i], farm.Owners[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("owner not found")
+	})
+}
+
+// SetStatus moves the farm to a lifecycle status
+func (fms *FarmManagementSystemService) SetStatus(farmID, status string) error {
+	if !domain.ValidFarmStatus(status) {
+		return fmt.Errorf("unknown farm status %q", status)
 	}
 
-	farm.Owners = append(farm.Owners, owner)
-	_, err = fms.db.UpdateFarm(farmID, farm)
-	return err
+	return fms.db.SetFarmStatus(farmID, status)
 }
 
 // AddIoTReading adds a new IoT sensor reading and updates farm status
@@ -113,13 +198,22 @@ func (fms *FarmManagementSystemService) AddIoTReading(farmID string, reading dom
 		return errors.New("crop specification not found")
 	}
 
+	if err := fms.metrics.ValidateReading(reading); err != nil {
+		return err
+	}
+
 	// Calculate crop health based on optimal conditions
-	healthScore := fms.calculateHealthScore(reading, cropSpec)
+	healthScore, ok := fms.calculateHealthScore(reading, cropSpec)
+	if !ok {
+		return errors.New("reading carries none of the metrics targeted by the crop specification")
+	}
 	reading.CropHealth = healthScore
 
 	// Calculate expected yield based on health and area
 	reading.ExpectedYield = fms.calculateExpectedYield(farm, healthScore, cropSpec)
 
+	done := fms.ingesting(farmID)
+	defer done()
 	err = fms.db.AddIoTReading(farmID, &reading)
 	if err != nil {
 		return err
@@ -128,9 +222,112 @@ func (fms *FarmManagementSystemService) AddIoTReading(farmID string, reading dom
 	farm.IoTData = append(farm.IoTData, reading)
 	farm.CurrentHealth = healthScore
 	farm.LastUpdated = reading.Timestamp
+	if reading.Timestamp.After(farm.LastReadingAt) {
+		farm.LastReadingAt = reading.Timestamp
+	}
 
-	_, err = fms.db.UpdateFarm(farmID, farm)
-	return err
+	for _, observer := range fms.observers {
+		observer.ReadingAccepted(farmID, farm, reading)
+	}
+	return nil
+}
+
+// IngestReading converts a reading reported in the declared units to canonical
+// units and adds it to the farm
+func (fms *FarmManagementSystemService) IngestReading(farmID string, reading domain.IoTReading, declaredUnits map[string]string) error {
+	if err := fms.metrics.NormalizeReading(&reading, declaredUnits); err != nil {
+		return err
+	}
+	return fms.AddIoTReading(farmID, reading)
+}
+
+// GetFarmInUnits retrieves the farm with its readings rendered in the
+// preferred units, along with the unit each metric is rendered in
+func (fms *FarmManagementSystemService) GetFarmInUnits(farmID string, preferredUnits map[string]string) (*domain.VerticalFarm, map[string]string, error) {
+	farm, err := fms.db.GetFarm(farmID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, reading := range farm.IoTData {
+		rendered, err := fms.metrics.RenderReading(reading, preferredUnits)
+		if err != nil {
+			return nil, nil, err
+		}
+		farm.IoTData[i] = rendered
+	}
+
+	return farm, fms.metrics.RenderedUnits(preferredUnits), nil
+}
+
+// SetReportingInterval sets how often readings are expected from a farm
+func (fms *FarmManagementSystemService) SetReportingInterval(farmID string, seconds int) error {
+	if seconds < 0 {
+		return errors.New("reporting interval cannot be negative")
+	}
+
+	return fms.db.SetFarmReportingInterval(farmID, seconds)
+}
+
+// SetReservoir describes the farm's nutrient tank
+func (fms *FarmManagementSystemService) SetReservoir(farmID string, reservoir domain.Reservoir) error {
+	if reservoir.VolumeLiters <= 0 {
+		return errors.New("tank volume must be positive")
+	}
+	if reservoir.ConcentrateStrength < 0 || reservoir.PHDownStrength < 0 || reservoir.PHUpStrength < 0 {
+		return errors.New("solution strengths cannot be negative")
+	}
+
+	return fms.db.SetFarmReservoir(farmID, &reservoir)
+}
+
+// RecordHarvest records the produce taken from a farm. The cycle it ends
+// starts at planting, or at the previous harvest for crops cut repeatedly.
+func (fms *FarmManagementSystemService) RecordHarvest(farmID string, harvest domain.HarvestRecord) (*domain.HarvestRecord, error) {
+	if harvest.YieldKg <= 0 {
+		return nil, errors.New("yield must be positive")
+	}
+	farm, err := fms.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+	if harvest.HarvestedAt.IsZero() {
+		harvest.HarvestedAt = time.Now()
+	}
+
+	harvest.CycleStart = farm.PlantingDate
+	previous, err := fms.db.RetrieveHarvests(farmID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range previous {
+		if p.HarvestedAt.After(harvest.CycleStart) && p.HarvestedAt.Before(harvest.HarvestedAt) {
+			harvest.CycleStart = p.HarvestedAt
+		}
+	}
+	if !harvest.HarvestedAt.After(harvest.CycleStart) {
+		return nil, errors.New("harvest must be after the start of the growing cycle")
+	}
+
+	harvest.FarmID = farmID
+	harvest.CropType = farm.CropType
+	harvest.CreatedAt = time.Now()
+	if err := fms.db.SaveHarvest(&harvest); err != nil {
+		return nil, err
+	}
+	return &harvest, nil
+}
+
+// ListHarvests retrieves the harvest records of a farm, oldest first
+func (fms *FarmManagementSystemService) ListHarvests(farmID string) ([]domain.HarvestRecord, error) {
+	harvests, err := fms.db.RetrieveHarvests(farmID)
+	if err != nil {
+		return []domain.HarvestRecord{}, err
+	}
+	if harvests == nil {
+		harvests = []domain.HarvestRecord{}
+	}
+	return harvests, nil
 }
 
 // GetFarmStatus retrieves current farm status and analytics
@@ -138,17 +335,47 @@ func (fms *FarmManagementSystemService) GetFarmStatus(farmID string) (*domain.Ve
 	return fms.db.GetFarm(farmID)
 }
 
-// calculateHealthScore determines crop health based on environmental conditions
-func (fms *FarmManagementSystemService) calculateHealthScore(reading domain.IoTReading, spec domain.CropSpecification) int {
-	phScore := 100 - math.Abs(reading.SoilPH-spec.OptimalPH)*10
-	humidityScore := 100 - math.Abs(reading.Humidity-spec.OptimalHumidity)
-	tempScore := 100 - math.Abs(reading.Temperature-spec.OptimalTemp)*2
-	nutrientScore := (reading.NutrientLevel / spec.NutrientNeeds) * 100
+// SaveCropSpecification creates or replaces a crop specification
+func (fms *FarmManagementSystemService) SaveCropSpecification(spec domain.CropSpecification) error {
+	if spec.Name == "" {
+		return errors.New("crop name is required")
+	}
+	if err := fms.metrics.ValidateTargets(spec); err != nil {
+		return err
+	}
+	return fms.db.SaveCropSpecification(&spec)
+}
+
+// calculateHealthScore determines crop health based on environmental conditions.
+// Only the targeted metrics present in the reading are scored, and their
+// weights are rescaled so they still add up to one. It returns false when the
+// reading carries none of the targeted metrics.
+func (fms *FarmManagementSystemService) calculateHealthScore(reading domain.IoTReading, spec domain.CropSpecification) (int, bool) {
+	var weighted, totalWeight float64
+	for _, target := range spec.MetricTargets() {
+		value, ok := reading.Value(target.Metric)
+		if !ok {
+			continue
+		}
+		weighted += metricScore(value, target) * target.Weight
+		totalWeight += target.Weight
+	}
+	if totalWeight == 0 {
+		return 0, false
+	}
 
 	// Weighted average of all scores
-	healthScore := (phScore*0.25 + humidityScore*0.25 + tempScore*0.25 + nutrientScore*0.25)
+	healthScore := weighted / totalWeight
+
+	return int(math.Max(0, math.Min(100, healthScore))), true
+}
 
-	return int(math.Max(0, math.Min(100, healthScore)))
+// metricScore scores a single metric value against its target
+func metricScore(value float64, target domain.MetricTarget) float64 {
+	if target.Scoring == domain.ScoringRatio {
+		return (value / target.Optimal) * 100
+	}
+	return 100 - math.Abs(value-target.Optimal)*target.Penalty
 }
 
 // calculateExpectedYield estimates crop yield based on current conditions
@@ -158,33 +385,6 @@ func (fms *FarmManagementSystemService) calculateExpectedYield(farm *domain.Vert
 	return baseYield * healthFactor
 }
 
-// Example usage
-func mains() {
-	db, _ := adapters.NewMongoAdapter("")
-	// Initialize the system
-	fms := NewFarmManagementSystemService(db)
-
-	// Create a new vertical farm
-	farm, _ := fms.CreateFarm(10.0, 5.0, "lettuce")
-
-	// Add an owner
-	fms.AddOwner(farm.ID, "0x123abc...", 50.0)
-
-	// Simulate IoT reading
-	reading := domain.IoTReading{
-		Timestamp:     time.Now(),
-		SoilPH:        6.5,
-		Humidity:      65.0,
-		NutrientLevel: 0.8,
-		Temperature:   23.0,
-	}
-	fms.AddIoTReading(farm.ID, reading)
-
-	// Get farm status
-	farmStatus, _ := fms.GetFarmStatus(farm.ID)
-	_ = farmStatus // Use the status as needed
-}
-
 // getCropSpecification retrieves the crop specification from the database
 func (fms *FarmManagementSystemService) getCropSpecification(cropType string) (domain.CropSpecification, bool) {
 	// Fetch crop specification from the database
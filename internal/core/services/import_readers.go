@@ -0,0 +1,203 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// rowReader streams the rows of an import file as strings keyed by column.
+// Offset is the position to resume from after the last row returned.
+type rowReader interface {
+	Header() []string
+	Next() ([]string, error)
+	Offset() int64
+	Close() error
+}
+
+// openRowReader opens an import file positioned at offset
+func openRowReader(format, path string, offset int64) (rowReader, error) {
+	switch format {
+	case domain.ImportCSV:
+		return openCSVReader(path, offset)
+	case domain.ImportParquet:
+		return openParquetReader(path, offset)
+	}
+	return nil, errors.New("unsupported import format " + format)
+}
+
+// csvReader reads comma or semicolon separated files with a header row
+type csvReader struct {
+	file   *os.File
+	reader *csv.Reader
+	header []string
+	base   int64 // file offset the current csv.Reader started at
+}
+
+func openCSVReader(path string, offset int64) (*csvReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &csvReader{file: file}
+	reader := newCSVReader(file, ',')
+	header, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// Exports from European controllers often use semicolons
+	if len(header) == 1 && strings.Contains(header[0], ";") {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		reader = newCSVReader(file, ';')
+		if header, err = reader.Read(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	r.header = header
+	r.reader = reader
+
+	// The csv.Reader buffers ahead, so resuming needs a fresh one at the offset
+	if offset > reader.InputOffset() {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		r.reader = newCSVReader(file, reader.Comma)
+		r.base = offset
+	}
+	return r, nil
+}
+
+func newCSVReader(file *os.File, comma rune) *csv.Reader {
+	reader := csv.NewReader(file)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	return reader
+}
+
+func (r *csvReader) Header() []string { return r.header }
+
+func (r *csvReader) Next() ([]string, error) { return r.reader.Read() }
+
+func (r *csvReader) Offset() int64 { return r.base + r.reader.InputOffset() }
+
+func (r *csvReader) Close() error { return r.file.Close() }
+
+// parquetReader reads the leaf columns of a Parquet file. Timestamp columns
+// are rendered in RFC 3339, other values in their natural text form.
+type parquetReader struct {
+	file       *os.File
+	reader     *parquet.GenericReader[any]
+	header     []string
+	timestamps map[int]time.Duration // column index to timestamp unit
+	rows       []parquet.Row
+	offset     int64
+}
+
+func openParquetReader(path string, offset int64) (*parquetReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &parquetReader{
+		file:       file,
+		reader:     parquet.NewGenericReader[any](file),
+		timestamps: make(map[int]time.Duration),
+		rows:       make([]parquet.Row, 1),
+		offset:     offset,
+	}
+	schema := r.reader.Schema()
+	for i, path := range schema.Columns() {
+		r.header = append(r.header, strings.Join(path, "."))
+		leaf, ok := schema.Lookup(path...)
+		if !ok {
+			continue
+		}
+		if logical := leaf.Node.Type().LogicalType(); logical != nil && logical.Timestamp != nil {
+			switch unit := logical.Timestamp.Unit; {
+			case unit.Millis != nil:
+				r.timestamps[i] = time.Millisecond
+			case unit.Micros != nil:
+				r.timestamps[i] = time.Microsecond
+			default:
+				r.timestamps[i] = time.Nanosecond
+			}
+		}
+	}
+
+	if offset > 0 {
+		if err := r.reader.SeekToRow(offset); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// NumRows returns the number of rows in the file
+func (r *parquetReader) NumRows() int64 { return r.reader.NumRows() }
+
+func (r *parquetReader) Header() []string { return r.header }
+
+func (r *parquetReader) Next() ([]string, error) {
+	n, err := r.reader.ReadRows(r.rows)
+	if n == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	r.offset++
+
+	record := make([]string, len(r.header))
+	for _, value := range r.rows[0] {
+		column := value.Column()
+		if column < 0 || column >= len(record) || value.IsNull() {
+			continue
+		}
+		if unit, ok := r.timestamps[column]; ok {
+			record[column] = time.Unix(0, value.Int64()*int64(unit)).UTC().Format(time.RFC3339Nano)
+			continue
+		}
+		switch value.Kind() {
+		case parquet.Boolean:
+			record[column] = strconv.FormatBool(value.Boolean())
+		case parquet.Int32:
+			record[column] = strconv.FormatInt(int64(value.Int32()), 10)
+		case parquet.Int64:
+			record[column] = strconv.FormatInt(value.Int64(), 10)
+		case parquet.Float:
+			record[column] = strconv.FormatFloat(float64(value.Float()), 'g', -1, 32)
+		case parquet.Double:
+			record[column] = strconv.FormatFloat(value.Double(), 'g', -1, 64)
+		default:
+			record[column] = string(value.ByteArray())
+		}
+	}
+	return record, nil
+}
+
+func (r *parquetReader) Offset() int64 { return r.offset }
+
+func (r *parquetReader) Close() error {
+	r.reader.Close()
+	return r.file.Close()
+}
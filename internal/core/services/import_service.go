@@ -0,0 +1,583 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// importBatchSize is how many rows are committed together. Progress is saved
+// after every batch, so a resumed job repeats at most one batch.
+const importBatchSize = 1000
+
+// timestampLayouts are tried in turn when a job does not declare its layout
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"01/02/2006 15:04:05",
+	"02.01.2006 15:04:05",
+}
+
+// ImportService imports historical sensor data from files into the reading
+// archive and the rollups. Readings are validated and scored like live ones
+// but do not trigger alerts or control policies.
+type ImportService struct {
+	db      ports.MongoDB
+	farms   *FarmManagementSystemService
+	metrics *MetricRegistry
+	rollups *RollupService
+	dir     string
+
+	mu      sync.Mutex
+	running map[string]*importRun
+	wg      sync.WaitGroup
+}
+
+// importRun is a job running in this process
+type importRun struct {
+	cancel    context.CancelFunc
+	done      chan struct{}
+	cancelled bool // stopped by a user rather than a shutdown
+}
+
+// NewImportService creates a new instance of the import service keeping
+// uploaded files in dir
+func NewImportService(db ports.MongoDB, farms *FarmManagementSystemService, metrics *MetricRegistry, rollups *RollupService, dir string) *ImportService {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "0xfarms-imports")
+	}
+	return &ImportService{
+		db:      db,
+		farms:   farms,
+		metrics: metrics,
+		rollups: rollups,
+		dir:     dir,
+		running: make(map[string]*importRun),
+	}
+}
+
+// Load resumes the jobs that were queued or running when the server stopped
+func (s *ImportService) Load() error {
+	jobs, err := s.db.RetrieveImportJobs("", []string{domain.ImportQueued, domain.ImportRunning})
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		s.start(&jobs[i])
+	}
+	return nil
+}
+
+// Create stores the uploaded file and queues a job importing it into the
+// farm's history. The format is taken from the file name when not given.
+func (s *ImportService) Create(farmID string, job domain.ImportJob, file io.Reader) (*domain.ImportJob, error) {
+	if _, err := s.db.GetFarm(farmID); err != nil {
+		return nil, err
+	}
+
+	if job.Format == "" {
+		job.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(job.FileName)), ".")
+	}
+	if job.Format != domain.ImportCSV && job.Format != domain.ImportParquet {
+		return nil, fmt.Errorf("unsupported import format %q", job.Format)
+	}
+	if job.Timezone != "" {
+		if _, err := time.LoadLocation(job.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", job.Timezone)
+		}
+	}
+	for column, target := range job.Mapping {
+		if target == domain.ColumnTimestamp || target == domain.ColumnDeviceID {
+			continue
+		}
+		if _, ok := s.metrics.Get(target); !ok {
+			return nil, fmt.Errorf("column %q maps to unregistered metric %q", column, target)
+		}
+	}
+	for metric := range job.Units {
+		if _, ok := s.metrics.Get(metric); !ok {
+			return nil, fmt.Errorf("unit declared for unregistered metric %q", metric)
+		}
+	}
+
+	now := time.Now()
+	job.FarmID = farmID
+	job.Status = domain.ImportQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if err := s.db.SaveImportJob(&job); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, s.fail(&job, err)
+	}
+	job.Path = filepath.Join(s.dir, job.ID.Hex()+"."+job.Format)
+	out, err := os.Create(job.Path)
+	if err != nil {
+		return nil, s.fail(&job, err)
+	}
+	job.BytesTotal, err = io.Copy(out, file)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, s.fail(&job, err)
+	}
+
+	// Check the header now so mistakes surface before the job is queued
+	reader, err := openRowReader(job.Format, job.Path, 0)
+	if err != nil {
+		return nil, s.fail(&job, fmt.Errorf("unreadable %s file: %v", job.Format, err))
+	}
+	if pr, ok := reader.(*parquetReader); ok {
+		job.RowsTotal = pr.NumRows()
+	}
+	_, err = resolveColumns(reader.Header(), job.Mapping, s.metrics)
+	reader.Close()
+	if err != nil {
+		return nil, s.fail(&job, err)
+	}
+
+	if err := s.db.UpdateImportJob(&job); err != nil {
+		return nil, err
+	}
+	s.start(&job)
+	return &job, nil
+}
+
+// Get retrieves an import job with its progress
+func (s *ImportService) Get(id string) (*domain.ImportJob, error) {
+	return s.db.RetrieveImportJob(id)
+}
+
+// List retrieves the import jobs of a farm, newest first
+func (s *ImportService) List(farmID string) ([]domain.ImportJob, error) {
+	jobs, err := s.db.RetrieveImportJobs(farmID, nil)
+	if err != nil {
+		return []domain.ImportJob{}, err
+	}
+	return jobs, nil
+}
+
+// Rejections retrieves the rows a job rejected and why
+func (s *ImportService) Rejections(id string) ([]domain.ImportRejection, error) {
+	rejections, err := s.db.RetrieveImportRejections(id)
+	if err != nil {
+		return []domain.ImportRejection{}, err
+	}
+	return rejections, nil
+}
+
+// Cancel stops a job after its current batch. Cancelled jobs can be resumed.
+func (s *ImportService) Cancel(id string) (*domain.ImportJob, error) {
+	job, err := s.db.RetrieveImportJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return nil, fmt.Errorf("import job is already %s", job.Status)
+	}
+
+	s.mu.Lock()
+	run, ok := s.running[id]
+	if ok {
+		run.cancelled = true
+		run.cancel()
+	}
+	s.mu.Unlock()
+	if ok {
+		<-run.done
+		return s.db.RetrieveImportJob(id)
+	}
+
+	job.Status = domain.ImportCancelled
+	job.UpdatedAt = time.Now()
+	return job, s.db.UpdateImportJob(job)
+}
+
+// Resume restarts a failed or cancelled job from its last committed batch
+func (s *ImportService) Resume(id string) (*domain.ImportJob, error) {
+	job, err := s.db.RetrieveImportJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.ImportFailed && job.Status != domain.ImportCancelled {
+		return nil, fmt.Errorf("import job is %s", job.Status)
+	}
+	if _, err := os.Stat(job.Path); err != nil {
+		return nil, errors.New("the uploaded file is gone, import it again")
+	}
+
+	job.Status = domain.ImportQueued
+	job.Error = ""
+	job.UpdatedAt = time.Now()
+	if err := s.db.UpdateImportJob(job); err != nil {
+		return nil, err
+	}
+	s.start(job)
+	return job, nil
+}
+
+// Wait blocks until the job stops running in this process
+func (s *ImportService) Wait(id string) {
+	s.mu.Lock()
+	run, ok := s.running[id]
+	s.mu.Unlock()
+	if ok {
+		<-run.done
+	}
+}
+
+// Shutdown stops every running job after its current batch, leaving them
+// queued so they resume on the next start
+func (s *ImportService) Shutdown() {
+	s.mu.Lock()
+	for _, run := range s.running {
+		run.cancel()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// start runs the job in the background unless it is already running
+func (s *ImportService) start(job *domain.ImportJob) {
+	id := job.ID.Hex()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.running[id]; ok {
+		return
+	}
+
+	// The goroutine works on its own copy so callers can hand theirs out
+	running := *job
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &importRun{cancel: cancel, done: make(chan struct{})}
+	s.running[id] = run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, id)
+			s.mu.Unlock()
+			cancel()
+			close(run.done)
+		}()
+		if err := s.run(ctx, &running, run); err != nil {
+			logger.LogWarning(fmt.Sprintf("Import job %s failed: %v", id, err))
+		}
+	}()
+}
+
+// run imports the job's file from its last committed offset
+func (s *ImportService) run(ctx context.Context, job *domain.ImportJob, run *importRun) error {
+	farm, err := s.db.GetFarm(job.FarmID)
+	if err != nil {
+		return s.fail(job, err)
+	}
+	spec, err := s.db.GetCropSpecification(farm.CropType)
+	if err != nil {
+		return s.fail(job, errors.New("crop specification not found"))
+	}
+	location := time.UTC
+	if job.Timezone != "" {
+		if location, err = time.LoadLocation(job.Timezone); err != nil {
+			return s.fail(job, err)
+		}
+	}
+
+	reader, err := openRowReader(job.Format, job.Path, job.Offset)
+	if err != nil {
+		return s.fail(job, err)
+	}
+	defer reader.Close()
+	columns, err := resolveColumns(reader.Header(), job.Mapping, s.metrics)
+	if err != nil {
+		return s.fail(job, err)
+	}
+
+	job.Status = domain.ImportRunning
+	job.UpdatedAt = time.Now()
+	if err := s.db.UpdateImportJob(job); err != nil {
+		return err
+	}
+
+	header := reader.Header()
+	now := time.Now()
+	readings := make([]domain.IoTReading, 0, importBatchSize)
+	var rejections []domain.ImportRejection
+	rows := int64(0)
+
+	for {
+		record, readErr := reader.Next()
+		if readErr != nil && readErr != io.EOF {
+			var parseErr *csv.ParseError
+			if !errors.As(readErr, &parseErr) {
+				return s.fail(job, readErr)
+			}
+			// A malformed line is rejected rather than failing the job
+			rows++
+			rejections = append(rejections, domain.ImportRejection{
+				JobID:  job.ID.Hex(),
+				Row:    job.RowsRead + rows,
+				Reason: readErr.Error(),
+				Values: map[string]string{},
+			})
+		} else if readErr == nil {
+			rows++
+			reading, err := s.parseRow(record, columns, job, location, now, farm, spec)
+			if err != nil {
+				rejections = append(rejections, domain.ImportRejection{
+					JobID:  job.ID.Hex(),
+					Row:    job.RowsRead + rows,
+					Reason: err.Error(),
+					Values: rowValues(header, record),
+				})
+			} else {
+				readings = append(readings, reading)
+			}
+		}
+
+		if rows == importBatchSize || (readErr == io.EOF && rows > 0) {
+			if err := s.commit(job, readings, rejections, rows, reader.Offset()); err != nil {
+				return s.fail(job, err)
+			}
+			readings = readings[:0]
+			rejections = nil
+			rows = 0
+
+			if ctx.Err() != nil {
+				// Stopped by a shutdown the job stays queued, by a user it is cancelled
+				s.mu.Lock()
+				cancelled := run.cancelled
+				s.mu.Unlock()
+				job.Status = domain.ImportQueued
+				if cancelled {
+					job.Status = domain.ImportCancelled
+				}
+				job.UpdatedAt = time.Now()
+				return s.db.UpdateImportJob(job)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	completed := time.Now()
+	job.Status = domain.ImportCompleted
+	job.Progress = 100
+	job.UpdatedAt = completed
+	job.CompletedAt = &completed
+	if err := s.db.UpdateImportJob(job); err != nil {
+		return err
+	}
+	if err := os.Remove(job.Path); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to remove imported file %s: %v", job.Path, err))
+	}
+	logger.LogInfo(fmt.Sprintf("Import job %s imported %d readings, rejected %d rows", job.ID.Hex(), job.RowsImported, job.RowsRejected))
+	return nil
+}
+
+// commit stores a batch and records the job's progress past it
+func (s *ImportService) commit(job *domain.ImportJob, readings []domain.IoTReading, rejections []domain.ImportRejection, rows, offset int64) error {
+	done := s.farms.ingesting(job.FarmID)
+	inserted, err := s.db.InsertReadings(job.FarmID, readings)
+	if err == nil {
+		err = s.rollups.RecordBatch(job.FarmID, inserted)
+	}
+	done()
+	if err != nil {
+		return err
+	}
+	if err := s.db.SaveImportRejections(rejections); err != nil {
+		return err
+	}
+
+	job.Offset = offset
+	job.RowsRead += rows
+	job.RowsImported += int64(len(inserted))
+	job.RowsDuplicate += int64(len(readings) - len(inserted))
+	job.RowsRejected += int64(len(rejections))
+	switch {
+	case job.RowsTotal > 0:
+		job.Progress = float64(job.RowsRead) / float64(job.RowsTotal) * 100
+	case job.BytesTotal > 0:
+		job.Progress = float64(offset) / float64(job.BytesTotal) * 100
+	}
+	job.UpdatedAt = time.Now()
+	return s.db.UpdateImportJob(job)
+}
+
+// fail marks the job failed with the error and returns the error
+func (s *ImportService) fail(job *domain.ImportJob, err error) error {
+	job.Status = domain.ImportFailed
+	job.Error = err.Error()
+	job.UpdatedAt = time.Now()
+	if updateErr := s.db.UpdateImportJob(job); updateErr != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to record failure of import job %s: %v", job.ID.Hex(), updateErr))
+	}
+	return err
+}
+
+// parseRow turns a file row into a validated, scored reading in canonical units
+func (s *ImportService) parseRow(record []string, columns map[int]string, job *domain.ImportJob, location *time.Location, now time.Time, farm *domain.VerticalFarm, spec domain.CropSpecification) (domain.IoTReading, error) {
+	var reading domain.IoTReading
+	hasTimestamp := false
+	for index, target := range columns {
+		if index >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[index])
+		if value == "" {
+			continue
+		}
+
+		switch target {
+		case domain.ColumnTimestamp:
+			t, err := parseTimestamp(value, job.TimestampFormat, location)
+			if err != nil {
+				return reading, err
+			}
+			reading.Timestamp = t
+			hasTimestamp = true
+		case domain.ColumnDeviceID:
+			reading.DeviceID = value
+		default:
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				// Decimal commas are common in European exports
+				if v, err = strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64); err != nil {
+					return reading, fmt.Errorf("%s value %q is not a number", target, value)
+				}
+			}
+			reading.SetValue(target, v)
+		}
+	}
+
+	if !hasTimestamp {
+		return reading, errors.New("missing timestamp")
+	}
+	if reading.Timestamp.After(now) {
+		return reading, errors.New("timestamp is in the future")
+	}
+	if len(reading.MetricValues()) == 0 {
+		return reading, errors.New("no metric values")
+	}
+
+	if err := s.metrics.NormalizeReading(&reading, job.Units); err != nil {
+		return reading, err
+	}
+	if err := s.metrics.ValidateReading(reading); err != nil {
+		return reading, err
+	}
+
+	health, ok := s.farms.calculateHealthScore(reading, spec)
+	if !ok {
+		return reading, errors.New("row carries none of the metrics targeted by the crop specification")
+	}
+	reading.CropHealth = health
+	reading.ExpectedYield = s.farms.calculateExpectedYield(farm, health, spec)
+	return reading, nil
+}
+
+// resolveColumns maps column indexes to metric keys, timestamp or deviceId.
+// Without a mapping, columns named after a registered metric, timestamp or
+// deviceId are used.
+func resolveColumns(header []string, mapping map[string]string, metrics *MetricRegistry) (map[int]string, error) {
+	columns := make(map[int]string)
+	for i, name := range header {
+		if len(mapping) > 0 {
+			if target, ok := mapping[name]; ok {
+				columns[i] = target
+			}
+			continue
+		}
+		if name == domain.ColumnTimestamp || name == domain.ColumnDeviceID {
+			columns[i] = name
+		} else if _, ok := metrics.Get(name); ok {
+			columns[i] = name
+		}
+	}
+
+	for column := range mapping {
+		found := false
+		for _, name := range header {
+			found = found || name == column
+		}
+		if !found {
+			return nil, fmt.Errorf("file has no column %q", column)
+		}
+	}
+
+	hasTimestamp, hasMetric := false, false
+	for _, target := range columns {
+		hasTimestamp = hasTimestamp || target == domain.ColumnTimestamp
+		hasMetric = hasMetric || (target != domain.ColumnTimestamp && target != domain.ColumnDeviceID)
+	}
+	if !hasTimestamp {
+		return nil, errors.New("no column maps to the timestamp")
+	}
+	if !hasMetric {
+		return nil, errors.New("no column maps to a metric")
+	}
+	return columns, nil
+}
+
+// parseTimestamp parses a timestamp in the given layout, unix seconds or
+// milliseconds, or any of the common layouts. Timestamps without an offset
+// are taken to be in location.
+func parseTimestamp(value, layout string, location *time.Location) (time.Time, error) {
+	switch layout {
+	case "unix", "unixms":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("timestamp %q is not a number", value)
+		}
+		if layout == "unixms" {
+			return time.UnixMilli(int64(n)).UTC(), nil
+		}
+		return time.Unix(0, int64(n*1e9)).UTC(), nil
+	case "":
+		for _, l := range timestampLayouts {
+			if t, err := time.ParseInLocation(l, value, location); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unrecognised timestamp %q", value)
+	}
+
+	t, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp %q does not match %q", value, layout)
+	}
+	return t.UTC(), nil
+}
+
+// rowValues keys a row's values by column name for the rejection report
+func rowValues(header, record []string) map[string]string {
+	values := make(map[string]string, len(record))
+	for i, value := range record {
+		name := strconv.Itoa(i)
+		if i < len(header) {
+			name = header[i]
+		}
+		values[name] = value
+	}
+	return values
+}
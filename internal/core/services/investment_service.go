@@ -0,0 +1,233 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// InvestmentService records the money invested in farms and the ownership
+// it buys
+type InvestmentService struct {
+	db    ports.MongoDB
+	farms *FarmManagementSystemService
+	mu    sync.Mutex // serialises share reservations
+}
+
+// NewInvestmentService creates a new instance of the investment service
+func NewInvestmentService(db ports.MongoDB, farms *FarmManagementSystemService) *InvestmentService {
+	return &InvestmentService{db: db, farms: farms}
+}
+
+// SetOffering opens a farm to investment at a price per percentage point
+// of its shares
+func (s *InvestmentService) SetOffering(farmID string, offering domain.Offering) (*domain.Offering, error) {
+	if offering.Currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	if offering.PricePerShare <= 0 {
+		return nil, errors.New("price per share must be positive")
+	}
+	offering.UpdatedAt = time.Now()
+	if err := s.db.SetFarmOffering(farmID, &offering); err != nil {
+		return nil, err
+	}
+	return &offering, nil
+}
+
+// CloseOffering stops new investment in a farm
+func (s *InvestmentService) CloseOffering(farmID string) error {
+	return s.db.SetFarmOffering(farmID, nil)
+}
+
+// Invest records a pending investment in a farm, reserving the shares it
+// buys. The amount is the shares at the farm's offering price; an amount,
+// price or currency given by the investor must agree with it.
+func (s *InvestmentService) Invest(farmID string, investment domain.Investment) (*domain.Investment, error) {
+	if investment.InvestorID == "" {
+		return nil, errors.New("investor ID is required")
+	}
+	if investment.Shares <= 0 || investment.Shares > domain.SharesPerFarm {
+		return nil, errors.New("share size must be between 0 and 100 percent")
+	}
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+	offering := farm.Offering
+	if offering == nil {
+		return nil, errors.New("farm is not open to investment")
+	}
+	if investment.Currency != "" && investment.Currency != offering.Currency {
+		return nil, fmt.Errorf("farm is offered in %s", offering.Currency)
+	}
+	if investment.PricePerShare != 0 && investment.PricePerShare != offering.PricePerShare {
+		return nil, fmt.Errorf("farm is offered at %s %s per share", offering.PricePerShare, offering.Currency)
+	}
+	amount, err := offering.Amount(investment.Shares)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, errors.New("share size is too small to price")
+	}
+	if investment.Amount != 0 && investment.Amount != amount {
+		return nil, fmt.Errorf("%s of the farm costs %s %s", investment.Shares, amount, offering.Currency)
+	}
+	investment.Amount, investment.Currency, investment.PricePerShare = amount, offering.Currency, offering.PricePerShare
+	if investment.Address == "" {
+		// An investor with a single bound wallet needn't name it
+		wallets, err := s.db.RetrieveWallets(investment.InvestorID)
+		if err != nil {
+			return nil, err
+		}
+		if len(wallets) != 1 {
+			return nil, errors.New("address is required")
+		}
+		investment.Address = wallets[0].AccountID
+	}
+	if err := checkOwnerAddress(s.db, investment.Address); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	available, err := s.availableShares(farmID)
+	if err != nil {
+		return nil, err
+	}
+	if investment.Shares > available {
+		return nil, fmt.Errorf("only %s of the farm is available", available)
+	}
+
+	investment.FarmID = farmID
+	investment.Status = domain.InvestmentPending
+	investment.OwnerID = ""
+	investment.CreatedAt = time.Now()
+	investment.ConfirmedAt, investment.RefundedAt = nil, nil
+	if err := s.db.SaveInvestment(&investment); err != nil {
+		return nil, err
+	}
+	return &investment, nil
+}
+
+// Confirm marks a pending investment as paid and makes the investor an owner
+// of the farm
+func (s *InvestmentService) Confirm(id string) (*domain.Investment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	investment, err := s.db.RetrieveInvestment(id)
+	if err != nil {
+		return nil, err
+	}
+	if investment.Status != domain.InvestmentPending {
+		return nil, fmt.Errorf("investment is %s", investment.Status)
+	}
+
+	owner, err := s.farms.AddOwner(investment.FarmID, investment.Address, investment.Shares)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	investment.Status = domain.InvestmentConfirmed
+	investment.OwnerID = owner.ID
+	investment.ConfirmedAt = &now
+	if err := s.db.UpdateInvestment(investment); err != nil {
+		// Without the record the ownership would have no money behind it
+		if rollbackErr := s.farms.RemoveOwner(investment.FarmID, owner.ID); rollbackErr != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to remove owner %s after investment %s failed: %v", owner.ID, id, rollbackErr))
+		}
+		return nil, err
+	}
+	return investment, nil
+}
+
+// Refund returns an investment, releasing the shares it reserved or bought
+func (s *InvestmentService) Refund(id string) (*domain.Investment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	investment, err := s.db.RetrieveInvestment(id)
+	if err != nil {
+		return nil, err
+	}
+	switch investment.Status {
+	case domain.InvestmentRefunded:
+		return nil, errors.New("investment is already refunded")
+	case domain.InvestmentConfirmed:
+		farm, err := s.db.GetFarm(investment.FarmID)
+		if err != nil {
+			return nil, err
+		}
+		// Shares sold on since cannot be handed back
+		if owner := findOwner(farm, investment.OwnerID); owner == nil || owner.Shares != investment.Shares {
+			return nil, errors.New("the investment's shares have been transferred")
+		}
+		if err := s.farms.RemoveOwner(investment.FarmID, investment.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	investment.Status = domain.InvestmentRefunded
+	investment.RefundedAt = &now
+	if err := s.db.UpdateInvestment(investment); err != nil {
+		return nil, err
+	}
+	return investment, nil
+}
+
+// FarmInvestments retrieves the investments in a farm, newest first
+func (s *InvestmentService) FarmInvestments(farmID string) ([]domain.Investment, error) {
+	return s.list(farmID, "")
+}
+
+// InvestorInvestments retrieves the investments of an investor, newest first
+func (s *InvestmentService) InvestorInvestments(investorID string) ([]domain.Investment, error) {
+	return s.list("", investorID)
+}
+
+func (s *InvestmentService) list(farmID, investorID string) ([]domain.Investment, error) {
+	investments, err := s.db.RetrieveInvestments(farmID, investorID)
+	if err != nil {
+		return []domain.Investment{}, err
+	}
+	if investments == nil {
+		investments = []domain.Investment{}
+	}
+	return investments, nil
+}
+
+// availableShares returns the share units of a farm neither owned nor
+// reserved by pending investments
+func (s *InvestmentService) availableShares(farmID string) (domain.Shares, error) {
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return 0, err
+	}
+	var taken domain.Shares
+	for _, owner := range farm.Owners {
+		taken += owner.Shares
+	}
+
+	investments, err := s.db.RetrieveInvestments(farmID, "")
+	if err != nil {
+		return 0, err
+	}
+	for _, investment := range investments {
+		if investment.Status == domain.InvestmentPending {
+			taken += investment.Shares
+		}
+	}
+	if taken > domain.SharesPerFarm {
+		return 0, nil
+	}
+	return domain.SharesPerFarm - taken, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/pkg/money"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+)
+
+// newTestInvestments returns an investment service for a farm offered at
+// 50 XLM per percent, and an investor with a single bound wallet
+func newTestInvestments(t *testing.T) (*InvestmentService, *memoryDB, string) {
+	t.Helper()
+	db := newMemoryDB()
+	db.putFarm(domain.VerticalFarm{ID: "farm-1"})
+	investorID := db.putUser("investor")
+	if err := db.SaveWallet(&domain.Wallet{UserID: investorID, AccountID: keypair.MustRandom().Address()}); err != nil {
+		t.Fatal(err)
+	}
+	investments := NewInvestmentService(db, NewFarmManagementSystemService(db, nil))
+	if _, err := investments.SetOffering("farm-1", domain.Offering{Currency: "XLM", PricePerShare: money.MustParse("50")}); err != nil {
+		t.Fatal(err)
+	}
+	return investments, db, investorID
+}
+
+func TestInvestPricesFromOffering(t *testing.T) {
+	investments, _, investorID := newTestInvestments(t)
+
+	investment, err := investments.Invest("farm-1", domain.Investment{InvestorID: investorID, Shares: 25_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if investment.Amount != money.MustParse("125") || investment.PricePerShare != money.MustParse("50") || investment.Currency != "XLM" {
+		t.Errorf("investment = %s %s at %s, want 125 XLM at 50", investment.Amount, investment.Currency, investment.PricePerShare)
+	}
+
+	// Terms agreeing with the offering are accepted
+	if _, err := investments.Invest("farm-1", domain.Investment{
+		InvestorID: investorID, Shares: 10_000, Amount: money.MustParse("50"), PricePerShare: money.MustParse("50"), Currency: "XLM",
+	}); err != nil {
+		t.Errorf("matching terms: %v", err)
+	}
+}
+
+func TestInvestRejectsOwnTerms(t *testing.T) {
+	investments, db, investorID := newTestInvestments(t)
+
+	for name, investment := range map[string]domain.Investment{
+		"cheaper amount": {Amount: money.MustParse("0.0000001")},
+		"cheaper price":  {PricePerShare: money.MustParse("1")},
+		"other currency": {Currency: "USDC"},
+	} {
+		investment.InvestorID, investment.Shares = investorID, domain.SharesPerFarm
+		if _, err := investments.Invest("farm-1", investment); err == nil {
+			t.Errorf("%s: investment accepted", name)
+		}
+	}
+
+	if err := investments.CloseOffering("farm-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := investments.Invest("farm-1", domain.Investment{InvestorID: investorID, Shares: 10_000}); err == nil {
+		t.Error("investment accepted without an offering")
+	}
+	if len(db.investments) != 0 {
+		t.Errorf("recorded %d investments, want none", len(db.investments))
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/lorawan"
+	"0xFarms-backend/internal/ports"
+	"fmt"
+)
+
+// LoRaWANService ingests sensor uplinks forwarded by a LoRaWAN network server
+type LoRaWANService struct {
+	db    ports.MongoDB
+	farms *FarmManagementSystemService
+}
+
+// NewLoRaWANService creates a new instance of the LoRaWAN service
+func NewLoRaWANService(db ports.MongoDB, farms *FarmManagementSystemService) *LoRaWANService {
+	return &LoRaWANService{db: db, farms: farms}
+}
+
+// HandleUplink decodes an uplink webhook with the decoder of the device it
+// came from and adds the reading to the device's farm. It returns
+// lorawan.ErrNotUplink for messages that carry no sensor data.
+func (s *LoRaWANService) HandleUplink(body []byte) (*domain.IoTReading, error) {
+	uplink, err := lorawan.ParseUplink(body)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := s.db.RetrieveDeviceByEUI(uplink.DevEUI)
+	if err != nil {
+		return nil, fmt.Errorf("device %s: %v", uplink.DevEUI, err)
+	}
+	decoder, ok := lorawan.Lookup(device.Decoder)
+	if !ok {
+		return nil, fmt.Errorf("device %s has no payload decoder", uplink.DevEUI)
+	}
+
+	measurements, err := decoder.Decode(uplink.FPort, uplink.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("device %s: %v", uplink.DevEUI, err)
+	}
+	reading, err := lorawan.ToReading(measurements, device.ChannelMetrics, uplink.ReceivedAt)
+	if err != nil {
+		return nil, fmt.Errorf("device %s: %v", uplink.DevEUI, err)
+	}
+	reading.DeviceID = device.ID.Hex()
+
+	if err := s.farms.AddIoTReading(device.FarmID, reading); err != nil {
+		return nil, err
+	}
+	return &reading, nil
+}
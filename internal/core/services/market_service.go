@@ -0,0 +1,581 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/logger"
+	"0xFarms-backend/pkg/money"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxMatchesPerOrder bounds the trades one incoming order executes, so a
+// book of many small orders cannot hold a request indefinitely
+const maxMatchesPerOrder = 100
+
+// errAskUnfillable marks an ask whose seller no longer holds the shares
+var errAskUnfillable = errors.New("seller no longer holds the shares offered")
+
+// MarketService runs the secondary market in which owners transfer farm
+// shares, directly or through a per-farm order book. Matched orders reserve
+// the seller's shares until the buyer pays the seller on the ledger. Shares
+// move in database transactions so both owners change together.
+type MarketService struct {
+	db      ports.MongoDB
+	ledger  stellar.Ledger
+	issuers map[string]string // accepted issuer of each currency but XLM
+	lockup  time.Duration     // how long owners hold new shares before selling
+	mu      sync.Mutex        // serialises matching
+}
+
+// NewMarketService creates a new instance of the market service. Trades are
+// paid for in XLM or the assets of issuers. Owners cannot sell or transfer
+// shares until lockup has passed since they got them.
+func NewMarketService(db ports.MongoDB, ledger stellar.Ledger, issuers map[string]string, lockup time.Duration) *MarketService {
+	return &MarketService{db: db, ledger: ledger, issuers: issuers, lockup: lockup}
+}
+
+// PlaceOrder adds an order to the farm's book and matches it against the
+// other side. The session must control the selling owner's wallet for asks
+// and the receiving address for bids. It returns the order as it stands
+// after matching and the trades it took part in.
+func (s *MarketService) PlaceOrder(session *domain.AuthSession, farmID string, order domain.ShareOrder) (*domain.ShareOrder, []domain.ShareTrade, error) {
+	if order.Shares <= 0 || order.Shares > domain.SharesPerFarm {
+		return nil, nil, errors.New("share size must be between 0 and 100 percent")
+	}
+	if order.PricePerShare <= 0 {
+		return nil, nil, errors.New("price per share must be positive")
+	}
+	if order.Currency == "" {
+		return nil, nil, errors.New("currency is required")
+	}
+	if _, err := stellar.PaymentAsset(order.Currency, s.issuers[order.Currency]); err != nil {
+		return nil, nil, fmt.Errorf("%s payments are not accepted: %v", order.Currency, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if farm.Status == domain.FarmSettling {
+		return nil, nil, errors.New("trading is halted while the farm is settling")
+	}
+
+	switch order.Side {
+	case domain.OrderAsk:
+		owner := findOwner(farm, order.OwnerID)
+		if owner == nil {
+			return nil, nil, errors.New("owner not found")
+		}
+		if err := checkActsFor(s.db, session, owner.Address); err != nil {
+			return nil, nil, err
+		}
+		if err := s.checkLockup(*owner, time.Now()); err != nil {
+			return nil, nil, err
+		}
+		listed, err := s.reservedShares(farmID, owner.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if order.Shares > owner.Shares-listed {
+			return nil, nil, fmt.Errorf("owner has only %s of the farm unlisted", owner.Shares-listed)
+		}
+		order.Address = owner.Address
+	case domain.OrderBid:
+		if order.Address == "" {
+			return nil, nil, errors.New("address is required")
+		}
+		if err := checkOwnerAddress(s.db, order.Address); err != nil {
+			return nil, nil, err
+		}
+		if err := checkActsFor(s.db, session, order.Address); err != nil {
+			return nil, nil, err
+		}
+		order.OwnerID = ""
+	default:
+		return nil, nil, fmt.Errorf("side must be %s or %s", domain.OrderAsk, domain.OrderBid)
+	}
+
+	now := time.Now()
+	order.FarmID = farmID
+	order.Remaining = order.Shares
+	order.Status = domain.OrderOpen
+	order.CreatedAt, order.UpdatedAt = now, now
+	if err := s.db.SaveShareOrder(&order); err != nil {
+		return nil, nil, err
+	}
+
+	trades, err := s.match(farmID)
+	if err != nil {
+		// Left on the book the order would cross again and fail every later
+		// match; trades it made before the failure stand
+		if _, cancelErr := s.cancelOrder(order.ID.Hex()); cancelErr != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to cancel unmatched order %s: %v", order.ID.Hex(), cancelErr))
+		}
+		return nil, nil, err
+	}
+	placed, err := s.db.RetrieveShareOrder(order.ID.Hex())
+	if err != nil {
+		return nil, nil, err
+	}
+	return placed, trades, nil
+}
+
+// CancelOrder takes an open order off the book, for a session controlling
+// the order's address
+func (s *MarketService) CancelOrder(session *domain.AuthSession, id string) (*domain.ShareOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.db.RetrieveShareOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkActsFor(s.db, session, order.Address); err != nil {
+		return nil, err
+	}
+	return s.cancelOrder(id)
+}
+
+// cancelOrder takes an open order off the book. Callers must hold s.mu.
+func (s *MarketService) cancelOrder(id string) (*domain.ShareOrder, error) {
+	order, err := s.db.RetrieveShareOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderOpen {
+		return nil, fmt.Errorf("order is %s", order.Status)
+	}
+
+	order.Status = domain.OrderCancelled
+	order.UpdatedAt = time.Now()
+	if err := s.db.UpdateShareOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Transfer moves shares from an owner to an address without payment, such
+// as a gift or an off-market sale. The session must control the owner's
+// wallet. It is recorded as a trade at no price.
+func (s *MarketService) Transfer(session *domain.AuthSession, farmID, ownerID, toAddress string, shares domain.Shares) (*domain.ShareTrade, error) {
+	if toAddress == "" {
+		return nil, errors.New("address is required")
+	}
+	if shares <= 0 {
+		return nil, errors.New("share size must be positive")
+	}
+	if err := checkOwnerAddress(s.db, toAddress); err != nil {
+		return nil, err
+	}
+
+	// Owner entries keep their address, so it can be checked outside the
+	// trade's transaction
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+	owner := findOwner(farm, ownerID)
+	if owner == nil {
+		return nil, errors.New("owner not found")
+	}
+	if err := checkActsFor(s.db, session, owner.Address); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Shares listed for sale stay with the owner until the ask is cancelled,
+	// and shares sold stay until the trade settles or is cancelled
+	listed, err := s.reservedShares(farmID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.ExecuteShareTrade(farmID, nil, func(farm *domain.VerticalFarm, _ []*domain.ShareOrder) (*domain.ShareTrade, error) {
+		owner := findOwner(farm, ownerID)
+		if owner == nil {
+			return nil, errors.New("owner not found")
+		}
+		if shares > owner.Shares-listed {
+			return nil, fmt.Errorf("owner has only %s of the farm unlisted", owner.Shares-listed)
+		}
+		now := time.Now()
+		seller, err := s.seller(farm, ownerID, toAddress, shares, now)
+		if err != nil {
+			return nil, err
+		}
+		trade := &domain.ShareTrade{
+			FarmID:        farm.ID,
+			SellerOwnerID: seller.ID,
+			SellerAddress: seller.Address,
+			BuyerAddress:  toAddress,
+			Shares:        shares,
+			Status:        domain.TradeSettled,
+			ExecutedAt:    now,
+			SettledAt:     &now,
+		}
+		trade.BuyerOwnerID = moveShares(farm, seller, toAddress, shares, now)
+		return trade, nil
+	})
+}
+
+// PaymentTransaction builds the payment of a pending trade from the account
+// at address to the seller, for the account holder to sign and submit. The
+// trade ID goes in the memo; the payment's operation ID then settles it.
+func (s *MarketService) PaymentTransaction(tradeID, address string) (string, error) {
+	trade, err := s.db.RetrieveShareTrade(tradeID)
+	if err != nil {
+		return "", err
+	}
+	if trade.Status != domain.TradePending {
+		return "", fmt.Errorf("trade is %s", trade.Status)
+	}
+
+	asset, err := stellar.PaymentAsset(trade.Currency, s.issuers[trade.Currency])
+	if err != nil {
+		return "", fmt.Errorf("%s payments are not accepted: %v", trade.Currency, err)
+	}
+	seller, err := stellar.AccountID(trade.SellerAddress)
+	if err != nil {
+		return "", err
+	}
+	account, err := s.ledger.Account(address)
+	if err != nil {
+		return "", fmt.Errorf("account %s: %v", address, err)
+	}
+
+	tx, err := stellar.NewPurchasePayment(account, seller, asset, trade.Amount, trade.ID.Hex())
+	if err != nil {
+		return "", err
+	}
+	return tx.Base64()
+}
+
+// SettleTrade moves the shares of a pending trade to the buyer once the
+// ledger payment with the operation ID has paid the seller for them. The
+// payment must name the trade in its memo and pay at least the trade's
+// amount in its currency.
+func (s *MarketService) SettleTrade(tradeID, paymentID string) (*domain.ShareTrade, error) {
+	trade, err := s.db.RetrieveShareTrade(tradeID)
+	if err != nil {
+		return nil, err
+	}
+	if trade.Status != domain.TradePending {
+		return nil, fmt.Errorf("trade is %s", trade.Status)
+	}
+	p, err := s.ledger.Payment(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("payment %s: %v", paymentID, err)
+	}
+	if err := s.checkPayment(trade, p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.UpdateShareTrade(tradeID, func(farm *domain.VerticalFarm, trade *domain.ShareTrade) error {
+		if trade.Status != domain.TradePending {
+			return fmt.Errorf("trade is %s", trade.Status)
+		}
+		if farm.Status == domain.FarmSettling {
+			return errors.New("trading is halted while the farm is settling")
+		}
+		seller := findOwner(farm, trade.SellerOwnerID)
+		if seller == nil || seller.Shares < trade.Shares {
+			return errors.New("seller no longer holds the shares traded")
+		}
+
+		now := time.Now()
+		trade.BuyerOwnerID = moveShares(farm, seller, trade.BuyerAddress, trade.Shares, now)
+		trade.Status = domain.TradeSettled
+		trade.PaymentID = p.ID
+		trade.SettledAt = &now
+		return nil
+	})
+}
+
+// CancelTrade cancels a pending trade the buyer has not paid for, releasing
+// the seller's shares. The orders it filled stay filled.
+func (s *MarketService) CancelTrade(tradeID string) (*domain.ShareTrade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.UpdateShareTrade(tradeID, func(_ *domain.VerticalFarm, trade *domain.ShareTrade) error {
+		if trade.Status != domain.TradePending {
+			return fmt.Errorf("trade is %s", trade.Status)
+		}
+		trade.Status = domain.TradeCancelled
+		return nil
+	})
+}
+
+// OrderBook returns the open orders of a farm, asks cheapest first and bids
+// highest first, earlier orders first at the same price
+func (s *MarketService) OrderBook(farmID string) (*domain.OrderBook, error) {
+	orders, err := s.db.RetrieveShareOrders(farmID, domain.OrderOpen)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &domain.OrderBook{FarmID: farmID, Asks: []domain.ShareOrder{}, Bids: []domain.ShareOrder{}}
+	for _, order := range orders {
+		if order.Side == domain.OrderAsk {
+			book.Asks = append(book.Asks, order)
+		} else {
+			book.Bids = append(book.Bids, order)
+		}
+	}
+	sortByPriority(book.Asks, book.Bids)
+	return book, nil
+}
+
+// Trades returns the trades of a farm, newest first
+func (s *MarketService) Trades(farmID string) ([]domain.ShareTrade, error) {
+	trades, err := s.db.RetrieveShareTrades(farmID)
+	if err != nil {
+		return []domain.ShareTrade{}, err
+	}
+	if trades == nil {
+		trades = []domain.ShareTrade{}
+	}
+	return trades, nil
+}
+
+// match executes trades between crossing orders of the farm until the best
+// bid is below the best ask. Trades execute at the price of the order that
+// was on the book first.
+func (s *MarketService) match(farmID string) ([]domain.ShareTrade, error) {
+	trades := make([]domain.ShareTrade, 0)
+	for len(trades) < maxMatchesPerOrder {
+		orders, err := s.db.RetrieveShareOrders(farmID, domain.OrderOpen)
+		if err != nil {
+			return trades, err
+		}
+		var asks, bids []domain.ShareOrder
+		for _, order := range orders {
+			if order.Side == domain.OrderAsk {
+				asks = append(asks, order)
+			} else {
+				bids = append(bids, order)
+			}
+		}
+		sortByPriority(asks, bids)
+
+		ask, bid := crossingPair(asks, bids)
+		if ask == nil {
+			return trades, nil
+		}
+
+		trade, err := s.db.ExecuteShareTrade(farmID, []string{ask.ID.Hex(), bid.ID.Hex()}, func(farm *domain.VerticalFarm, orders []*domain.ShareOrder) (*domain.ShareTrade, error) {
+			return s.fill(farm, orders[0], orders[1], time.Now())
+		})
+		if errors.Is(err, errAskUnfillable) {
+			// The seller sold or lost the shares some other way
+			logger.LogWarning(fmt.Sprintf("Cancelling ask %s: %v", ask.ID.Hex(), err))
+			ask.Status = domain.OrderCancelled
+			ask.UpdatedAt = time.Now()
+			if err := s.db.UpdateShareOrder(ask); err != nil {
+				return trades, err
+			}
+			continue
+		}
+		if err != nil {
+			return trades, err
+		}
+		trades = append(trades, *trade)
+	}
+	return trades, nil
+}
+
+// fill executes as much of a crossing ask and bid as both allow, on the
+// state read inside the trade's transaction. The trade is pending: the
+// shares stay with the seller, reserved, until the buyer pays.
+func (s *MarketService) fill(farm *domain.VerticalFarm, ask, bid *domain.ShareOrder, now time.Time) (*domain.ShareTrade, error) {
+	if ask.Status != domain.OrderOpen || bid.Status != domain.OrderOpen {
+		return nil, errors.New("order is no longer open")
+	}
+	quantity := ask.Remaining
+	if bid.Remaining < quantity {
+		quantity = bid.Remaining
+	}
+	price := ask.PricePerShare
+	if bid.CreatedAt.Before(ask.CreatedAt) {
+		price = bid.PricePerShare
+	}
+
+	seller, err := s.seller(farm, ask.OwnerID, bid.Address, quantity, now)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := price.MulDiv(int64(quantity), int64(domain.SharesPerPercent))
+	if err != nil {
+		return nil, err
+	}
+	trade := &domain.ShareTrade{
+		FarmID:        farm.ID,
+		AskID:         ask.ID.Hex(),
+		BidID:         bid.ID.Hex(),
+		SellerOwnerID: seller.ID,
+		SellerAddress: seller.Address,
+		BuyerAddress:  bid.Address,
+		Shares:        quantity,
+		PricePerShare: price,
+		Amount:        amount,
+		Currency:      ask.Currency,
+		Status:        domain.TradePending,
+		ExecutedAt:    now,
+	}
+
+	for _, order := range []*domain.ShareOrder{ask, bid} {
+		order.Remaining -= quantity
+		if order.Remaining == 0 {
+			order.Status = domain.OrderFilled
+		}
+		order.UpdatedAt = now
+	}
+	return trade, nil
+}
+
+// seller returns the owner entry shares are sold or transferred from,
+// rejecting sales the owner cannot make
+func (s *MarketService) seller(farm *domain.VerticalFarm, ownerID, toAddress string, shares domain.Shares, now time.Time) (*domain.Owner, error) {
+	if farm.Status == domain.FarmSettling {
+		return nil, errors.New("trading is halted while the farm is settling")
+	}
+	seller := findOwner(farm, ownerID)
+	if seller == nil || seller.Shares < shares {
+		return nil, errAskUnfillable
+	}
+	if err := s.checkLockup(*seller, now); err != nil {
+		return nil, err
+	}
+	if seller.Address == toAddress {
+		return nil, errors.New("cannot transfer shares to the same address")
+	}
+	return seller, nil
+}
+
+// moveShares moves shares from the seller's owner entry to a new owner entry
+// for the address, removing the seller's entry once it is empty, and returns
+// the new entry's ID. The buyer's lockup starts with the new entry.
+func moveShares(farm *domain.VerticalFarm, seller *domain.Owner, toAddress string, shares domain.Shares, now time.Time) string {
+	buyerID := uuid.New().String()
+	seller.Shares -= shares
+	owners := make([]domain.Owner, 0, len(farm.Owners)+1)
+	for _, owner := range farm.Owners {
+		if owner.Shares > 0 {
+			owners = append(owners, owner)
+		}
+	}
+	farm.Owners = append(owners, domain.Owner{
+		ID:       buyerID,
+		Address:  toAddress,
+		Shares:   shares,
+		JoinedAt: now,
+	})
+	return buyerID
+}
+
+// checkLockup rejects sales by owners who got their shares too recently
+func (s *MarketService) checkLockup(owner domain.Owner, now time.Time) error {
+	if until := owner.JoinedAt.Add(s.lockup); now.Before(until) {
+		return fmt.Errorf("shares are locked up until %s", until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// reservedShares returns the shares of an owner offered by open asks or
+// sold by trades not yet paid for
+func (s *MarketService) reservedShares(farmID, ownerID string) (domain.Shares, error) {
+	orders, err := s.db.RetrieveShareOrders(farmID, domain.OrderOpen)
+	if err != nil {
+		return 0, err
+	}
+	var reserved domain.Shares
+	for _, order := range orders {
+		if order.Side == domain.OrderAsk && order.OwnerID == ownerID {
+			reserved += order.Remaining
+		}
+	}
+
+	trades, err := s.db.RetrieveShareTrades(farmID)
+	if err != nil {
+		return 0, err
+	}
+	for _, trade := range trades {
+		if trade.Status == domain.TradePending && trade.SellerOwnerID == ownerID {
+			reserved += trade.Shares
+		}
+	}
+	return reserved, nil
+}
+
+// checkPayment rejects ledger payments that do not pay the seller of a
+// trade for it
+func (s *MarketService) checkPayment(trade *domain.ShareTrade, p *stellar.Payment) error {
+	if p.Memo != trade.ID.Hex() {
+		return errors.New("payment memo does not name the trade")
+	}
+	seller, err := stellar.AccountID(trade.SellerAddress)
+	if err != nil {
+		return err
+	}
+	if p.To != seller {
+		return errors.New("payment is not to the seller")
+	}
+	if reason := assetMismatch(s.issuers, trade.Currency, p.AssetCode, p.AssetIssuer); reason != "" {
+		return errors.New(reason)
+	}
+	amount, err := money.Parse(p.Amount)
+	if err != nil {
+		return fmt.Errorf("payment %s: %v", p.ID, err)
+	}
+	if amount < trade.Amount {
+		return fmt.Errorf("paid %s of %s %s", amount, trade.Amount, trade.Currency)
+	}
+	return nil
+}
+
+// crossingPair returns the best ask and bid that trade with each other,
+// skipping pairs from the same address
+func crossingPair(asks, bids []domain.ShareOrder) (*domain.ShareOrder, *domain.ShareOrder) {
+	for i := range bids {
+		for j := range asks {
+			if asks[j].PricePerShare > bids[i].PricePerShare {
+				break
+			}
+			if asks[j].Address != bids[i].Address && asks[j].Currency == bids[i].Currency {
+				return &asks[j], &bids[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// sortByPriority orders asks cheapest first and bids highest first, keeping
+// the oldest first at the same price
+func sortByPriority(asks, bids []domain.ShareOrder) {
+	sort.SliceStable(asks, func(i, j int) bool { return asks[i].PricePerShare < asks[j].PricePerShare })
+	sort.SliceStable(bids, func(i, j int) bool { return bids[i].PricePerShare > bids[j].PricePerShare })
+}
+
+// findOwner returns the farm's owner entry with the ID
+func findOwner(farm *domain.VerticalFarm, ownerID string) *domain.Owner {
+	for i := range farm.Owners {
+		if farm.Owners[i].ID == ownerID {
+			return &farm.Owners[i]
+		}
+	}
+	return nil
+}
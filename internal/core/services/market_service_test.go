@@ -0,0 +1,143 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/money"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// newTestMarket returns a market for farm-1, whose single owner owner-1 is
+// the seller's account holding 10 percent, and sessions for the seller and
+// buyer, both with bound wallets
+func newTestMarket(t *testing.T, ledger *stellar.MemoryLedger, seller, buyer *keypair.Full) (*MarketService, *memoryDB, *domain.AuthSession, *domain.AuthSession) {
+	t.Helper()
+	db := newMemoryDB()
+	db.putFarm(domain.VerticalFarm{ID: "farm-1", Owners: []domain.Owner{
+		{ID: "owner-1", Address: seller.Address(), Shares: 100_000, JoinedAt: time.Now().Add(-time.Hour)},
+	}})
+	for _, key := range []*keypair.Full{seller, buyer} {
+		if err := db.SaveWallet(&domain.Wallet{UserID: db.putUser(key.Address()), AccountID: key.Address()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	market := NewMarketService(db, ledger, nil, 0)
+	return market, db, &domain.AuthSession{AccountID: seller.Address()}, &domain.AuthSession{AccountID: buyer.Address()}
+}
+
+// matchTrade crosses an ask of the seller's owner entry with a bid of the
+// buyer and returns the trade they make
+func matchTrade(t *testing.T, market *MarketService, sellerSession, buyerSession *domain.AuthSession, shares domain.Shares) domain.ShareTrade {
+	t.Helper()
+	price := money.MustParse("10")
+	if _, _, err := market.PlaceOrder(sellerSession, "farm-1", domain.ShareOrder{
+		Side: domain.OrderAsk, OwnerID: "owner-1", Shares: shares, PricePerShare: price, Currency: "XLM",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_, trades, err := market.PlaceOrder(buyerSession, "farm-1", domain.ShareOrder{
+		Side: domain.OrderBid, Address: buyerSession.AccountID, Shares: shares, PricePerShare: price, Currency: "XLM",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("orders made %d trades, want 1", len(trades))
+	}
+	return trades[0]
+}
+
+// payTrade pays the seller of a trade from the key's account and returns
+// the payment's operation ID
+func payTrade(t *testing.T, ledger *stellar.MemoryLedger, from *keypair.Full, trade domain.ShareTrade, amount money.Amount) string {
+	t.Helper()
+	account, err := ledger.Account(from.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.NewPurchasePayment(account, trade.SellerAddress, txnbuild.NativeAsset{}, amount, trade.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, from); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.Submit(tx); err != nil {
+		t.Fatal(err)
+	}
+	payments, err := ledger.Payments(trade.SellerAddress, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payments[len(payments)-1].ID
+}
+
+func TestMatchedTradeSettlesOnPayment(t *testing.T) {
+	seller, buyer := keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, seller, buyer)
+	market, db, sellerSession, buyerSession := newTestMarket(t, ledger, seller, buyer)
+
+	trade := matchTrade(t, market, sellerSession, buyerSession, 20_000)
+	if trade.Status != domain.TradePending || trade.Amount != money.MustParse("20") {
+		t.Fatalf("trade is %s for %s, want pending for 20", trade.Status, trade.Amount)
+	}
+	farm, _ := db.GetFarm("farm-1")
+	if len(farm.Owners) != 1 || farm.Owners[0].Shares != 100_000 {
+		t.Fatalf("owners before payment = %+v, want the seller's 10%% untouched", farm.Owners)
+	}
+	// The sold shares are reserved for the buyer until the trade settles
+	if _, err := market.Transfer(sellerSession, "farm-1", "owner-1", buyer.Address(), 90_000); err == nil {
+		t.Error("seller transferred shares reserved by a pending trade")
+	}
+
+	if _, err := market.SettleTrade(trade.ID.Hex(), pay(t, ledger, buyer, seller, "20")); err == nil {
+		t.Error("payment without the trade's memo settled it")
+	}
+	if _, err := market.SettleTrade(trade.ID.Hex(), payTrade(t, ledger, buyer, trade, money.MustParse("5"))); err == nil {
+		t.Error("underpayment settled the trade")
+	}
+
+	paymentID := payTrade(t, ledger, buyer, trade, trade.Amount)
+	settled, err := market.SettleTrade(trade.ID.Hex(), paymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settled.Status != domain.TradeSettled || settled.PaymentID != paymentID || settled.BuyerOwnerID == "" {
+		t.Errorf("settled trade = %+v", settled)
+	}
+	farm, _ = db.GetFarm("farm-1")
+	if len(farm.Owners) != 2 || farm.Owners[0].Shares != 80_000 ||
+		farm.Owners[1].Address != buyer.Address() || farm.Owners[1].Shares != 20_000 {
+		t.Errorf("owners after payment = %+v, want 8%% to the seller and 2%% to the buyer", farm.Owners)
+	}
+	if _, err := market.SettleTrade(trade.ID.Hex(), paymentID); err == nil {
+		t.Error("trade settled twice")
+	}
+}
+
+func TestCancelTradeReleasesShares(t *testing.T) {
+	seller, buyer := keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, seller, buyer)
+	market, db, sellerSession, buyerSession := newTestMarket(t, ledger, seller, buyer)
+
+	trade := matchTrade(t, market, sellerSession, buyerSession, 20_000)
+	if _, err := market.CancelTrade(trade.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := market.SettleTrade(trade.ID.Hex(), payTrade(t, ledger, buyer, trade, trade.Amount)); err == nil {
+		t.Error("cancelled trade settled")
+	}
+
+	if _, err := market.Transfer(sellerSession, "farm-1", "owner-1", buyer.Address(), 100_000); err != nil {
+		t.Errorf("shares of the cancelled trade are still reserved: %v", err)
+	}
+	farm, _ := db.GetFarm("farm-1")
+	if len(farm.Owners) != 1 || farm.Owners[0].Address != buyer.Address() {
+		t.Errorf("owners = %+v, want the buyer alone", farm.Owners)
+	}
+}
@@ -0,0 +1,726 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryDB keeps the records the service tests need in memory. Operations a
+// test does not use fall through to the nil ports.MongoDB and panic.
+type memoryDB struct {
+	ports.MongoDB
+
+	mu       sync.Mutex
+	devices  map[string]domain.Device
+	commands map[string]domain.ActuatorCommand
+	farms    map[string]domain.VerticalFarm
+	tokens   map[string]domain.FarmToken
+	users    map[string]domain.OrdinaryUser
+	wallets  map[string]domain.Wallet // by account ID
+	// challenges holds wallet challenges by user and account ID
+	challenges map[[2]string]domain.WalletChallenge
+	sessions   map[string]domain.AuthSession // by token hash
+
+	distributions map[string]domain.Distribution
+	payouts       map[string]domain.Payout
+	investments   map[string]domain.Investment
+	payments      map[string]domain.IncomingPayment // by ledger operation ID
+	cursors       map[string]string
+	orders        map[string]domain.ShareOrder
+	trades        map[string]domain.ShareTrade
+	decisions     []domain.ControlDecision // in the order they were made
+	specs         map[string]domain.CropSpecification
+	dosing        []domain.DosingRecommendation
+	archive       map[string][]domain.IoTReading // archived readings by farm ID
+	rollups       []domain.ReadingRollup
+	harvests      map[string]domain.HarvestRecord
+	events        []domain.OwnershipEvent // in the order they occurred
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{
+		devices:  make(map[string]domain.Device),
+		commands: make(map[string]domain.ActuatorCommand),
+		farms:    make(map[string]domain.VerticalFarm),
+		tokens:   make(map[string]domain.FarmToken),
+		users:    make(map[string]domain.OrdinaryUser),
+		wallets:  make(map[string]domain.Wallet),
+
+		challenges: make(map[[2]string]domain.WalletChallenge),
+		sessions:   make(map[string]domain.AuthSession),
+
+		distributions: make(map[string]domain.Distribution),
+		payouts:       make(map[string]domain.Payout),
+		investments:   make(map[string]domain.Investment),
+		payments:      make(map[string]domain.IncomingPayment),
+		cursors:       make(map[string]string),
+		orders:        make(map[string]domain.ShareOrder),
+		trades:        make(map[string]domain.ShareTrade),
+		specs:         make(map[string]domain.CropSpecification),
+		archive:       make(map[string][]domain.IoTReading),
+		harvests:      make(map[string]domain.HarvestRecord),
+	}
+}
+
+// putFarm stores a farm as it stands, replacing any farm with its ID
+func (db *memoryDB) putFarm(farm domain.VerticalFarm) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	farm.Owners = append([]domain.Owner(nil), farm.Owners...)
+	db.farms[farm.ID] = farm
+}
+
+func (db *memoryDB) GetFarm(id string) (*domain.VerticalFarm, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	farm, ok := db.farms[id]
+	if !ok {
+		return nil, errors.New("farm not found")
+	}
+	farm.Owners = append([]domain.Owner(nil), farm.Owners...)
+	return &farm, nil
+}
+
+// UpdateFarmOwners changes a farm's owners, keeping them only if change
+// succeeds
+func (db *memoryDB) UpdateFarmOwners(farmID string, change func(farm *domain.VerticalFarm) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	farm, ok := db.farms[farmID]
+	if !ok {
+		return errors.New("farm not found")
+	}
+	farm.Owners = append([]domain.Owner(nil), farm.Owners...)
+	if err := change(&farm); err != nil {
+		return err
+	}
+	db.farms[farmID] = farm
+	return nil
+}
+
+func (db *memoryDB) RetrieveReadings(farmID string, from, to time.Time) ([]domain.IoTReading, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var readings []domain.IoTReading
+	for _, reading := range db.archive[farmID] {
+		if !reading.Timestamp.Before(from) && reading.Timestamp.Before(to) {
+			readings = append(readings, reading)
+		}
+	}
+	return readings, nil
+}
+
+func (db *memoryDB) RemoveRollups(farmID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	kept := db.rollups[:0]
+	for _, rollup := range db.rollups {
+		if rollup.FarmID != farmID {
+			kept = append(kept, rollup)
+		}
+	}
+	db.rollups = kept
+	return nil
+}
+
+// MergeRollups stores the rollups as they are; tests merge each bucket once
+func (db *memoryDB) MergeRollups(rollups []domain.ReadingRollup) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rollups = append(db.rollups, rollups...)
+	return nil
+}
+
+func (db *memoryDB) SaveFarmToken(token *domain.FarmToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, stored := range db.tokens {
+		if stored.FarmID == token.FarmID {
+			return errors.New("farm is already tokenized")
+		}
+		if stored.AssetCode == token.AssetCode {
+			return errors.New("asset code is already in use")
+		}
+	}
+	token.ID = primitive.NewObjectID()
+	db.tokens[token.FarmID] = *token
+	return nil
+}
+
+func (db *memoryDB) UpdateFarmToken(token *domain.FarmToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.tokens[token.FarmID] = *token
+	return nil
+}
+
+func (db *memoryDB) RetrieveFarmToken(farmID string) (*domain.FarmToken, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	token, ok := db.tokens[farmID]
+	if !ok {
+		return nil, errors.New("farm is not tokenized")
+	}
+	return &token, nil
+}
+
+func (db *memoryDB) RetrieveFarmTokens() ([]domain.FarmToken, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	tokens := []domain.FarmToken{}
+	for _, token := range db.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (db *memoryDB) SaveDevice(device *domain.Device) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if device.ID.IsZero() {
+		device.ID = primitive.NewObjectID()
+	}
+	db.devices[device.ID.Hex()] = *device
+	return nil
+}
+
+func (db *memoryDB) RetrieveDevice(id string) (*domain.Device, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	device, ok := db.devices[id]
+	if !ok {
+		return nil, errors.New("device not found")
+	}
+	return &device, nil
+}
+
+func (db *memoryDB) SaveCommand(command *domain.ActuatorCommand) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	command.ID = primitive.NewObjectID()
+	db.commands[command.ID.Hex()] = *command
+	return nil
+}
+
+func (db *memoryDB) UpdateCommand(command *domain.ActuatorCommand) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.commands[command.ID.Hex()]; !ok {
+		return errors.New("command not found")
+	}
+	db.commands[command.ID.Hex()] = *command
+	return nil
+}
+
+func (db *memoryDB) RetrieveCommand(id string) (*domain.ActuatorCommand, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	command, ok := db.commands[id]
+	if !ok {
+		return nil, errors.New("command not found")
+	}
+	return &command, nil
+}
+
+func (db *memoryDB) RetrieveCommands(filters *domain.CommandFilters) ([]domain.ActuatorCommand, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	commands := []domain.ActuatorCommand{}
+	for _, command := range db.commands {
+		switch {
+		case filters.FarmID != "" && command.FarmID != filters.FarmID,
+			filters.DeviceID != "" && command.DeviceID != filters.DeviceID,
+			filters.Output != "" && command.Output != filters.Output,
+			len(filters.Statuses) > 0 && !containsString(filters.Statuses, command.Status),
+			!filters.From.IsZero() && command.CreatedAt.Before(filters.From),
+			!filters.To.IsZero() && !command.CreatedAt.Before(filters.To):
+			continue
+		}
+		commands = append(commands, command)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].CreatedAt.Before(commands[j].CreatedAt) })
+	return commands, nil
+}
+
+// putUser stores a user and returns its ID
+func (db *memoryDB) SaveControlDecision(decision *domain.ControlDecision) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	decision.ID = primitive.NewObjectID()
+	db.decisions = append(db.decisions, *decision)
+	return nil
+}
+
+func (db *memoryDB) GetCropSpecification(cropType string) (domain.CropSpecification, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	spec, ok := db.specs[cropType]
+	if !ok {
+		return domain.CropSpecification{}, errors.New("crop specification not found")
+	}
+	return spec, nil
+}
+
+func (db *memoryDB) SaveRecommendation(recommendation *domain.DosingRecommendation) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	recommendation.ID = primitive.NewObjectID()
+	db.dosing = append(db.dosing, *recommendation)
+	return nil
+}
+
+func (db *memoryDB) putUser(name string) string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	user := domain.OrdinaryUser{ID: primitive.NewObjectID(), Name: name}
+	db.users[user.ID.Hex()] = user
+	return user.ID.Hex()
+}
+
+func (db *memoryDB) RegisterOrdinaryUser(user *domain.OrdinaryUser) (string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	user.ID = primitive.NewObjectID()
+	db.users[user.ID.Hex()] = *user
+	return user.ID.Hex(), nil
+}
+
+func (db *memoryDB) GetUser(id string) (*domain.OrdinaryUser, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	user, ok := db.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return &user, nil
+}
+
+func (db *memoryDB) SaveWalletChallenge(challenge *domain.WalletChallenge) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.challenges[[2]string{challenge.UserID, challenge.AccountID}] = *challenge
+	return nil
+}
+
+func (db *memoryDB) TakeWalletChallenge(userID, accountID string) (*domain.WalletChallenge, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	key := [2]string{userID, accountID}
+	challenge, ok := db.challenges[key]
+	if !ok {
+		return nil, errors.New("no challenge issued for this wallet")
+	}
+	delete(db.challenges, key)
+	return &challenge, nil
+}
+
+func (db *memoryDB) SaveWallet(wallet *domain.Wallet) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.wallets[wallet.AccountID]; ok {
+		return errors.New("wallet is already bound")
+	}
+	db.wallets[wallet.AccountID] = *wallet
+	return nil
+}
+
+func (db *memoryDB) RetrieveWallet(accountID string) (*domain.Wallet, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	wallet, ok := db.wallets[accountID]
+	if !ok {
+		return nil, errors.New("wallet not found")
+	}
+	return &wallet, nil
+}
+
+func (db *memoryDB) RetrieveWallets(userID string) ([]domain.Wallet, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var wallets []domain.Wallet
+	for _, wallet := range db.wallets {
+		if wallet.UserID == userID {
+			wallets = append(wallets, wallet)
+		}
+	}
+	return wallets, nil
+}
+
+func (db *memoryDB) SaveAuthSession(session *domain.AuthSession) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, stored := range db.sessions {
+		if stored.ChallengeHash == session.ChallengeHash {
+			return errors.New("challenge has already been used")
+		}
+	}
+	session.ID = primitive.NewObjectID()
+	db.sessions[session.TokenHash] = *session
+	return nil
+}
+
+func (db *memoryDB) RetrieveAuthSession(tokenHash string) (*domain.AuthSession, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	session, ok := db.sessions[tokenHash]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	return &session, nil
+}
+
+func (db *memoryDB) DeleteAuthSession(tokenHash string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.sessions[tokenHash]; !ok {
+		return errors.New("session not found")
+	}
+	delete(db.sessions, tokenHash)
+	return nil
+}
+
+// putDistribution stores a distribution and returns its ID
+func (db *memoryDB) putDistribution(distribution domain.Distribution) string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	distribution.ID = primitive.NewObjectID()
+	db.distributions[distribution.ID.Hex()] = distribution
+	return distribution.ID.Hex()
+}
+
+func (db *memoryDB) RetrieveDistribution(id string) (*domain.Distribution, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	distribution, ok := db.distributions[id]
+	if !ok {
+		return nil, errors.New("distribution not found")
+	}
+	return &distribution, nil
+}
+
+func (db *memoryDB) RetrieveHarvest(id string) (*domain.HarvestRecord, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	harvest, ok := db.harvests[id]
+	if !ok {
+		return nil, errors.New("harvest not found")
+	}
+	return &harvest, nil
+}
+
+func (db *memoryDB) RetrieveOwnershipEvents(farmID string, until time.Time) ([]domain.OwnershipEvent, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var events []domain.OwnershipEvent
+	for _, event := range db.events {
+		if event.FarmID == farmID && (until.IsZero() || !event.OccurredAt.After(until)) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (db *memoryDB) SavePayout(payout *domain.Payout) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, stored := range db.payouts {
+		if stored.DistributionID == payout.DistributionID {
+			return errors.New("distribution already has a payout")
+		}
+	}
+	payout.ID = primitive.NewObjectID()
+	db.payouts[payout.ID.Hex()] = copyPayout(*payout)
+	return nil
+}
+
+func (db *memoryDB) UpdatePayout(payout *domain.Payout) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.payouts[payout.ID.Hex()]; !ok {
+		return errors.New("payout not found")
+	}
+	db.payouts[payout.ID.Hex()] = copyPayout(*payout)
+	return nil
+}
+
+func (db *memoryDB) RetrievePayout(id string) (*domain.Payout, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	payout, ok := db.payouts[id]
+	if !ok {
+		return nil, errors.New("payout not found")
+	}
+	payout = copyPayout(payout)
+	return &payout, nil
+}
+
+func (db *memoryDB) RetrieveDistributionPayout(distributionID string) (*domain.Payout, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, payout := range db.payouts {
+		if payout.DistributionID == distributionID {
+			payout = copyPayout(payout)
+			return &payout, nil
+		}
+	}
+	return nil, errors.New("payout not found")
+}
+
+func (db *memoryDB) SetFarmOffering(id string, offering *domain.Offering) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	farm, ok := db.farms[id]
+	if !ok {
+		return errors.New("farm not found")
+	}
+	farm.Offering = offering
+	db.farms[id] = farm
+	return nil
+}
+
+func (db *memoryDB) SaveInvestment(investment *domain.Investment) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	investment.ID = primitive.NewObjectID()
+	db.investments[investment.ID.Hex()] = *investment
+	return nil
+}
+
+func (db *memoryDB) UpdateInvestment(investment *domain.Investment) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.investments[investment.ID.Hex()]; !ok {
+		return errors.New("investment not found")
+	}
+	db.investments[investment.ID.Hex()] = *investment
+	return nil
+}
+
+func (db *memoryDB) RetrieveInvestment(id string) (*domain.Investment, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	investment, ok := db.investments[id]
+	if !ok {
+		return nil, errors.New("investment not found")
+	}
+	return &investment, nil
+}
+
+func (db *memoryDB) RetrieveInvestments(farmID, investorID string) ([]domain.Investment, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var investments []domain.Investment
+	for _, investment := range db.investments {
+		if (farmID == "" || investment.FarmID == farmID) && (investorID == "" || investment.InvestorID == investorID) {
+			investments = append(investments, investment)
+		}
+	}
+	return investments, nil
+}
+
+func (db *memoryDB) SaveIncomingPayment(payment *domain.IncomingPayment) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.payments[payment.PaymentID]; ok {
+		return false, nil
+	}
+	payment.ID = primitive.NewObjectID()
+	db.payments[payment.PaymentID] = *payment
+	return true, nil
+}
+
+func (db *memoryDB) UpdateIncomingPayment(payment *domain.IncomingPayment) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored, ok := db.payments[payment.PaymentID]
+	if !ok || stored.ID != payment.ID {
+		return errors.New("payment not found")
+	}
+	db.payments[payment.PaymentID] = *payment
+	return nil
+}
+
+func (db *memoryDB) RetrieveIncomingPayments(statuses []string) ([]domain.IncomingPayment, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var payments []domain.IncomingPayment
+	for _, payment := range db.payments {
+		if len(statuses) == 0 || containsString(statuses, payment.Status) {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (db *memoryDB) RetrieveInvestmentPayments(investmentID string) ([]domain.IncomingPayment, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var payments []domain.IncomingPayment
+	for _, payment := range db.payments {
+		if payment.InvestmentID == investmentID {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (db *memoryDB) RetrievePaymentCursor(accountID string) (string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.cursors[accountID], nil
+}
+
+func (db *memoryDB) SavePaymentCursor(accountID, cursor string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.cursors[accountID] = cursor
+	return nil
+}
+
+func (db *memoryDB) SaveShareOrder(order *domain.ShareOrder) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	order.ID = primitive.NewObjectID()
+	db.orders[order.ID.Hex()] = *order
+	return nil
+}
+
+func (db *memoryDB) UpdateShareOrder(order *domain.ShareOrder) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.orders[order.ID.Hex()]; !ok {
+		return errors.New("order not found")
+	}
+	db.orders[order.ID.Hex()] = *order
+	return nil
+}
+
+func (db *memoryDB) RetrieveShareOrder(id string) (*domain.ShareOrder, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	order, ok := db.orders[id]
+	if !ok {
+		return nil, errors.New("order not found")
+	}
+	return &order, nil
+}
+
+func (db *memoryDB) RetrieveShareOrders(farmID, status string) ([]domain.ShareOrder, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var orders []domain.ShareOrder
+	for _, order := range db.orders {
+		if order.FarmID == farmID && (status == "" || order.Status == status) {
+			orders = append(orders, order)
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+	return orders, nil
+}
+
+func (db *memoryDB) RetrieveShareTrades(farmID string) ([]domain.ShareTrade, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var trades []domain.ShareTrade
+	for _, trade := range db.trades {
+		if trade.FarmID == farmID {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+func (db *memoryDB) RetrieveShareTrade(id string) (*domain.ShareTrade, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	trade, ok := db.trades[id]
+	if !ok {
+		return nil, errors.New("trade not found")
+	}
+	return &trade, nil
+}
+
+// ExecuteShareTrade applies a trade to copies of the farm and orders,
+// keeping the changes only if apply succeeds
+func (db *memoryDB) ExecuteShareTrade(farmID string, orderIDs []string, apply func(farm *domain.VerticalFarm, orders []*domain.ShareOrder) (*domain.ShareTrade, error)) (*domain.ShareTrade, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	farm, ok := db.farms[farmID]
+	if !ok {
+		return nil, errors.New("farm not found")
+	}
+	farm.Owners = append([]domain.Owner(nil), farm.Owners...)
+	orders := make([]*domain.ShareOrder, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		order, ok := db.orders[id]
+		if !ok {
+			return nil, errors.New("order not found")
+		}
+		orders = append(orders, &order)
+	}
+
+	trade, err := apply(&farm, orders)
+	if err != nil {
+		return nil, err
+	}
+	db.farms[farmID] = farm
+	for _, order := range orders {
+		db.orders[order.ID.Hex()] = *order
+	}
+	if trade != nil {
+		trade.ID = primitive.NewObjectID()
+		db.trades[trade.ID.Hex()] = *trade
+	}
+	return trade, nil
+}
+
+// UpdateShareTrade changes copies of a trade and its farm, keeping the
+// changes only if change succeeds
+func (db *memoryDB) UpdateShareTrade(id string, change func(farm *domain.VerticalFarm, trade *domain.ShareTrade) error) (*domain.ShareTrade, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	trade, ok := db.trades[id]
+	if !ok {
+		return nil, errors.New("trade not found")
+	}
+	farm, ok := db.farms[trade.FarmID]
+	if !ok {
+		return nil, errors.New("farm not found")
+	}
+	farm.Owners = append([]domain.Owner(nil), farm.Owners...)
+
+	if err := change(&farm, &trade); err != nil {
+		return nil, err
+	}
+	db.farms[trade.FarmID] = farm
+	db.trades[id] = trade
+	return &trade, nil
+}
+
+// copyPayout copies a payout's batches and lines, so stored payouts do not
+// change with the ones the service holds
+func copyPayout(payout domain.Payout) domain.Payout {
+	payout.Held = append([]domain.PayoutHold{}, payout.Held...)
+	batches := make([]domain.PayoutBatch, len(payout.Batches))
+	for i, batch := range payout.Batches {
+		batch.Lines = append([]domain.DistributionLine{}, batch.Lines...)
+		batches[i] = batch
+	}
+	payout.Batches = batches
+	return payout
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
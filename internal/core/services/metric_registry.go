@@ -0,0 +1,155 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/units"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// defaultMetrics are the metrics every deployment understands out of the box
+var defaultMetrics = []domain.MetricDefinition{
+	{Key: domain.MetricPH, Name: "pH", Unit: "pH", Dimension: units.PH, Min: 0, Max: 14},
+	{Key: domain.MetricHumidity, Name: "Relative humidity", Unit: "%", Dimension: units.RelativeHumidity, Min: 0, Max: 100},
+	{Key: domain.MetricNutrientLevel, Name: "Nutrient level", Unit: "ratio", Dimension: units.Conductivity, Min: 0, Max: 10},
+	{Key: domain.MetricTemperature, Name: "Air temperature", Unit: "C", Dimension: units.Temperature, Min: -40, Max: 80},
+	{Key: domain.MetricCO2, Name: "CO2", Unit: "ppm", Dimension: units.MixingRatio, Min: 0, Max: 10000},
+	{Key: domain.MetricPPFD, Name: "PAR light (PPFD)", Unit: "umol/m2/s", Dimension: units.PPFD, Min: 0, Max: 3000},
+	{Key: domain.MetricEC, Name: "Electrical conductivity", Unit: "mS/cm", Dimension: units.Conductivity, Min: 0, Max: 20},
+	{Key: domain.MetricWaterTemp, Name: "Water temperature", Unit: "C", Dimension: units.Temperature, Min: 0, Max: 50},
+	{Key: domain.MetricDissolvedOxygen, Name: "Dissolved oxygen", Unit: "mg/L", Dimension: units.MassConcentration, Min: 0, Max: 30},
+	{Key: domain.MetricVPD, Name: "Vapour pressure deficit", Unit: "kPa", Dimension: units.Pressure, Min: 0, Max: 10},
+}
+
+// metricKeyPattern restricts metric keys to names that are safe to use as
+// document field names
+var metricKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// MetricRegistry keeps track of the sensor metrics readings may carry
+type MetricRegistry struct {
+	db      ports.MongoDB
+	mu      sync.RWMutex
+	metrics map[string]domain.MetricDefinition
+}
+
+// NewMetricRegistry creates a registry seeded with the default metrics
+func NewMetricRegistry(db ports.MongoDB) *MetricRegistry {
+	registry := &MetricRegistry{
+		db:      db,
+		metrics: make(map[string]domain.MetricDefinition, len(defaultMetrics)),
+	}
+	for _, m := range defaultMetrics {
+		registry.metrics[m.Key] = m
+	}
+	return registry
+}
+
+// Load adds the metrics registered at runtime and persisted in the database
+func (r *MetricRegistry) Load() error {
+	stored, err := r.db.RetrieveMetricDefinitions()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range stored {
+		r.metrics[m.Key] = m
+	}
+	return nil
+}
+
+// Register validates and persists a new or updated metric definition
+func (r *MetricRegistry) Register(metric domain.MetricDefinition) error {
+	if metric.Key == "" {
+		return errors.New("metric key is required")
+	}
+	if !metricKeyPattern.MatchString(metric.Key) {
+		return errors.New("metric key must be lower case letters, digits and underscores")
+	}
+	if metric.Unit == "" {
+		return errors.New("metric unit is required")
+	}
+	if metric.Min >= metric.Max {
+		return errors.New("metric min must be lower than max")
+	}
+
+	if err := r.db.SaveMetricDefinition(&metric); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.metrics[metric.Key] = metric
+	r.mu.Unlock()
+	return nil
+}
+
+// Get returns the definition of a registered metric
+func (r *MetricRegistry) Get(key string) (domain.MetricDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.metrics[key]
+	return m, ok
+}
+
+// List returns all registered metrics ordered by key
+func (r *MetricRegistry) List() []domain.MetricDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metrics := make([]domain.MetricDefinition, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Key < metrics[j].Key })
+	return metrics
+}
+
+// ValidateReading checks that every metric in the reading is registered and
+// within its valid range
+func (r *MetricRegistry) ValidateReading(reading domain.IoTReading) error {
+	for key, value := range reading.MetricValues() {
+		m, ok := r.Get(key)
+		if !ok {
+			return fmt.Errorf("unregistered metric %q", key)
+		}
+		if !m.InRange(value) {
+			return fmt.Errorf("%s value %g %s outside valid range [%g, %g]", key, value, m.Unit, m.Min, m.Max)
+		}
+	}
+	return nil
+}
+
+// ValidateTargets checks that a crop specification and its stages only target
+// registered metrics
+func (r *MetricRegistry) ValidateTargets(spec domain.CropSpecification) error {
+	for _, t := range spec.Targets {
+		if _, ok := r.Get(t.Metric); !ok {
+			return fmt.Errorf("crop %s targets unregistered metric %q", spec.Name, t.Metric)
+		}
+		if t.Scoring != domain.ScoringDeviation && t.Scoring != domain.ScoringRatio {
+			return fmt.Errorf("unknown scoring mode %q for metric %q", t.Scoring, t.Metric)
+		}
+		if t.Weight <= 0 {
+			return fmt.Errorf("target for metric %q needs a positive weight", t.Metric)
+		}
+		if t.Scoring == domain.ScoringRatio && t.Optimal == 0 {
+			return fmt.Errorf("ratio target for metric %q needs a non-zero optimal value", t.Metric)
+		}
+	}
+	for _, stage := range spec.Stages {
+		if stage.StartDay < 0 {
+			return fmt.Errorf("stage %s of crop %s cannot start before planting", stage.Name, spec.Name)
+		}
+		for _, t := range stage.Targets {
+			if _, ok := r.Get(t.Metric); !ok {
+				return fmt.Errorf("stage %s of crop %s targets unregistered metric %q", stage.Name, spec.Name, t.Metric)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"sort"
+	"time"
+)
+
+// OwnershipService reconstructs who owned a farm at any point in time from
+// its ownership events
+type OwnershipService struct {
+	db ports.MongoDB
+}
+
+// NewOwnershipService creates a new instance of the ownership service
+func NewOwnershipService(db ports.MongoDB) *OwnershipService {
+	return &OwnershipService{db: db}
+}
+
+// CapTable replays the farm's ownership events up to at and returns the
+// holders then
+func (s *OwnershipService) CapTable(farmID string, at time.Time) (*domain.CapTable, error) {
+	if _, err := s.db.GetFarm(farmID); err != nil {
+		return nil, err
+	}
+	events, err := s.db.RetrieveOwnershipEvents(farmID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make(map[string]*domain.Owner)
+	var order []string
+	for _, event := range events {
+		holder := holders[event.OwnerID]
+		if holder == nil {
+			holder = &domain.Owner{ID: event.OwnerID, Address: event.Address, JoinedAt: event.OccurredAt}
+			holders[event.OwnerID] = holder
+			order = append(order, event.OwnerID)
+		}
+		holder.Shares += event.Change
+	}
+
+	table := &domain.CapTable{
+		FarmID:    farmID,
+		At:        at,
+		Holders:   make([]domain.Owner, 0, len(order)),
+		Addresses: make([]domain.AddressHolding, 0),
+	}
+	byAddress := make(map[string]domain.Shares)
+	for _, id := range order {
+		holder := holders[id]
+		if holder.Shares <= 0 {
+			continue
+		}
+		table.Holders = append(table.Holders, *holder)
+		if _, ok := byAddress[holder.Address]; !ok {
+			table.Addresses = append(table.Addresses, domain.AddressHolding{Address: holder.Address})
+		}
+		byAddress[holder.Address] += holder.Shares
+		table.Issued += holder.Shares
+	}
+	for i := range table.Addresses {
+		table.Addresses[i].Shares = byAddress[table.Addresses[i].Address]
+	}
+	table.Unissued = domain.SharesPerFarm - table.Issued
+
+	sort.SliceStable(table.Holders, func(i, j int) bool { return table.Holders[i].Shares > table.Holders[j].Shares })
+	sort.SliceStable(table.Addresses, func(i, j int) bool { return table.Addresses[i].Shares > table.Addresses[j].Shares })
+	return table, nil
+}
+
+// History returns every ownership event of the farm, oldest first
+func (s *OwnershipService) History(farmID string) ([]domain.OwnershipEvent, error) {
+	events, err := s.db.RetrieveOwnershipEvents(farmID, time.Time{})
+	if err != nil {
+		return []domain.OwnershipEvent{}, err
+	}
+	if events == nil {
+		events = []domain.OwnershipEvent{}
+	}
+	return events, nil
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/logger"
+	"0xFarms-backend/pkg/money"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// errPaymentsDisabled is returned when no receiving account is configured
+var errPaymentsDisabled = errors.New("payment reconciliation is not configured")
+
+// PaymentService reconciles payments received on Stellar with pending
+// investments. Payments are matched by memo to an investment, must be in
+// the investment's currency from an accepted issuer, and confirm it once
+// they add up to its amount. Payments that do not fit are kept for review.
+type PaymentService struct {
+	db          ports.MongoDB
+	ledger      stellar.Ledger
+	investments *InvestmentService
+	account     string            // receiving account, empty when disabled
+	issuers     map[string]string // accepted issuer of each currency but XLM
+	mu          sync.Mutex        // serialises matching
+}
+
+// NewPaymentService creates a new instance of the payment service. Payments
+// to account are reconciled, in XLM or the assets of issuers.
+func NewPaymentService(db ports.MongoDB, ledger stellar.Ledger, investments *InvestmentService, account string, issuers map[string]string) *PaymentService {
+	return &PaymentService{db: db, ledger: ledger, investments: investments, account: account, issuers: issuers}
+}
+
+// Sync reads the payments received since the last sync and reconciles
+// them, returning those newly recorded. Payments recorded but left
+// unmatched by an interruption are matched again.
+func (s *PaymentService) Sync() ([]domain.IncomingPayment, error) {
+	if s.account == "" {
+		return nil, errPaymentsDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.retryReceived(); err != nil {
+		return nil, err
+	}
+
+	cursor, err := s.db.RetrievePaymentCursor(s.account)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.ledger.Payments(s.account, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := []domain.IncomingPayment{}
+	for _, p := range payments {
+		payment, err := incomingPayment(p)
+		if err != nil {
+			return recorded, err
+		}
+		isNew, err := s.record(&payment)
+		if err != nil {
+			return recorded, err
+		}
+		if isNew {
+			recorded = append(recorded, payment)
+		}
+		if err := s.db.SavePaymentCursor(s.account, p.Cursor); err != nil {
+			return recorded, err
+		}
+	}
+	return recorded, nil
+}
+
+// Import reconciles payments missed by sync, such as those before its
+// cursor, given their ledger operation IDs. Each payment is read from the
+// ledger and must have been made to the receiving account. Payments
+// recorded before, by an earlier import or sync, are skipped and counted.
+func (s *PaymentService) Import(paymentIDs []string) ([]domain.IncomingPayment, int, error) {
+	if s.account == "" {
+		return nil, 0, errPaymentsDisabled
+	}
+	payments := make([]domain.IncomingPayment, 0, len(paymentIDs))
+	for _, id := range paymentIDs {
+		p, err := s.ledger.Payment(id)
+		if errors.Is(err, stellar.ErrPaymentNotFound) {
+			return nil, 0, fmt.Errorf("payment %s is not on the ledger", id)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if p.To != s.account {
+			return nil, 0, fmt.Errorf("payment %s was not made to %s", id, s.account)
+		}
+		payment, err := incomingPayment(*p)
+		if err != nil {
+			return nil, 0, err
+		}
+		payments = append(payments, payment)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded := []domain.IncomingPayment{}
+	duplicates := 0
+	for _, payment := range payments {
+		isNew, err := s.record(&payment)
+		if err != nil {
+			return recorded, duplicates, err
+		}
+		if !isNew {
+			duplicates++
+			continue
+		}
+		recorded = append(recorded, payment)
+	}
+	return recorded, duplicates, nil
+}
+
+// PaymentTransaction builds the payment of a pending investment from the
+// account at address, for the account holder to sign and submit. Once
+// received it is matched to the investment like any other payment.
+func (s *PaymentService) PaymentTransaction(investmentID, address string) (string, error) {
+	if s.account == "" {
+		return "", errPaymentsDisabled
+	}
+	investment, err := s.db.RetrieveInvestment(investmentID)
+	if err != nil {
+		return "", err
+	}
+	if investment.Status != domain.InvestmentPending {
+		return "", fmt.Errorf("investment is %s", investment.Status)
+	}
+
+	asset, err := stellar.PaymentAsset(investment.Currency, s.issuers[investment.Currency])
+	if err != nil {
+		return "", fmt.Errorf("%s payments are not accepted: %v", investment.Currency, err)
+	}
+	account, err := s.ledger.Account(address)
+	if err != nil {
+		return "", fmt.Errorf("account %s: %v", address, err)
+	}
+
+	tx, err := stellar.NewPurchasePayment(account, s.account, asset, investment.Amount, investment.ID.Hex())
+	if err != nil {
+		return "", err
+	}
+	return tx.Base64()
+}
+
+// Payments retrieves the recorded payments in a status, every payment when
+// status is empty and those needing review when it is "review"
+func (s *PaymentService) Payments(status string) ([]domain.IncomingPayment, error) {
+	var statuses []string
+	switch status {
+	case "":
+	case "review":
+		statuses = domain.PaymentReviewStatuses
+	default:
+		statuses = []string{status}
+	}
+
+	payments, err := s.db.RetrieveIncomingPayments(statuses)
+	if err != nil {
+		return nil, err
+	}
+	if payments == nil {
+		payments = []domain.IncomingPayment{}
+	}
+	return payments, nil
+}
+
+// Run syncs payments each interval until the context is cancelled
+func (s *PaymentService) Run(ctx context.Context, every time.Duration) {
+	if s.account == "" {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recorded, err := s.Sync()
+			if err != nil {
+				logger.LogWarning(fmt.Sprintf("Failed to sync incoming payments: %v", err))
+			}
+			for _, payment := range recorded {
+				if payment.Status != domain.PaymentMatched {
+					logger.LogWarning(fmt.Sprintf("Payment %s needs review: %s", payment.PaymentID, payment.Note))
+				}
+			}
+		}
+	}
+}
+
+// record stores a payment and matches it, reporting false for payments
+// recorded before. The payment is stored first so an interrupted match is
+// picked up again rather than read as new.
+func (s *PaymentService) record(payment *domain.IncomingPayment) (bool, error) {
+	payment.ID = primitive.NilObjectID
+	payment.InvestmentID, payment.Note = "", ""
+	payment.Status = domain.PaymentReceived
+	payment.RecordedAt = time.Now()
+	isNew, err := s.db.SaveIncomingPayment(payment)
+	if err != nil || !isNew {
+		return false, err
+	}
+	return true, s.match(payment)
+}
+
+// incomingPayment returns a payment read from the ledger as a record
+func incomingPayment(p stellar.Payment) (domain.IncomingPayment, error) {
+	amount, err := money.Parse(p.Amount)
+	if err != nil {
+		return domain.IncomingPayment{}, fmt.Errorf("payment %s: %v", p.ID, err)
+	}
+	return domain.IncomingPayment{
+		PaymentID:       p.ID,
+		TransactionHash: p.TransactionHash,
+		From:            p.From,
+		To:              p.To,
+		AssetCode:       p.AssetCode,
+		AssetIssuer:     p.AssetIssuer,
+		Amount:          amount,
+		Memo:            p.Memo,
+		ReceivedAt:      p.ReceivedAt,
+	}, nil
+}
+
+// retryReceived matches payments recorded but never matched
+func (s *PaymentService) retryReceived() error {
+	payments, err := s.db.RetrieveIncomingPayments([]string{domain.PaymentReceived})
+	if err != nil {
+		return err
+	}
+	for i := range payments {
+		if err := s.match(&payments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match settles a recorded payment against the investment its memo names.
+// Payments to an investment add up until they cover its amount, which
+// confirms it and allocates its shares; earlier partial payments are then
+// marked matched too. Only investments priced at the farm's offering are
+// confirmed; others are left for an operator to review.
+func (s *PaymentService) match(payment *domain.IncomingPayment) error {
+	investment, reason := s.investmentFor(payment)
+	if investment == nil {
+		payment.Status, payment.Note = domain.PaymentUnmatched, reason
+		return s.db.UpdateIncomingPayment(payment)
+	}
+	payment.InvestmentID = investment.ID.Hex()
+
+	switch investment.Status {
+	case domain.InvestmentRefunded:
+		payment.Status, payment.Note = domain.PaymentUnmatched, "investment was refunded"
+		return s.db.UpdateIncomingPayment(payment)
+	case domain.InvestmentConfirmed:
+		payment.Status, payment.Note = domain.PaymentOverpaid, "investment was already paid"
+		return s.db.UpdateIncomingPayment(payment)
+	}
+
+	earlier, err := s.db.RetrieveInvestmentPayments(payment.InvestmentID)
+	if err != nil {
+		return err
+	}
+	var partial []domain.IncomingPayment
+	paid := payment.Amount
+	for _, p := range earlier {
+		if p.ID != payment.ID && p.Status == domain.PaymentUnderpaid {
+			partial = append(partial, p)
+			paid += p.Amount
+		}
+	}
+
+	if paid < investment.Amount {
+		payment.Status = domain.PaymentUnderpaid
+		payment.Note = fmt.Sprintf("%s of %s %s received", paid, investment.Amount, investment.Currency)
+		return s.db.UpdateIncomingPayment(payment)
+	}
+
+	if reason, err := s.checkPrice(investment); err != nil {
+		return err
+	} else if reason != "" {
+		payment.Status = domain.PaymentUnconfirmed
+		payment.Note = "investment is paid but " + reason
+		return s.db.UpdateIncomingPayment(payment)
+	}
+	if _, err := s.investments.Confirm(payment.InvestmentID); err != nil {
+		payment.Status = domain.PaymentUnconfirmed
+		payment.Note = fmt.Sprintf("investment is paid but could not be confirmed: %v", err)
+		return s.db.UpdateIncomingPayment(payment)
+	}
+	for i := range partial {
+		partial[i].Status, partial[i].Note = domain.PaymentMatched, ""
+		if err := s.db.UpdateIncomingPayment(&partial[i]); err != nil {
+			return err
+		}
+	}
+	if paid > investment.Amount {
+		payment.Status = domain.PaymentOverpaid
+		payment.Note = fmt.Sprintf("%s %s paid beyond the %s owed", paid-investment.Amount, investment.Currency, investment.Amount)
+	} else {
+		payment.Status, payment.Note = domain.PaymentMatched, ""
+	}
+	return s.db.UpdateIncomingPayment(payment)
+}
+
+// checkPrice returns why an investment's amount cannot be trusted to buy
+// its shares, empty when it is the shares at the farm's offering price.
+// Investments recorded before offerings, with investor-chosen amounts,
+// are not.
+func (s *PaymentService) checkPrice(investment *domain.Investment) (string, error) {
+	farm, err := s.db.GetFarm(investment.FarmID)
+	if err != nil {
+		return "", err
+	}
+	offering := farm.Offering
+	if offering == nil {
+		return "the farm has no offering price", nil
+	}
+	amount, err := offering.Amount(investment.Shares)
+	if err != nil {
+		return "", err
+	}
+	if investment.Currency != offering.Currency || investment.Amount != amount {
+		return fmt.Sprintf("its amount is not the offering price of %s %s", amount, offering.Currency), nil
+	}
+	return "", nil
+}
+
+// investmentFor finds the investment a payment is for, or returns why it
+// cannot be matched
+func (s *PaymentService) investmentFor(payment *domain.IncomingPayment) (*domain.Investment, string) {
+	if payment.Memo == "" {
+		return nil, "payment has no memo"
+	}
+	investment, err := s.db.RetrieveInvestment(payment.Memo)
+	if err != nil {
+		return nil, "memo does not name an investment"
+	}
+
+	if reason := assetMismatch(s.issuers, investment.Currency, payment.AssetCode, payment.AssetIssuer); reason != "" {
+		return nil, reason
+	}
+	return investment, ""
+}
+
+// assetMismatch returns why a payment in the asset cannot pay an amount in
+// the currency, or "" when it can. XLM is paid in lumens, other currencies
+// in the asset of their accepted issuer.
+func assetMismatch(issuers map[string]string, currency, code, issuer string) string {
+	if currency == "XLM" {
+		if code != "XLM" || issuer != "" {
+			return fmt.Sprintf("paid in %s, not XLM", code)
+		}
+		return ""
+	}
+	if code != currency {
+		return fmt.Sprintf("paid in %s, not %s", code, currency)
+	}
+	accepted, ok := issuers[currency]
+	if !ok {
+		return fmt.Sprintf("no issuer of %s is accepted", currency)
+	}
+	if issuer != accepted {
+		return fmt.Sprintf("paid in %s issued by %s, not the accepted issuer", code, issuer)
+	}
+	return ""
+}
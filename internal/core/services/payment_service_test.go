@@ -0,0 +1,156 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/money"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// pay sends lumens from one key's account to another's and returns the
+// payment's operation ID
+func pay(t *testing.T, ledger *stellar.MemoryLedger, from, to *keypair.Full, amount string) string {
+	t.Helper()
+	submitAs(t, ledger, from, &txnbuild.Payment{Destination: to.Address(), Amount: amount, Asset: txnbuild.NativeAsset{}})
+	payments, err := ledger.Payments(to.Address(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payments[len(payments)-1].ID
+}
+
+// payInvestment pays for an investment from the key's account, with the
+// investment's ID as memo
+func payInvestment(t *testing.T, ledger *stellar.MemoryLedger, from *keypair.Full, to string, investment *domain.Investment, amount money.Amount) {
+	t.Helper()
+	account, err := ledger.Account(from.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.NewPurchasePayment(account, to, txnbuild.NativeAsset{}, amount, investment.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, from); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.Submit(tx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaymentConfirmsOfferingPrice(t *testing.T) {
+	receiver, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, receiver, alice)
+	investments, db, investorID := newTestInvestments(t)
+	payments := NewPaymentService(db, ledger, investments, receiver.Address(), nil)
+
+	investment, err := investments.Invest("farm-1", domain.Investment{InvestorID: investorID, Shares: 10_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payInvestment(t, ledger, alice, receiver.Address(), investment, investment.Amount)
+	recorded, err := payments.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 || recorded[0].Status != domain.PaymentMatched {
+		t.Fatalf("recorded = %+v, want the payment matched", recorded)
+	}
+	if investment, _ = db.RetrieveInvestment(investment.ID.Hex()); investment.Status != domain.InvestmentConfirmed {
+		t.Errorf("investment is %s, want confirmed", investment.Status)
+	}
+}
+
+func TestPaymentReviewsInvestorPrice(t *testing.T) {
+	receiver, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, receiver, alice)
+	investments, db, investorID := newTestInvestments(t)
+	payments := NewPaymentService(db, ledger, investments, receiver.Address(), nil)
+
+	// Recorded before offerings, at a price the investor chose
+	wallets, _ := db.RetrieveWallets(investorID)
+	investment := &domain.Investment{
+		InvestorID: investorID, FarmID: "farm-1", Address: wallets[0].AccountID, Currency: "XLM",
+		Shares: domain.SharesPerFarm, Amount: money.MustParse("0.0000001"), Status: domain.InvestmentPending,
+	}
+	if err := db.SaveInvestment(investment); err != nil {
+		t.Fatal(err)
+	}
+	payInvestment(t, ledger, alice, receiver.Address(), investment, investment.Amount)
+	recorded, err := payments.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 || recorded[0].Status != domain.PaymentUnconfirmed {
+		t.Fatalf("recorded = %+v, want the payment left for review", recorded)
+	}
+	if investment, _ = db.RetrieveInvestment(investment.ID.Hex()); investment.Status != domain.InvestmentPending {
+		t.Errorf("investment is %s, want pending", investment.Status)
+	}
+	if farm, _ := db.GetFarm("farm-1"); len(farm.Owners) != 0 {
+		t.Errorf("farm has owners %+v, want none", farm.Owners)
+	}
+}
+
+func TestImportPayments(t *testing.T) {
+	receiver, alice, bob := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, receiver, alice, bob)
+	db := newMemoryDB()
+	payments := NewPaymentService(db, ledger, nil, receiver.Address(), nil)
+	received := pay(t, ledger, alice, receiver, "5")
+
+	recorded, duplicates, err := payments.Import([]string{received})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 || duplicates != 0 {
+		t.Fatalf("recorded %d with %d duplicates, want 1 and 0", len(recorded), duplicates)
+	}
+	payment := recorded[0]
+	if payment.PaymentID != received || payment.From != alice.Address() || payment.AssetCode != "XLM" || payment.Amount != money.MustParse("5") {
+		t.Errorf("payment = %+v, want alice's 5 XLM as on the ledger", payment)
+	}
+	if payment.Status != domain.PaymentUnmatched || payment.Note != "payment has no memo" {
+		t.Errorf("payment is %s (%s), want unmatched for the missing memo", payment.Status, payment.Note)
+	}
+
+	// Importing again, or syncing, records it only once
+	if recorded, duplicates, err = payments.Import([]string{received}); err != nil || len(recorded) != 0 || duplicates != 1 {
+		t.Errorf("import again = %d recorded, %d duplicates, %v; want a duplicate", len(recorded), duplicates, err)
+	}
+	if recorded, err = payments.Sync(); err != nil || len(recorded) != 0 {
+		t.Errorf("sync = %d recorded, %v; want none", len(recorded), err)
+	}
+}
+
+func TestImportPaymentsRejects(t *testing.T) {
+	receiver, alice, bob := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, receiver, alice, bob)
+	db := newMemoryDB()
+	payments := NewPaymentService(db, ledger, nil, receiver.Address(), nil)
+	elsewhere := pay(t, ledger, alice, bob, "5")
+
+	for name, ids := range map[string][]string{
+		"unknown operation":    {"999990001"},
+		"paid to another":      {elsewhere},
+		"one bad in the batch": {pay(t, ledger, alice, receiver, "1"), "999990001"},
+	} {
+		if _, _, err := payments.Import(ids); err == nil {
+			t.Errorf("%s: import succeeded", name)
+		}
+	}
+	if len(db.payments) != 0 {
+		t.Errorf("recorded %d payments, want none", len(db.payments))
+	}
+
+	// Nothing from a rejected import is recorded, so sync still picks up
+	// the payment to the receiver
+	if recorded, err := payments.Sync(); err != nil || len(recorded) != 1 {
+		t.Errorf("sync = %d recorded, %v; want the payment to the receiver", len(recorded), err)
+	}
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/internal/stellar"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// payoutGrace is how long after its time bounds a transaction missing from
+// the ledger is still waited for, covering clock skew and ingestion delay
+const payoutGrace = time.Minute
+
+// errPayoutsDisabled is returned when no paying account is configured
+var errPayoutsDisabled = errors.New("payouts are not configured")
+
+// PayoutService pays finalized distributions on Stellar from the platform's
+// treasury account. Lines are batched into transactions of up to
+// stellar.MaxOperations payments. Every signed transaction is stored before
+// it is submitted, and retries check the ledger for it first, so a batch is
+// never paid twice: it is only rebuilt once the ledger shows the earlier
+// transaction failed or can no longer be applied. A transaction failing
+// because of some recipients is rebuilt without them, holding their lines,
+// so the rest of the batch is still paid.
+type PayoutService struct {
+	db         ports.MongoDB
+	ledger     stellar.Ledger
+	source     *keypair.Full // nil when payouts are not configured
+	passphrase string
+	mu         sync.Mutex // serialises transactions from the source account
+}
+
+// NewPayoutService creates a new instance of the payout service. Payouts are
+// signed by source on the network of passphrase.
+func NewPayoutService(db ports.MongoDB, ledger stellar.Ledger, source *keypair.Full, passphrase string) *PayoutService {
+	return &PayoutService{db: db, ledger: ledger, source: source, passphrase: passphrase}
+}
+
+// Prepare batches the unpaid lines of a distribution, creating its payout
+// on first use. Lines that cannot be paid yet are held out of the batches,
+// and preparing again later batches those that have become payable. The
+// issuer of the distribution's currency is only needed the first time, and
+// not at all for XLM.
+func (s *PayoutService) Prepare(distributionID, issuer string) (*domain.Payout, error) {
+	if s.source == nil {
+		return nil, errPayoutsDisabled
+	}
+	distribution, err := s.db.RetrieveDistribution(distributionID)
+	if err != nil {
+		return nil, err
+	}
+	if distribution.Status != domain.DistributionFinalized {
+		return nil, errors.New("only finalized distributions can be paid out")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payout, err := s.db.RetrieveDistributionPayout(distributionID)
+	isNew := err != nil
+	if isNew {
+		asset, err := stellar.PaymentAsset(distribution.Currency, issuer)
+		if err != nil {
+			return nil, err
+		}
+		payout = &domain.Payout{
+			DistributionID: distributionID,
+			FarmID:         distribution.FarmID,
+			AssetCode:      distribution.Currency,
+			AssetIssuer:    asset.GetIssuer(),
+			Source:         s.source.Address(),
+			Batches:        []domain.PayoutBatch{},
+			CreatedAt:      time.Now(),
+		}
+	} else if issuer != "" && issuer != payout.AssetIssuer {
+		if payout.AssetIssuer == "" {
+			return nil, errors.New("payout is in XLM, which has no issuer")
+		}
+		return nil, fmt.Errorf("payout is in %s issued by %s", payout.AssetCode, payout.AssetIssuer)
+	}
+	asset, err := stellar.PaymentAsset(payout.AssetCode, payout.AssetIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	batched := make(map[string]bool)
+	for _, batch := range payout.Batches {
+		for _, line := range batch.Lines {
+			batched[line.OwnerID] = true
+		}
+	}
+	var payable []domain.DistributionLine
+	payout.Held = []domain.PayoutHold{}
+	for _, line := range distribution.Lines {
+		if line.Amount <= 0 || batched[line.OwnerID] {
+			continue
+		}
+		reason, err := s.checkRecipient(line.Address, asset)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			payout.Held = append(payout.Held, domain.PayoutHold{DistributionLine: line, Reason: reason})
+			continue
+		}
+		payable = append(payable, line)
+	}
+	number := 0
+	if len(payout.Batches) > 0 {
+		number = payout.Batches[len(payout.Batches)-1].Number
+	}
+	for start := 0; start < len(payable); start += stellar.MaxOperations {
+		end := start + stellar.MaxOperations
+		if end > len(payable) {
+			end = len(payable)
+		}
+		number++
+		payout.Batches = append(payout.Batches, domain.PayoutBatch{
+			Number: number,
+			Lines:  payable[start:end],
+			Status: domain.BatchPending,
+		})
+	}
+
+	payout.Status = payoutStatus(payout)
+	payout.UpdatedAt = time.Now()
+	if isNew {
+		err = s.db.SavePayout(payout)
+	} else {
+		err = s.db.UpdatePayout(payout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return payout, nil
+}
+
+// Payout retrieves a payout
+func (s *PayoutService) Payout(id string) (*domain.Payout, error) {
+	return s.db.RetrievePayout(id)
+}
+
+// DistributionPayout retrieves the payout of a distribution
+func (s *PayoutService) DistributionPayout(distributionID string) (*domain.Payout, error) {
+	return s.db.RetrieveDistributionPayout(distributionID)
+}
+
+// Submit sends the batches of a payout that are not confirmed, in order.
+// It is safe to call again after any failure. A batch whose transaction
+// may still land stops the run, as the next one would compete for the same
+// sequence number. Batches left without lines, all of them held, are
+// dropped.
+func (s *PayoutService) Submit(id string) (*domain.Payout, error) {
+	if s.source == nil {
+		return nil, errPayoutsDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payout, err := s.db.RetrievePayout(id)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := stellar.PaymentAsset(payout.AssetCode, payout.AssetIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range payout.Batches {
+		batch := &payout.Batches[i]
+		if batch.Status == domain.BatchConfirmed {
+			continue
+		}
+		live, err := s.submitBatch(payout, batch, asset)
+		if err != nil {
+			return nil, err
+		}
+		if live {
+			break
+		}
+	}
+	batches := payout.Batches[:0]
+	for _, batch := range payout.Batches {
+		if len(batch.Lines) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+	payout.Batches = batches
+	return payout, s.save(payout)
+}
+
+// Reconcile updates the batches of a payout from the ledger without
+// submitting anything
+func (s *PayoutService) Reconcile(id string) (*domain.Payout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payout, err := s.db.RetrievePayout(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range payout.Batches {
+		batch := &payout.Batches[i]
+		if batch.Status == domain.BatchConfirmed || batch.Hash == "" {
+			continue
+		}
+		if _, err := s.reconcileBatch(batch); err != nil {
+			return nil, err
+		}
+	}
+	return payout, s.save(payout)
+}
+
+// submitBatch sends a batch, resending its stored transaction while that
+// can still land and building a new one otherwise. It reports whether the
+// batch's transaction is still outstanding.
+func (s *PayoutService) submitBatch(payout *domain.Payout, batch *domain.PayoutBatch, asset txnbuild.Asset) (bool, error) {
+	if batch.Hash != "" {
+		live, err := s.reconcileBatch(batch)
+		if err != nil || batch.Status == domain.BatchConfirmed {
+			return false, err
+		}
+		if live {
+			live, rejected, err := s.send(payout, batch)
+			if err != nil || live || !holdRejected(payout, batch, rejected) {
+				return live, err
+			}
+		}
+	}
+
+	for len(batch.Lines) > 0 {
+		if err := s.sign(payout, batch, asset); err != nil {
+			return false, err
+		}
+		live, rejected, err := s.send(payout, batch)
+		if err != nil || live || !holdRejected(payout, batch, rejected) {
+			return live, err
+		}
+	}
+	return false, nil
+}
+
+// sign builds and signs a new transaction for a batch and stores it
+func (s *PayoutService) sign(payout *domain.Payout, batch *domain.PayoutBatch, asset txnbuild.Asset) error {
+	source, err := s.ledger.Account(s.source.Address())
+	if err != nil {
+		return fmt.Errorf("paying account: %v", err)
+	}
+	ops := make([]txnbuild.Operation, 0, len(batch.Lines))
+	for _, line := range batch.Lines {
+		ops = append(ops, &txnbuild.Payment{Destination: line.Address, Amount: line.Amount.String(), Asset: asset})
+	}
+	tx, err := stellar.NewTransaction(source, txnbuild.MemoText(payout.DistributionID), ops...)
+	if err != nil {
+		return err
+	}
+	if tx, err = tx.Sign(s.passphrase, s.source); err != nil {
+		return err
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		return err
+	}
+	hash, err := tx.HashHex(s.passphrase)
+	if err != nil {
+		return err
+	}
+
+	// Recorded before sending, so a crash in between leaves a transaction
+	// the next run looks for rather than one it cannot know about
+	now := time.Now()
+	batch.Sequence, batch.Envelope, batch.Hash = tx.SequenceNumber(), envelope, hash
+	batch.Status, batch.Error, batch.SubmittedAt = domain.BatchSubmitted, "", &now
+	return s.save(payout)
+}
+
+// send submits a batch's stored transaction and records the outcome,
+// returning the network's rejection of it if there was one
+func (s *PayoutService) send(payout *domain.Payout, batch *domain.PayoutBatch) (bool, *stellar.SubmitError, error) {
+	tx, err := stellar.ParseTransaction(batch.Envelope)
+	if err != nil {
+		return false, nil, err
+	}
+	var rejected *stellar.SubmitError
+	if _, submitErr := s.ledger.Submit(tx); submitErr != nil {
+		batch.Error = submitErr.Error()
+		errors.As(submitErr, &rejected)
+	}
+	live, err := s.reconcileBatch(batch)
+	if err != nil {
+		return false, nil, err
+	}
+	return live, rejected, s.save(payout)
+}
+
+// holdRejected moves the lines whose payments failed because of their
+// recipient from a failed batch to the payout's held lines. It reports
+// whether any were, so the batch can be rebuilt with the rest.
+func holdRejected(payout *domain.Payout, batch *domain.PayoutBatch, rejected *stellar.SubmitError) bool {
+	if batch.Status != domain.BatchFailed || rejected == nil || len(rejected.OperationCodes) != len(batch.Lines) {
+		return false
+	}
+	kept := make([]domain.DistributionLine, 0, len(batch.Lines))
+	for i, line := range batch.Lines {
+		reason := recipientFailure(rejected.OperationCodes[i], payout.AssetCode)
+		if reason == "" {
+			kept = append(kept, line)
+			continue
+		}
+		payout.Held = append(payout.Held, domain.PayoutHold{DistributionLine: line, Reason: reason})
+	}
+	if len(kept) == len(batch.Lines) {
+		return false
+	}
+	batch.Lines = kept
+	return true
+}
+
+// reconcileBatch updates a batch from the ledger's record of its
+// transaction. A transaction missing from the ledger can still land until
+// its sequence number is used or its time bounds pass; it reports whether
+// that is the case.
+func (s *PayoutService) reconcileBatch(batch *domain.PayoutBatch) (bool, error) {
+	result, err := s.ledger.Transaction(batch.Hash)
+	switch {
+	case err == nil && result.Successful:
+		now := time.Now()
+		batch.Status, batch.Error, batch.Ledger, batch.ConfirmedAt = domain.BatchConfirmed, "", result.Ledger, &now
+		return false, nil
+	case err == nil:
+		batch.Status, batch.Ledger = domain.BatchFailed, result.Ledger
+		if batch.Error == "" {
+			batch.Error = "transaction failed on the ledger"
+		}
+		return false, nil
+	case !errors.Is(err, stellar.ErrTransactionNotFound):
+		return false, err
+	}
+
+	tx, err := stellar.ParseTransaction(batch.Envelope)
+	if err != nil {
+		return false, err
+	}
+	expired := time.Now().After(time.Unix(tx.Timebounds().MaxTime, 0).Add(payoutGrace))
+	source, err := s.ledger.Account(s.source.Address())
+	if err != nil {
+		return false, fmt.Errorf("paying account: %v", err)
+	}
+	if expired || source.Sequence >= batch.Sequence {
+		batch.Status = domain.BatchFailed
+		if batch.Error == "" {
+			batch.Error = "transaction was never applied"
+		}
+		return false, nil
+	}
+	batch.Status = domain.BatchSubmitted
+	return true, nil
+}
+
+// checkRecipient returns why a line to an address cannot be paid in the
+// asset, empty when it can
+func (s *PayoutService) checkRecipient(address string, asset txnbuild.Asset) (string, error) {
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return "invalid Stellar address", nil
+	}
+	account, err := s.ledger.Account(accountID)
+	if errors.Is(err, stellar.ErrAccountNotFound) {
+		return "account does not exist", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if credit, ok := asset.(txnbuild.CreditAsset); ok {
+		if _, trusts := account.Trustline(credit); !trusts {
+			return "account does not trust " + credit.Code, nil
+		}
+	}
+	return "", nil
+}
+
+// recipientFailure returns why a payment with the operation result code
+// failed because of its recipient, empty when it did not
+func recipientFailure(code, assetCode string) string {
+	switch code {
+	case "op_no_destination":
+		return "account does not exist"
+	case "op_no_trust":
+		return "account does not trust " + assetCode
+	case "op_line_full":
+		return "account cannot hold more " + assetCode
+	}
+	return ""
+}
+
+func (s *PayoutService) save(payout *domain.Payout) error {
+	payout.Status = payoutStatus(payout)
+	payout.UpdatedAt = time.Now()
+	return s.db.UpdatePayout(payout)
+}
+
+// payoutStatus is completed once nothing is held and every batch confirmed
+func payoutStatus(payout *domain.Payout) string {
+	if len(payout.Held) > 0 {
+		return domain.PayoutOpen
+	}
+	for _, batch := range payout.Batches {
+		if batch.Status != domain.BatchConfirmed {
+			return domain.PayoutOpen
+		}
+	}
+	return domain.PayoutCompleted
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/money"
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// lossyLedger loses the response to the next lost submissions, applying
+// them to the ledger first when landed is set
+type lossyLedger struct {
+	*stellar.MemoryLedger
+	lost   int
+	landed bool
+}
+
+func (l *lossyLedger) Submit(tx *txnbuild.Transaction) (string, error) {
+	if l.lost == 0 {
+		return l.MemoryLedger.Submit(tx)
+	}
+	l.lost--
+	if l.landed {
+		l.MemoryLedger.Submit(tx)
+	}
+	return "", errors.New("connection reset by peer")
+}
+
+// submitAs signs and submits a transaction from the key's account
+func submitAs(t *testing.T, ledger stellar.Ledger, key *keypair.Full, ops ...txnbuild.Operation) {
+	t.Helper()
+	account, err := ledger.Account(key.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.NewTransaction(account, nil, ops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.Submit(tx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// trustline has each key's account trust the asset, or stop trusting it
+// when limit is "0"
+func trustline(t *testing.T, ledger stellar.Ledger, asset txnbuild.CreditAsset, limit string, keys ...*keypair.Full) {
+	t.Helper()
+	for _, key := range keys {
+		submitAs(t, ledger, key, &txnbuild.ChangeTrust{Line: txnbuild.ChangeTrustAssetWrapper{Asset: asset}, Limit: limit})
+	}
+}
+
+// received returns the account's balance of the asset
+func received(t *testing.T, ledger stellar.Ledger, key *keypair.Full, asset txnbuild.CreditAsset) money.Amount {
+	t.Helper()
+	account, err := ledger.Account(key.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, ok := account.Trustline(asset)
+	if !ok {
+		return 0
+	}
+	amount, err := money.Parse(balance.Amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amount
+}
+
+// newTestPayout prepares the payout of a finalized distribution paying
+// 10 EUR to each key, in EUR issued by the paying account
+func newTestPayout(t *testing.T, ledger stellar.Ledger, treasury *keypair.Full, keys ...*keypair.Full) (*PayoutService, *domain.Payout) {
+	t.Helper()
+	db := newMemoryDB()
+	distribution := domain.Distribution{FarmID: "farm-1", Currency: "EUR", Status: domain.DistributionFinalized}
+	for i, key := range keys {
+		distribution.Lines = append(distribution.Lines, domain.DistributionLine{
+			OwnerID: string(rune('a' + i)),
+			Address: key.Address(),
+			Shares:  100_000,
+			Amount:  money.MustParse("10"),
+		})
+	}
+	id := db.putDistribution(distribution)
+	payouts := NewPayoutService(db, ledger, treasury, network.TestNetworkPassphrase)
+	payout, err := payouts.Prepare(id, treasury.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payouts, payout
+}
+
+func TestPayoutHoldsRejectedRecipients(t *testing.T) {
+	treasury, alice, bob, carol := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, treasury, alice, bob, carol)
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: treasury.Address()}
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, alice, bob, carol)
+	payouts, payout := newTestPayout(t, ledger, treasury, alice, bob, carol)
+	if len(payout.Batches) != 1 || len(payout.Batches[0].Lines) != 3 {
+		t.Fatalf("batches = %+v, want one of 3 lines", payout.Batches)
+	}
+
+	// Carol drops the trustline after the batch is built, failing its
+	// transaction on the ledger
+	trustline(t, ledger, eur, "0", carol)
+	payout, err := payouts.Submit(payout.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := received(t, ledger, alice, eur); got != money.MustParse("10") {
+		t.Errorf("alice received %s, want 10", got)
+	}
+	if got := received(t, ledger, bob, eur); got != money.MustParse("10") {
+		t.Errorf("bob received %s, want 10", got)
+	}
+	batch := payout.Batches[0]
+	if batch.Status != domain.BatchConfirmed || len(batch.Lines) != 2 {
+		t.Errorf("batch = %s with %d lines, want confirmed with 2", batch.Status, len(batch.Lines))
+	}
+	if len(payout.Held) != 1 || payout.Held[0].Address != carol.Address() || payout.Held[0].Reason != "account does not trust EUR" {
+		t.Fatalf("held = %+v, want carol's line for the missing trustline", payout.Held)
+	}
+	if payout.Status != domain.PayoutOpen {
+		t.Errorf("status = %s, want open while a line is held", payout.Status)
+	}
+
+	// Once carol trusts the asset again her line goes in a batch of its own
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, carol)
+	if payout, err = payouts.Prepare(payout.DistributionID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(payout.Batches) != 2 || payout.Batches[1].Number != 2 || len(payout.Held) != 0 {
+		t.Fatalf("batches = %+v, held = %+v, want a second batch for carol", payout.Batches, payout.Held)
+	}
+	if payout, err = payouts.Submit(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if got := received(t, ledger, carol, eur); got != money.MustParse("10") {
+		t.Errorf("carol received %s, want 10", got)
+	}
+	if payout.Status != domain.PayoutCompleted {
+		t.Errorf("status = %s, want completed", payout.Status)
+	}
+}
+
+func TestPayoutDropsBatchOfRejectedRecipients(t *testing.T) {
+	treasury, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, treasury, alice)
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: treasury.Address()}
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, alice)
+	payouts, payout := newTestPayout(t, ledger, treasury, alice)
+
+	trustline(t, ledger, eur, "0", alice)
+	payout, err := payouts.Submit(payout.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payout.Batches) != 0 || len(payout.Held) != 1 {
+		t.Fatalf("batches = %+v, held = %+v, want alice's line held and no batch", payout.Batches, payout.Held)
+	}
+}
+
+func TestPayoutLostResponse(t *testing.T) {
+	treasury, alice, bob := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	ledger := &lossyLedger{MemoryLedger: newTestLedger(t, treasury, alice, bob), landed: true}
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: treasury.Address()}
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, alice, bob)
+	ledger.lost = 1
+	payouts, payout := newTestPayout(t, ledger, treasury, alice, bob)
+
+	// The transaction landed although its response was lost, which the
+	// ledger lookup after submitting finds
+	payout, err := payouts.Submit(payout.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := payout.Batches[0].Hash
+	if payout.Status != domain.PayoutCompleted || payout.Batches[0].Status != domain.BatchConfirmed {
+		t.Fatalf("payout = %s, batch = %s, want completed and confirmed", payout.Status, payout.Batches[0].Status)
+	}
+
+	// Submitting again sends nothing
+	if payout, err = payouts.Submit(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if payout.Batches[0].Hash != hash {
+		t.Errorf("hash = %s, want the confirmed %s", payout.Batches[0].Hash, hash)
+	}
+	for _, key := range []*keypair.Full{alice, bob} {
+		if got := received(t, ledger, key, eur); got != money.MustParse("10") {
+			t.Errorf("%s received %s, want 10 once", key.Address(), got)
+		}
+	}
+}
+
+func TestPayoutResendsOutstandingTransaction(t *testing.T) {
+	treasury, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := &lossyLedger{MemoryLedger: newTestLedger(t, treasury, alice)}
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: treasury.Address()}
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, alice)
+	payouts, payout := newTestPayout(t, ledger, treasury, alice)
+
+	// The submission never reached the network, so the transaction can
+	// still land and is kept
+	ledger.lost = 1
+	payout, err := payouts.Submit(payout.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := payout.Batches[0]
+	if batch.Status != domain.BatchSubmitted || batch.Error == "" {
+		t.Fatalf("batch = %s (%q), want submitted with the error", batch.Status, batch.Error)
+	}
+
+	// The retry resends the same envelope rather than signing another
+	if payout, err = payouts.Submit(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if payout.Batches[0].Hash != batch.Hash || payout.Batches[0].Envelope != batch.Envelope {
+		t.Errorf("retry signed a new transaction %s, want %s resent", payout.Batches[0].Hash, batch.Hash)
+	}
+	if payout.Batches[0].Status != domain.BatchConfirmed {
+		t.Errorf("batch = %s, want confirmed", payout.Batches[0].Status)
+	}
+	if got := received(t, ledger, alice, eur); got != money.MustParse("10") {
+		t.Errorf("alice received %s, want 10", got)
+	}
+}
+
+func TestPayoutReconcile(t *testing.T) {
+	treasury, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := &lossyLedger{MemoryLedger: newTestLedger(t, treasury, alice)}
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: treasury.Address()}
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, alice)
+	payouts, payout := newTestPayout(t, ledger, treasury, alice)
+
+	ledger.lost = 1
+	payout, err := payouts.Submit(payout.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing on the ledger yet, so the batch stays outstanding
+	if payout, err = payouts.Reconcile(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if payout.Batches[0].Status != domain.BatchSubmitted {
+		t.Fatalf("batch = %s, want submitted", payout.Batches[0].Status)
+	}
+
+	// The envelope lands later, relayed by someone else
+	tx, err := stellar.ParseTransaction(payout.Batches[0].Envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.MemoryLedger.Submit(tx); err != nil {
+		t.Fatal(err)
+	}
+	if payout, err = payouts.Reconcile(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if payout.Batches[0].Status != domain.BatchConfirmed || payout.Batches[0].Ledger == 0 {
+		t.Errorf("batch = %s in ledger %d, want confirmed", payout.Batches[0].Status, payout.Batches[0].Ledger)
+	}
+	if payout.Status != domain.PayoutCompleted {
+		t.Errorf("status = %s, want completed", payout.Status)
+	}
+}
+
+func TestPayoutReconcileUsedSequence(t *testing.T) {
+	treasury, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := &lossyLedger{MemoryLedger: newTestLedger(t, treasury, alice)}
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: treasury.Address()}
+	trustline(t, ledger, eur, txnbuild.MaxTrustlineLimit, alice)
+	payouts, payout := newTestPayout(t, ledger, treasury, alice)
+
+	ledger.lost = 1
+	payout, err := payouts.Submit(payout.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := payout.Batches[0].Hash
+
+	// Another transaction from the paying account uses the sequence number,
+	// so the stored one can never land
+	submitAs(t, ledger, treasury, &txnbuild.Payment{Destination: alice.Address(), Amount: "1", Asset: txnbuild.NativeAsset{}})
+	if payout, err = payouts.Reconcile(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if payout.Batches[0].Status != domain.BatchFailed {
+		t.Fatalf("batch = %s, want failed", payout.Batches[0].Status)
+	}
+
+	// Only then is the batch signed again
+	if payout, err = payouts.Submit(payout.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if payout.Batches[0].Hash == stale || payout.Batches[0].Status != domain.BatchConfirmed {
+		t.Errorf("batch = %s with hash %s, want a new confirmed transaction", payout.Batches[0].Status, payout.Batches[0].Hash)
+	}
+	if got := received(t, ledger, alice, eur); got != money.MustParse("10") {
+		t.Errorf("alice received %s, want 10", got)
+	}
+}
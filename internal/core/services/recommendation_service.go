@@ -0,0 +1,336 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// nutrientTolerance is how far, as a fraction of the target, the nutrient
+	// level may drift before a dose or dilution is recommended
+	nutrientTolerance = 0.05
+	// phTolerance is how far pH may drift before an adjustment is recommended
+	phTolerance = 0.2
+	// recommendationEffectDelay is how long after a technician responds the
+	// effect of a recommendation is measured
+	recommendationEffectDelay = time.Hour
+)
+
+// DosingInputs overrides what the recommendation engine otherwise reads from
+// the farm. Zero values and nil pointers leave the farm's values in place.
+type DosingInputs struct {
+	VolumeLiters        float64
+	ConcentrateStrength float64
+	PHDownStrength      float64
+	PHUpStrength        float64
+	Nutrient            *float64 // EC in mS/cm
+	PH                  *float64
+	Stage               string
+}
+
+// RecommendationService recommends nutrient and pH doses for farm tanks and
+// measures how crop health responds when technicians follow or ignore them
+type RecommendationService struct {
+	db ports.MongoDB
+
+	mu       sync.Mutex
+	awaiting map[string][]*domain.DosingRecommendation // responded, unmeasured, by farm
+}
+
+// NewRecommendationService creates a new instance of the recommendation service
+func NewRecommendationService(db ports.MongoDB) *RecommendationService {
+	return &RecommendationService{
+		db:       db,
+		awaiting: make(map[string][]*domain.DosingRecommendation),
+	}
+}
+
+// Load reads the recommendations whose effect is still to be measured
+func (s *RecommendationService) Load() error {
+	recommendations, err := s.db.RetrieveUnmeasuredRecommendations()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range recommendations {
+		recommendation := &recommendations[i]
+		s.awaiting[recommendation.FarmID] = append(s.awaiting[recommendation.FarmID], recommendation)
+	}
+	return nil
+}
+
+// Issue works out a recommendation like Recommend and stores it, so the
+// technician's response to it can be recorded
+func (s *RecommendationService) Issue(farmID string, inputs DosingInputs) (*domain.DosingRecommendation, error) {
+	recommendation, err := s.Recommend(farmID, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.SaveRecommendation(recommendation); err != nil {
+		return nil, err
+	}
+	return recommendation, nil
+}
+
+// Recommend works out the doses that bring the farm's tank to the targets of
+// the crop's current stage without storing them. Dilution or concentrate
+// comes first, pH is adjusted after it as nutrients shift pH.
+func (s *RecommendationService) Recommend(farmID string, inputs DosingInputs) (*domain.DosingRecommendation, error) {
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := s.db.GetCropSpecification(farm.CropType)
+	if err != nil {
+		return nil, errors.New("crop specification not found")
+	}
+
+	var reservoir domain.Reservoir
+	if farm.Reservoir != nil {
+		reservoir = *farm.Reservoir
+	}
+	if inputs.VolumeLiters > 0 {
+		reservoir.VolumeLiters = inputs.VolumeLiters
+	}
+	if inputs.ConcentrateStrength > 0 {
+		reservoir.ConcentrateStrength = inputs.ConcentrateStrength
+	}
+	if inputs.PHDownStrength > 0 {
+		reservoir.PHDownStrength = inputs.PHDownStrength
+	}
+	if inputs.PHUpStrength > 0 {
+		reservoir.PHUpStrength = inputs.PHUpStrength
+	}
+	if reservoir.VolumeLiters <= 0 {
+		return nil, errors.New("tank volume is required")
+	}
+
+	// Stage targets apply from the day the stage starts
+	day := int(time.Since(farm.PlantingDate).Hours() / 24)
+	var stage *domain.CropStage
+	if inputs.Stage != "" {
+		for i := range spec.Stages {
+			if spec.Stages[i].Name == inputs.Stage {
+				stage = &spec.Stages[i]
+			}
+		}
+		if stage == nil {
+			return nil, fmt.Errorf("crop %s has no stage %q", spec.Name, inputs.Stage)
+		}
+	} else if current, ok := spec.StageAt(day); ok {
+		stage = &current
+	}
+
+	latest := latestReading(farm.IoTData)
+	recommendation := &domain.DosingRecommendation{
+		FarmID:       farmID,
+		Reservoir:    reservoir,
+		Actions:      make([]domain.DosingAction, 0),
+		HealthBefore: farm.CurrentHealth,
+		CreatedAt:    time.Now(),
+	}
+	if stage != nil {
+		recommendation.Stage = stage.Name
+	}
+
+	nutrient, ok := inputs.Nutrient, inputs.Nutrient != nil
+	if !ok && latest != nil {
+		nutrient, ok = nutrientValue(*latest)
+	}
+	if !ok {
+		return nil, errors.New("no EC or nutrient level reading to base the recommendation on")
+	}
+	target, ok := stageTarget(spec, stage, domain.MetricEC)
+	if !ok {
+		target, ok = stageTarget(spec, stage, domain.MetricNutrientLevel)
+	}
+	if !ok || target.Optimal <= 0 {
+		return nil, errors.New("crop specification has no nutrient target")
+	}
+	recommendation.Nutrient = *nutrient
+	recommendation.TargetNutrient = target.Optimal
+
+	switch {
+	case *nutrient < target.Optimal*(1-nutrientTolerance):
+		if reservoir.ConcentrateStrength <= 0 {
+			return nil, errors.New("concentrate strength is required")
+		}
+		ml := (target.Optimal - *nutrient) / reservoir.ConcentrateStrength * reservoir.VolumeLiters
+		recommendation.Actions = append(recommendation.Actions, domain.DosingAction{Kind: domain.DoseConcentrate, Amount: round1(ml), Unit: "mL"})
+	case *nutrient > target.Optimal*(1+nutrientTolerance):
+		liters := reservoir.VolumeLiters * (*nutrient/target.Optimal - 1)
+		recommendation.Actions = append(recommendation.Actions, domain.DosingAction{Kind: domain.DoseWater, Amount: round1(liters), Unit: "L"})
+	}
+
+	ph, ok := inputs.PH, inputs.PH != nil
+	if !ok && latest != nil {
+		var v float64
+		if v, ok = latest.Value(domain.MetricPH); ok {
+			ph = &v
+		}
+	}
+	phTarget, hasTarget := stageTarget(spec, stage, domain.MetricPH)
+	if ok && hasTarget {
+		recommendation.PH = *ph
+		recommendation.TargetPH = phTarget.Optimal
+
+		switch {
+		case *ph > phTarget.Optimal+phTolerance:
+			if reservoir.PHDownStrength <= 0 {
+				return nil, errors.New("pH down strength is required")
+			}
+			ml := (*ph - phTarget.Optimal) / reservoir.PHDownStrength * reservoir.VolumeLiters
+			recommendation.Actions = append(recommendation.Actions, domain.DosingAction{Kind: domain.DosePHDown, Amount: round1(ml), Unit: "mL"})
+		case *ph < phTarget.Optimal-phTolerance:
+			if reservoir.PHUpStrength <= 0 {
+				return nil, errors.New("pH up strength is required")
+			}
+			ml := (phTarget.Optimal - *ph) / reservoir.PHUpStrength * reservoir.VolumeLiters
+			recommendation.Actions = append(recommendation.Actions, domain.DosingAction{Kind: domain.DosePHUp, Amount: round1(ml), Unit: "mL"})
+		}
+	}
+	return recommendation, nil
+}
+
+// Respond records whether a technician followed a recommendation. Its effect
+// on crop health is measured from the first reading an hour later.
+func (s *RecommendationService) Respond(id string, followed bool, by string) (*domain.DosingRecommendation, error) {
+	recommendation, err := s.db.RetrieveRecommendation(id)
+	if err != nil {
+		return nil, err
+	}
+	if recommendation.Followed != nil {
+		return nil, errors.New("recommendation has already been responded to")
+	}
+
+	now := time.Now()
+	recommendation.Followed = &followed
+	recommendation.RespondedBy = by
+	recommendation.RespondedAt = &now
+	if err := s.db.UpdateRecommendation(recommendation); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.awaiting[recommendation.FarmID] = append(s.awaiting[recommendation.FarmID], recommendation)
+	s.mu.Unlock()
+	return recommendation, nil
+}
+
+// History retrieves a farm's recommendations in [from, to) along with the
+// average health change after the ones that were followed and ignored
+func (s *RecommendationService) History(farmID string, from, to time.Time) ([]domain.DosingRecommendation, domain.RecommendationSummary, error) {
+	var summary domain.RecommendationSummary
+	recommendations, err := s.db.RetrieveRecommendations(farmID, from, to)
+	if err != nil {
+		return []domain.DosingRecommendation{}, summary, err
+	}
+
+	var followedGain, ignoredGain float64
+	for _, r := range recommendations {
+		if r.Followed == nil || r.HealthAfter == nil {
+			continue
+		}
+		gain := float64(*r.HealthAfter - r.HealthBefore)
+		if *r.Followed {
+			summary.Followed.Count++
+			followedGain += gain
+		} else {
+			summary.Ignored.Count++
+			ignoredGain += gain
+		}
+	}
+	if summary.Followed.Count > 0 {
+		summary.Followed.AverageHealthGain = followedGain / float64(summary.Followed.Count)
+	}
+	if summary.Ignored.Count > 0 {
+		summary.Ignored.AverageHealthGain = ignoredGain / float64(summary.Ignored.Count)
+	}
+	return recommendations, summary, nil
+}
+
+// ReadingAccepted measures the effect of the farm's recommendations that were
+// responded to long enough ago
+func (s *RecommendationService) ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining := s.awaiting[farmID][:0]
+	for _, recommendation := range s.awaiting[farmID] {
+		if reading.Timestamp.Before(recommendation.RespondedAt.Add(recommendationEffectDelay)) {
+			remaining = append(remaining, recommendation)
+			continue
+		}
+
+		health := reading.CropHealth
+		measuredAt := reading.Timestamp
+		recommendation.HealthAfter = &health
+		recommendation.MeasuredAt = &measuredAt
+		if nutrient, ok := nutrientValue(reading); ok {
+			recommendation.NutrientAfter = nutrient
+		}
+		if ph, ok := reading.Value(domain.MetricPH); ok {
+			recommendation.PHAfter = &ph
+		}
+
+		if err := s.db.UpdateRecommendation(recommendation); err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to record effect of recommendation %s: %v", recommendation.ID.Hex(), err))
+			remaining = append(remaining, recommendation)
+		}
+	}
+
+	if len(remaining) == 0 {
+		delete(s.awaiting, farmID)
+	} else {
+		s.awaiting[farmID] = remaining
+	}
+}
+
+// stageTarget returns the target for a metric in the given stage, falling
+// back to the crop-wide target
+func stageTarget(spec domain.CropSpecification, stage *domain.CropStage, metric string) (domain.MetricTarget, bool) {
+	if stage != nil {
+		for _, t := range stage.Targets {
+			if t.Metric == metric {
+				return t, true
+			}
+		}
+	}
+	return spec.Target(metric)
+}
+
+// nutrientValue returns the EC of a reading, or its nutrient level when it
+// carries no EC; both are on the mS/cm scale
+func nutrientValue(reading domain.IoTReading) (*float64, bool) {
+	if v, ok := reading.Value(domain.MetricEC); ok {
+		return &v, true
+	}
+	if v, ok := reading.Value(domain.MetricNutrientLevel); ok {
+		return &v, true
+	}
+	return nil, false
+}
+
+// latestReading returns the most recent of the readings, if any
+func latestReading(readings []domain.IoTReading) *domain.IoTReading {
+	var latest *domain.IoTReading
+	for i := range readings {
+		if latest == nil || readings[i].Timestamp.After(latest.Timestamp) {
+			latest = &readings[i]
+		}
+	}
+	return latest
+}
+
+// round1 rounds to one decimal, the precision technicians measure doses in
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"testing"
+)
+
+func TestRecommendStoresOnlyIssued(t *testing.T) {
+	db := newMemoryDB()
+	db.specs["lettuce"] = domain.CropSpecification{Name: "lettuce", Targets: []domain.MetricTarget{{Metric: domain.MetricEC, Optimal: 2}}}
+	db.putFarm(domain.VerticalFarm{ID: "farm-1", CropType: "lettuce", Reservoir: &domain.Reservoir{VolumeLiters: 100, ConcentrateStrength: 1}})
+	recommendations := NewRecommendationService(db)
+	ec := 1.0
+
+	previewed, err := recommendations.Recommend("farm-1", DosingInputs{Nutrient: &ec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previewed.Actions) != 1 || previewed.Actions[0].Kind != domain.DoseConcentrate || previewed.Actions[0].Amount != 100 {
+		t.Errorf("actions = %+v, want 100 mL of concentrate", previewed.Actions)
+	}
+	if len(db.dosing) != 0 {
+		t.Fatalf("previewing stored %d recommendations", len(db.dosing))
+	}
+
+	issued, err := recommendations.Issue("farm-1", DosingInputs{Nutrient: &ec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.dosing) != 1 || db.dosing[0].ID != issued.ID || issued.ID.IsZero() {
+		t.Errorf("issuing stored %+v", db.dosing)
+	}
+}
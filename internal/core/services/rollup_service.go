@@ -0,0 +1,193 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// rollupResolutions are maintained for every farm, finest first
+var rollupResolutions = []string{domain.Resolution5Min, domain.ResolutionHourly, domain.ResolutionDaily}
+
+// RollupService maintains downsampled aggregates of farm readings
+type RollupService struct {
+	db    ports.MongoDB
+	farms *FarmManagementSystemService
+}
+
+// NewRollupService creates a new instance of the rollup service. Rebuilds
+// pause the ingest of the farm service.
+func NewRollupService(db ports.MongoDB, farms *FarmManagementSystemService) *RollupService {
+	return &RollupService{db: db, farms: farms}
+}
+
+// ReadingAccepted folds every accepted reading into the farm's rollups
+func (s *RollupService) ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading) {
+	if err := s.Record(farmID, reading); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to update rollups for farm %s: %v", farmID, err))
+	}
+}
+
+// Record adds a reading to the 5-minute, hourly and daily buckets it falls in
+func (s *RollupService) Record(farmID string, reading domain.IoTReading) error {
+	values := reading.MetricValues()
+	for _, resolution := range rollupResolutions {
+		bucket := reading.Timestamp.UTC().Truncate(domain.ResolutionDurations[resolution])
+		if err := s.db.UpsertRollup(farmID, resolution, bucket, values, reading.CropHealth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuild recomputes the rollups of a farm from its readings and replaces
+// the stored ones with them. Ingest of the farm is paused meanwhile, so no
+// reading is lost or counted twice.
+func (s *RollupService) Rebuild(farmID string) error {
+	resume := s.farms.PauseIngest(farmID)
+	defer resume()
+
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return err
+	}
+	archived, err := s.db.RetrieveReadings(farmID, time.Time{}, time.Now().Add(24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	// Readings kept on the farm may be archived too
+	type readingKey struct {
+		timestamp int64
+		deviceID  string
+	}
+	readings := append([]domain.IoTReading(nil), farm.IoTData...)
+	kept := make(map[readingKey]bool, len(farm.IoTData))
+	for _, reading := range farm.IoTData {
+		kept[readingKey{reading.Timestamp.UnixNano(), reading.DeviceID}] = true
+	}
+	for _, reading := range archived {
+		if !kept[readingKey{reading.Timestamp.UnixNano(), reading.DeviceID}] {
+			readings = append(readings, reading)
+		}
+	}
+
+	rollups := aggregate(farmID, readings)
+	if err := s.db.RemoveRollups(farmID); err != nil {
+		return err
+	}
+	return s.db.MergeRollups(rollups)
+}
+
+// RecordBatch folds many readings into the rollups at once, merging each
+// bucket in a single write instead of one per reading
+func (s *RollupService) RecordBatch(farmID string, readings []domain.IoTReading) error {
+	return s.db.MergeRollups(aggregate(farmID, readings))
+}
+
+// aggregate folds readings into rollup buckets of every resolution
+func aggregate(farmID string, readings []domain.IoTReading) []domain.ReadingRollup {
+	type bucketKey struct {
+		resolution string
+		start      time.Time
+	}
+
+	buckets := make(map[bucketKey]*domain.ReadingRollup)
+	order := make([]bucketKey, 0)
+	for _, reading := range readings {
+		values := reading.MetricValues()
+		for _, resolution := range rollupResolutions {
+			key := bucketKey{resolution, reading.Timestamp.UTC().Truncate(domain.ResolutionDurations[resolution])}
+			rollup, ok := buckets[key]
+			if !ok {
+				rollup = &domain.ReadingRollup{
+					FarmID:      farmID,
+					Resolution:  resolution,
+					BucketStart: key.start,
+					Metrics:     make(map[string]domain.MetricAggregate),
+				}
+				buckets[key] = rollup
+				order = append(order, key)
+			}
+			rollup.Health = foldAggregate(rollup.Health, float64(reading.CropHealth))
+			for metric, value := range values {
+				rollup.Metrics[metric] = foldAggregate(rollup.Metrics[metric], value)
+			}
+		}
+	}
+
+	rollups := make([]domain.ReadingRollup, len(order))
+	for i, key := range order {
+		rollups[i] = *buckets[key]
+	}
+	return rollups
+}
+
+// foldAggregate adds a value to an aggregate
+func foldAggregate(agg domain.MetricAggregate, value float64) domain.MetricAggregate {
+	if agg.Count == 0 || value < agg.Min {
+		agg.Min = value
+	}
+	if agg.Count == 0 || value > agg.Max {
+		agg.Max = value
+	}
+	agg.Sum += value
+	agg.Count++
+	return agg
+}
+
+// Query returns the rollups of a farm between from and to, along with the
+// data gaps in that range. When resolution is empty it is picked from the
+// length of the range so charts stay within a few hundred points.
+func (s *RollupService) Query(farmID string, from, to time.Time, resolution string) (*domain.RollupSeries, error) {
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	if resolution == "" {
+		resolution = ResolutionFor(to.Sub(from))
+	}
+	width, ok := domain.ResolutionDurations[resolution]
+	if !ok {
+		return nil, fmt.Errorf("unknown resolution %q", resolution)
+	}
+
+	// Include the bucket that from falls in
+	start := from.UTC().Truncate(width)
+	rollups, err := s.db.RetrieveRollups(farmID, resolution, start, to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	gaps, err := s.db.RetrieveDataGaps(farmID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &domain.RollupSeries{
+		Resolution: resolution,
+		Buckets:    rollups,
+		Gaps:       gaps,
+	}
+	if series.Buckets == nil {
+		series.Buckets = []domain.ReadingRollup{}
+	}
+	if series.Gaps == nil {
+		series.Gaps = []domain.DataGap{}
+	}
+	return series, nil
+}
+
+// ResolutionFor picks the rollup resolution for a time range
+func ResolutionFor(span time.Duration) string {
+	switch {
+	case span <= 2*24*time.Hour:
+		return domain.Resolution5Min
+	case span <= 60*24*time.Hour:
+		return domain.ResolutionHourly
+	default:
+		return domain.ResolutionDaily
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestRebuildCountsArchivedReadingsOnce(t *testing.T) {
+	db := newMemoryDB()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	reading := func(hour int, device string) domain.IoTReading {
+		return domain.IoTReading{Timestamp: day.Add(time.Duration(hour) * time.Hour), DeviceID: device, Temperature: 20, CropHealth: 90}
+	}
+	// The farm keeps its latest readings, which are archived as well
+	db.putFarm(domain.VerticalFarm{ID: "farm-1", IoTData: []domain.IoTReading{reading(2, "a"), reading(3, "a")}})
+	db.archive["farm-1"] = []domain.IoTReading{reading(1, "a"), reading(2, "a"), reading(2, "b"), reading(3, "a")}
+	db.rollups = []domain.ReadingRollup{{FarmID: "farm-1", Resolution: domain.ResolutionDaily, BucketStart: day}}
+
+	rollups := NewRollupService(db, NewFarmManagementSystemService(db, nil))
+	if err := rollups.Rebuild("farm-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var daily []domain.ReadingRollup
+	for _, rollup := range db.rollups {
+		if rollup.Resolution == domain.ResolutionDaily {
+			daily = append(daily, rollup)
+		}
+	}
+	if len(daily) != 1 || daily[0].Health.Count != 4 {
+		t.Fatalf("daily rollups = %+v, want one bucket of 4 readings", daily)
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"context"
+	"sync"
+	"time"
+)
+
+// OutputState is the state of one output of a simulated device
+type OutputState struct {
+	On    bool      `json:"on"`
+	Value float64   `json:"value"`           // current level, or total dosed quantity
+	Until time.Time `json:"until,omitempty"` // when a timed run switches off
+}
+
+// SimulatedDevice stands in for a physical controller. It polls the command
+// queue, applies commands to its outputs and acknowledges them, so the
+// actuator flow can be exercised without hardware.
+type SimulatedDevice struct {
+	actuators *ActuatorService
+	deviceID  string
+
+	mu      sync.Mutex
+	outputs map[string]*OutputState
+	failing map[string]bool
+}
+
+// NewSimulatedDevice creates a simulated device for a registered device ID
+func NewSimulatedDevice(actuators *ActuatorService, deviceID string) *SimulatedDevice {
+	return &SimulatedDevice{
+		actuators: actuators,
+		deviceID:  deviceID,
+		outputs:   make(map[string]*OutputState),
+		failing:   make(map[string]bool),
+	}
+}
+
+// FailOutput makes every later command for the output fail, to exercise
+// fault handling
+func (d *SimulatedDevice) FailOutput(key string, fail bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.failing[key] = fail
+}
+
+// Poll fetches the pending commands, applies them and acknowledges each one
+func (d *SimulatedDevice) Poll(now time.Time) ([]domain.ActuatorCommand, error) {
+	commands, err := d.actuators.Pending(d.deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tick(now)
+	acknowledged := make([]domain.ActuatorCommand, 0, len(commands))
+	for _, command := range commands {
+		executed, result := d.apply(command, now)
+		ack, err := d.actuators.Acknowledge(command.ID.Hex(), executed, result)
+		if err != nil {
+			return acknowledged, err
+		}
+		acknowledged = append(acknowledged, *ack)
+	}
+	return acknowledged, nil
+}
+
+// Run polls for commands every interval until the context is cancelled
+func (d *SimulatedDevice) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, _ = d.Poll(now)
+		}
+	}
+}
+
+// State returns the current state of an output
+func (d *SimulatedDevice) State(key string, now time.Time) OutputState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tick(now)
+	if state, ok := d.outputs[key]; ok {
+		return *state
+	}
+	return OutputState{}
+}
+
+// tick switches off timed runs that have finished. Callers must hold d.mu.
+func (d *SimulatedDevice) tick(now time.Time) {
+	for _, state := range d.outputs {
+		if state.On && !state.Until.IsZero() && !now.Before(state.Until) {
+			state.On = false
+			state.Until = time.Time{}
+		}
+	}
+}
+
+// apply executes a command on the simulated outputs. Callers must hold d.mu.
+func (d *SimulatedDevice) apply(command domain.ActuatorCommand, now time.Time) (bool, string) {
+	if d.failing[command.Output] {
+		return false, "simulated fault"
+	}
+
+	state, ok := d.outputs[command.Output]
+	if !ok {
+		state = &OutputState{}
+		d.outputs[command.Output] = state
+	}
+
+	switch command.Action {
+	case domain.ActionOn:
+		state.On = true
+		state.Until = time.Time{}
+		if command.DurationSeconds > 0 {
+			state.Until = now.Add(time.Duration(command.DurationSeconds) * time.Second)
+		}
+	case domain.ActionOff:
+		state.On = false
+		state.Until = time.Time{}
+	case domain.ActionSet:
+		state.Value = command.Value
+		state.On = command.Value > 0
+	case domain.ActionDose:
+		state.Value += command.Value
+	}
+	return true, "ok"
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// errTokenizationDisabled is returned when no issuing account is configured
+var errTokenizationDisabled = errors.New("tokenization is not configured")
+
+// maxAssetCodeAttempts bounds the codes tried for a farm before giving up
+const maxAssetCodeAttempts = 8
+
+// TokenService represents farm shares as Stellar assets. Every farm gets
+// its own asset from the platform's issuing account, and the farm's owner
+// records stay authoritative: syncing pays tokens to owners holding fewer
+// than their shares and claws back tokens held beyond them, so trades,
+// refunds and lockups on the platform carry through to the ledger.
+type TokenService struct {
+	db         ports.MongoDB
+	ledger     stellar.Ledger
+	issuer     *keypair.Full // nil when tokenization is not configured
+	passphrase string
+	mu         sync.Mutex // serialises issuer transactions
+}
+
+// NewTokenService creates a new instance of the token service. The issuer
+// signs issuance and clawback transactions on the network of passphrase.
+func NewTokenService(db ports.MongoDB, ledger stellar.Ledger, issuer *keypair.Full, passphrase string) *TokenService {
+	return &TokenService{db: db, ledger: ledger, issuer: issuer, passphrase: passphrase}
+}
+
+// Tokenize defines the share asset of a farm. The issuing account is made
+// revocable with clawback, which trustlines created afterwards inherit.
+func (s *TokenService) Tokenize(farmID string) (*domain.FarmToken, error) {
+	if s.issuer == nil {
+		return nil, errTokenizationDisabled
+	}
+	if _, err := s.db.GetFarm(farmID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, err := s.assetCode(farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := s.ledger.Account(s.issuer.Address())
+	if err != nil {
+		return nil, fmt.Errorf("issuing account: %v", err)
+	}
+	if !issuer.AuthRevocable || !issuer.ClawbackEnabled {
+		tx, err := stellar.NewTransaction(issuer, nil, &txnbuild.SetOptions{
+			SetFlags: []txnbuild.AccountFlag{txnbuild.AuthRevocable, txnbuild.AuthClawbackEnabled},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.submit(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	token := &domain.FarmToken{
+		FarmID:    farmID,
+		AssetCode: code,
+		Issuer:    s.issuer.Address(),
+		CreatedAt: time.Now(),
+	}
+	if err := s.db.SaveFarmToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// assetCode picks a code for a farm's asset that no other farm's asset
+// uses. Callers must hold s.mu.
+func (s *TokenService) assetCode(farmID string) (string, error) {
+	tokens, err := s.db.RetrieveFarmTokens()
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		if token.FarmID == farmID {
+			return "", errors.New("farm is already tokenized")
+		}
+		taken[token.AssetCode] = token.FarmID
+	}
+
+	for attempt := 0; attempt < maxAssetCodeAttempts; attempt++ {
+		code := stellar.AssetCode(farmID, attempt)
+		if owner, ok := taken[code]; ok {
+			logger.LogWarning(fmt.Sprintf("Asset code %s of farm %s is taken by farm %s", code, farmID, owner))
+			continue
+		}
+		return code, nil
+	}
+	return "", errors.New("no free asset code found for the farm")
+}
+
+// Token retrieves the share asset of a farm
+func (s *TokenService) Token(farmID string) (*domain.FarmToken, error) {
+	return s.db.RetrieveFarmToken(farmID)
+}
+
+// TrustlineTransaction builds the transaction in which an account trusts a
+// farm's asset, for the account holder to sign and submit. Owners need the
+// trustline before their tokens can be issued.
+func (s *TokenService) TrustlineTransaction(farmID, address string) (string, error) {
+	token, err := s.db.RetrieveFarmToken(farmID)
+	if err != nil {
+		return "", err
+	}
+	account, err := s.ledger.Account(address)
+	if err != nil {
+		return "", fmt.Errorf("account %s: %v", address, err)
+	}
+	asset := stellar.FarmAsset(token.AssetCode, token.Issuer)
+	if _, ok := account.Trustline(asset); ok {
+		return "", errors.New("account already trusts the farm's asset")
+	}
+
+	tx, err := stellar.NewTrustline(account, asset)
+	if err != nil {
+		return "", err
+	}
+	return tx.Base64()
+}
+
+// SubmitTrustline submits a trustline transaction signed by its account.
+// Only trustlines to farm assets, for accounts the session controls, are
+// relayed.
+func (s *TokenService) SubmitTrustline(session *domain.AuthSession, envelope string) (string, error) {
+	if s.issuer == nil {
+		return "", errTokenizationDisabled
+	}
+	tx, err := stellar.ParseTransaction(envelope)
+	if err != nil {
+		return "", err
+	}
+	for _, op := range tx.Operations() {
+		trust, ok := op.(*txnbuild.ChangeTrust)
+		if !ok || trust.Line.GetIssuer() != s.issuer.Address() {
+			return "", errors.New("only trustlines to farm assets can be submitted")
+		}
+		source := op.GetSourceAccount()
+		if source == "" {
+			source = tx.SourceAccount().AccountID
+		}
+		if err := checkActsFor(s.db, session, source); err != nil {
+			return "", err
+		}
+	}
+	return s.ledger.Submit(tx)
+}
+
+// Sync brings the token balances of a farm in line with its owners
+func (s *TokenService) Sync(farmID string) (*domain.TokenSyncReport, error) {
+	if s.issuer == nil {
+		return nil, errTokenizationDisabled
+	}
+	token, err := s.db.RetrieveFarmToken(farmID)
+	if err != nil {
+		return nil, err
+	}
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	asset := stellar.FarmAsset(token.AssetCode, token.Issuer)
+	holdings, err := s.ledger.Holders(asset)
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[string]domain.Shares, len(holdings))
+	for _, holding := range holdings {
+		shares, err := stellar.AmountToShares(holding.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("balance of %s: %v", holding.AccountID, err)
+		}
+		held[holding.AccountID] = shares
+	}
+	recorded := make(map[string]domain.Shares)
+	for _, owner := range farm.Owners {
+		recorded[owner.Address] += owner.Shares
+	}
+
+	addresses := make([]string, 0, len(held)+len(recorded))
+	for address := range recorded {
+		addresses = append(addresses, address)
+	}
+	for address := range held {
+		if _, ok := recorded[address]; !ok {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	report := &domain.TokenSyncReport{
+		FarmID:            farmID,
+		AssetCode:         token.AssetCode,
+		Issued:            []domain.TokenMovement{},
+		Clawed:            []domain.TokenMovement{},
+		AwaitingTrustline: []string{},
+		Transactions:      []string{},
+	}
+	var ops []txnbuild.Operation
+	for _, address := range addresses {
+		want, have := recorded[address], held[address]
+		_, trusts := held[address]
+		switch {
+		case want > have && !trusts:
+			report.AwaitingTrustline = append(report.AwaitingTrustline, address)
+		case want > have:
+			ops = append(ops, &txnbuild.Payment{Destination: address, Amount: stellar.SharesToAmount(want - have), Asset: asset})
+			report.Issued = append(report.Issued, domain.TokenMovement{Address: address, Shares: want - have})
+		case want < have:
+			ops = append(ops, &txnbuild.Clawback{From: address, Amount: stellar.SharesToAmount(have - want), Asset: asset})
+			report.Clawed = append(report.Clawed, domain.TokenMovement{Address: address, Shares: have - want})
+		}
+	}
+
+	for start := 0; start < len(ops); start += stellar.MaxOperations {
+		end := start + stellar.MaxOperations
+		if end > len(ops) {
+			end = len(ops)
+		}
+		issuer, err := s.ledger.Account(s.issuer.Address())
+		if err != nil {
+			return nil, fmt.Errorf("issuing account: %v", err)
+		}
+		tx, err := stellar.NewTransaction(issuer, txnbuild.MemoText("sync "+token.AssetCode), ops[start:end]...)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := s.submit(tx)
+		if err != nil {
+			return nil, err
+		}
+		report.Transactions = append(report.Transactions, hash)
+	}
+
+	now := time.Now()
+	report.SyncedAt = now
+	token.LastSyncedAt = &now
+	if err := s.db.UpdateFarmToken(token); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Run syncs every tokenized farm each interval until the context is
+// cancelled
+func (s *TokenService) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SyncAll()
+		}
+	}
+}
+
+// SyncAll syncs every tokenized farm, logging failures
+func (s *TokenService) SyncAll() {
+	if s.issuer == nil {
+		return
+	}
+	tokens, err := s.db.RetrieveFarmTokens()
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to load farm tokens: %v", err))
+		return
+	}
+	for _, token := range tokens {
+		if _, err := s.Sync(token.FarmID); err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to sync tokens of farm %s: %v", token.FarmID, err))
+		}
+	}
+}
+
+// submit signs a transaction with the issuer and submits it
+func (s *TokenService) submit(tx *txnbuild.Transaction) (string, error) {
+	signed, err := tx.Sign(s.passphrase, s.issuer)
+	if err != nil {
+		return "", err
+	}
+	return s.ledger.Submit(signed)
+}
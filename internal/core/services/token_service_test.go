@@ -0,0 +1,216 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/stellar"
+	"0xFarms-backend/pkg/money"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// newTestLedger returns an in-memory ledger with a funded account for each key
+func newTestLedger(t *testing.T, keys ...*keypair.Full) *stellar.MemoryLedger {
+	t.Helper()
+	ledger := stellar.NewMemoryLedger(network.TestNetworkPassphrase)
+	for _, key := range keys {
+		if err := ledger.Fund(key.Address(), "100"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ledger
+}
+
+// trust has the holder of key trust the farm's asset through the service
+func trust(t *testing.T, tokens *TokenService, farmID string, key *keypair.Full) {
+	t.Helper()
+	envelope, err := tokens.TrustlineTransaction(farmID, key.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.ParseTransaction(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, key); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := tx.Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.SubmitTrustline(&domain.AuthSession{AccountID: key.Address()}, signed); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// balances returns the token balance of every holder of the farm's asset
+func balances(t *testing.T, ledger stellar.Ledger, token *domain.FarmToken) map[string]domain.Shares {
+	t.Helper()
+	holdings, err := ledger.Holders(stellar.FarmAsset(token.AssetCode, token.Issuer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	held := make(map[string]domain.Shares)
+	for _, holding := range holdings {
+		shares, err := stellar.AmountToShares(holding.Amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		held[holding.AccountID] = shares
+	}
+	return held
+}
+
+func TestTokenSync(t *testing.T) {
+	issuer, alice, bob := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, issuer, alice, bob)
+	db := newMemoryDB()
+	farm := domain.VerticalFarm{ID: "farm-1", Owners: []domain.Owner{
+		{ID: "owner-a", Address: alice.Address(), Shares: 600_000},
+		{ID: "owner-b", Address: bob.Address(), Shares: 400_000},
+	}}
+	db.putFarm(farm)
+	tokens := NewTokenService(db, ledger, issuer, network.TestNetworkPassphrase)
+
+	token, err := tokens.Tokenize("farm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Tokenize("farm-1"); err == nil {
+		t.Fatal("farm tokenized twice")
+	}
+	account, err := ledger.Account(issuer.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !account.AuthRevocable || !account.ClawbackEnabled {
+		t.Fatal("issuer cannot claw back tokens")
+	}
+
+	// Only owners trusting the asset receive tokens
+	trust(t, tokens, "farm-1", alice)
+	report, err := tokens.Sync("farm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issued) != 1 || report.Issued[0] != (domain.TokenMovement{Address: alice.Address(), Shares: 600_000}) {
+		t.Fatalf("issued %+v", report.Issued)
+	}
+	if len(report.AwaitingTrustline) != 1 || report.AwaitingTrustline[0] != bob.Address() {
+		t.Fatalf("awaiting trustline %v", report.AwaitingTrustline)
+	}
+
+	// Alice sells a tenth of the farm to Bob on the platform
+	trust(t, tokens, "farm-1", bob)
+	farm.Owners[0].Shares, farm.Owners[1].Shares = 500_000, 500_000
+	db.putFarm(farm)
+	report, err = tokens.Sync("farm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Clawed) != 1 || report.Clawed[0] != (domain.TokenMovement{Address: alice.Address(), Shares: 100_000}) {
+		t.Fatalf("clawed back %+v", report.Clawed)
+	}
+	if len(report.Issued) != 1 || report.Issued[0] != (domain.TokenMovement{Address: bob.Address(), Shares: 500_000}) {
+		t.Fatalf("issued %+v", report.Issued)
+	}
+	held := balances(t, ledger, token)
+	if held[alice.Address()] != 500_000 || held[bob.Address()] != 500_000 {
+		t.Fatalf("balances %v", held)
+	}
+
+	// Balances in line with the owners need no transactions
+	report, err = tokens.Sync("farm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issued)+len(report.Clawed)+len(report.Transactions) != 0 {
+		t.Fatalf("second sync moved tokens: %+v", report)
+	}
+}
+
+func TestTokenizeAssetCodeCollision(t *testing.T) {
+	issuer := keypair.MustRandom()
+	ledger := newTestLedger(t, issuer)
+	db := newMemoryDB()
+	db.putFarm(domain.VerticalFarm{ID: "farm-1"})
+	tokens := NewTokenService(db, ledger, issuer, network.TestNetworkPassphrase)
+
+	// Another farm already holds the code farm-1 would get first
+	taken := stellar.AssetCode("farm-1", 0)
+	if err := db.SaveFarmToken(&domain.FarmToken{FarmID: "farm-2", AssetCode: taken, Issuer: issuer.Address()}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.Tokenize("farm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AssetCode == taken || token.AssetCode != stellar.AssetCode("farm-1", 1) {
+		t.Fatalf("asset code %s, want the second candidate", token.AssetCode)
+	}
+	if len(token.AssetCode) > 12 {
+		t.Fatalf("asset code %s is too long", token.AssetCode)
+	}
+}
+
+func TestTrustlineRelayRejectsOtherOperations(t *testing.T) {
+	issuer, alice := keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, issuer, alice)
+	tokens := NewTokenService(newMemoryDB(), ledger, issuer, network.TestNetworkPassphrase)
+
+	account, err := ledger.Account(alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.NewPurchasePayment(account, issuer.Address(), txnbuild.NativeAsset{}, money.MustParse("1"), "investment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, alice); err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.SubmitTrustline(&domain.AuthSession{AccountID: alice.Address()}, envelope); err == nil {
+		t.Fatal("payment relayed as a trustline")
+	}
+}
+
+func TestTrustlineRelayRequiresAccountSession(t *testing.T) {
+	issuer, alice, mallory := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	ledger := newTestLedger(t, issuer, alice, mallory)
+	db := newMemoryDB()
+	db.putFarm(domain.VerticalFarm{ID: "farm-1"})
+	tokens := NewTokenService(db, ledger, issuer, network.TestNetworkPassphrase)
+	if _, err := tokens.Tokenize("farm-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := tokens.TrustlineTransaction("farm-1", alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := stellar.ParseTransaction(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, alice); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := tx.Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.SubmitTrustline(&domain.AuthSession{AccountID: mallory.Address()}, signed); err == nil {
+		t.Fatal("trustline of another account relayed")
+	}
+	if _, err := tokens.SubmitTrustline(&domain.AuthSession{AccountID: alice.Address()}, signed); err != nil {
+		t.Fatal(err)
+	}
+}
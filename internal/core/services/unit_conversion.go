@@ -0,0 +1,157 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/pkg/units"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// unitPresets maps named unit systems onto preferred units per metric.
+// Metrics missing from a preset are rendered in their canonical unit.
+var unitPresets = map[string]map[string]string{
+	"metric": {},
+	"imperial": {
+		domain.MetricTemperature: "F",
+		domain.MetricWaterTemp:   "F",
+	},
+	"tds": {
+		domain.MetricNutrientLevel: "ppm500",
+		domain.MetricEC:            "ppm500",
+	},
+}
+
+// PreferredUnits combines a named unit preset with per-metric overrides
+func PreferredUnits(preset string, overrides map[string]string) (map[string]string, error) {
+	preferred := make(map[string]string)
+	if preset != "" {
+		base, ok := unitPresets[strings.ToLower(preset)]
+		if !ok {
+			return nil, fmt.Errorf("unknown unit preset %q", preset)
+		}
+		for metric, unit := range base {
+			preferred[metric] = unit
+		}
+	}
+	for metric, unit := range overrides {
+		preferred[metric] = unit
+	}
+	return preferred, nil
+}
+
+// NormalizeReading converts the values of a reading from the declared units to
+// each metric's canonical unit, in place
+func (r *MetricRegistry) NormalizeReading(reading *domain.IoTReading, declared map[string]string) error {
+	// Air temperature goes first, absolute humidity needs it in Celsius
+	for _, key := range temperatureFirst(declared) {
+		metric, ok := r.Get(key)
+		if !ok {
+			return fmt.Errorf("unregistered metric %q", key)
+		}
+		value, ok := reading.Value(key)
+		if !ok {
+			continue
+		}
+
+		converted, err := r.convert(metric, value, declared[key], metric.Unit, *reading)
+		if err != nil {
+			return err
+		}
+		reading.SetValue(key, converted)
+	}
+	return nil
+}
+
+// RenderReading returns a copy of the reading with its values converted from
+// canonical units to the preferred ones
+func (r *MetricRegistry) RenderReading(reading domain.IoTReading, preferred map[string]string) (domain.IoTReading, error) {
+	rendered := reading
+	if reading.Metrics != nil {
+		rendered.Metrics = make(map[string]float64, len(reading.Metrics))
+		for key, value := range reading.Metrics {
+			rendered.Metrics[key] = value
+		}
+	}
+
+	for key, unit := range preferred {
+		metric, ok := r.Get(key)
+		if !ok {
+			return domain.IoTReading{}, fmt.Errorf("unregistered metric %q", key)
+		}
+		value, ok := reading.Value(key)
+		if !ok {
+			continue
+		}
+
+		// Conversions read the canonical reading so absolute humidity is
+		// computed from the temperature in Celsius
+		converted, err := r.convert(metric, value, metric.Unit, unit, reading)
+		if err != nil {
+			return domain.IoTReading{}, err
+		}
+		rendered.SetValue(key, converted)
+	}
+	return rendered, nil
+}
+
+// RenderedUnits returns the unit every registered metric is rendered in once
+// the preferred units are applied
+func (r *MetricRegistry) RenderedUnits(preferred map[string]string) map[string]string {
+	rendered := make(map[string]string)
+	for _, metric := range r.List() {
+		rendered[metric.Key] = metric.Unit
+		if unit, ok := preferred[metric.Key]; ok {
+			rendered[metric.Key] = unit
+		}
+	}
+	return rendered
+}
+
+// convert converts a metric value between two units. Conversions between
+// relative and absolute humidity use the air temperature of the reading,
+// which must already be in Celsius.
+func (r *MetricRegistry) convert(metric domain.MetricDefinition, value float64, from, to string, reading domain.IoTReading) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return value, nil
+	}
+
+	fromAbsolute := strings.EqualFold(from, units.AbsoluteHumidity)
+	toAbsolute := strings.EqualFold(to, units.AbsoluteHumidity)
+	if metric.Key == domain.MetricHumidity && (fromAbsolute || toAbsolute) {
+		tempC, ok := reading.Value(domain.MetricTemperature)
+		if !ok {
+			return 0, errors.New("absolute humidity conversion needs an air temperature reading")
+		}
+		if fromAbsolute {
+			return r.convert(metric, units.AbsoluteToRelative(value, tempC), "%", to, reading)
+		}
+		relative, err := r.convert(metric, value, from, "%", reading)
+		if err != nil {
+			return 0, err
+		}
+		return units.RelativeToAbsolute(relative, tempC), nil
+	}
+
+	if metric.Dimension == "" {
+		return units.Convert(value, from, to)
+	}
+	return units.ConvertWithin(metric.Dimension, value, from, to)
+}
+
+// temperatureFirst returns the keys of the declared units with air
+// temperature ahead of every other metric
+func temperatureFirst(declared map[string]string) []string {
+	keys := make([]string, 0, len(declared))
+	for key := range declared {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == domain.MetricTemperature || keys[j] == domain.MetricTemperature {
+			return keys[i] == domain.MetricTemperature
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// UtilityService tracks the energy and water farms use and what it costs
+type UtilityService struct {
+	db ports.MongoDB
+}
+
+// NewUtilityService creates a new instance of the utility service
+func NewUtilityService(db ports.MongoDB) *UtilityService {
+	return &UtilityService{db: db}
+}
+
+// CreateFacility groups farms that share meters. A farm can be in only one
+// facility.
+func (s *UtilityService) CreateFacility(facility domain.Facility) (*domain.Facility, error) {
+	if facility.Name == "" {
+		return nil, errors.New("facility name is required")
+	}
+	if len(facility.FarmIDs) == 0 {
+		return nil, errors.New("a facility needs at least one farm")
+	}
+	for _, farmID := range facility.FarmIDs {
+		if _, err := s.db.GetFarm(farmID); err != nil {
+			return nil, fmt.Errorf("farm %s: %v", farmID, err)
+		}
+		if existing, err := s.db.RetrieveFacilityOfFarm(farmID); err == nil {
+			return nil, fmt.Errorf("farm %s already belongs to facility %s", farmID, existing.Name)
+		}
+	}
+
+	facility.CreatedAt = time.Now()
+	if err := s.db.SaveFacility(&facility); err != nil {
+		return nil, err
+	}
+	return &facility, nil
+}
+
+// GetFacility retrieves a facility by ID
+func (s *UtilityService) GetFacility(id string) (*domain.Facility, error) {
+	return s.db.RetrieveFacility(id)
+}
+
+// CreateTariff adds a tariff meters can be priced with
+func (s *UtilityService) CreateTariff(tariff domain.Tariff) (*domain.Tariff, error) {
+	if tariff.Name == "" {
+		return nil, errors.New("tariff name is required")
+	}
+	if err := validateUtility(tariff.Utility); err != nil {
+		return nil, err
+	}
+	if tariff.Currency == "" {
+		return nil, errors.New("tariff currency is required")
+	}
+	if _, err := time.LoadLocation(tariff.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+	if tariff.BasePrice < 0 {
+		return nil, errors.New("base price cannot be negative")
+	}
+	for _, p := range tariff.Periods {
+		if p.StartHour < 0 || p.StartHour > 23 || p.EndHour < 0 || p.EndHour > 24 {
+			return nil, fmt.Errorf("period %q hours must be between 0 and 24", p.Name)
+		}
+		if p.Price < 0 {
+			return nil, fmt.Errorf("period %q price cannot be negative", p.Name)
+		}
+		for _, d := range p.Weekdays {
+			if d < time.Sunday || d > time.Saturday {
+				return nil, fmt.Errorf("period %q has an invalid weekday %d", p.Name, d)
+			}
+		}
+	}
+
+	tariff.CreatedAt = time.Now()
+	if err := s.db.SaveTariff(&tariff); err != nil {
+		return nil, err
+	}
+	return &tariff, nil
+}
+
+// ListTariffs retrieves every tariff
+func (s *UtilityService) ListTariffs() ([]domain.Tariff, error) {
+	tariffs, err := s.db.RetrieveTariffs()
+	if err != nil {
+		return []domain.Tariff{}, err
+	}
+	if tariffs == nil {
+		tariffs = []domain.Tariff{}
+	}
+	return tariffs, nil
+}
+
+// CreateMeter registers a meter on a farm or a facility, priced with a tariff
+// for the same utility
+func (s *UtilityService) CreateMeter(meter domain.Meter) (*domain.Meter, error) {
+	if meter.Name == "" {
+		return nil, errors.New("meter name is required")
+	}
+	if err := validateUtility(meter.Utility); err != nil {
+		return nil, err
+	}
+	if (meter.FarmID == "") == (meter.FacilityID == "") {
+		return nil, errors.New("a meter belongs to either a farm or a facility")
+	}
+	if meter.FarmID != "" {
+		if _, err := s.db.GetFarm(meter.FarmID); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.db.RetrieveFacility(meter.FacilityID); err != nil {
+		return nil, err
+	}
+	tariff, err := s.db.RetrieveTariff(meter.TariffID)
+	if err != nil {
+		return nil, err
+	}
+	if tariff.Utility != meter.Utility {
+		return nil, fmt.Errorf("tariff %s prices %s, not %s", tariff.Name, tariff.Utility, meter.Utility)
+	}
+
+	meter.CreatedAt = time.Now()
+	if err := s.db.SaveMeter(&meter); err != nil {
+		return nil, err
+	}
+	return &meter, nil
+}
+
+// ListMeters retrieves the meters of a farm, including those of its facility
+func (s *UtilityService) ListMeters(farmID string) ([]domain.Meter, error) {
+	facilityID := ""
+	if facility, err := s.db.RetrieveFacilityOfFarm(farmID); err == nil {
+		facilityID = facility.ID.Hex()
+	}
+	meters, err := s.db.RetrieveMeters(farmID, facilityID)
+	if err != nil {
+		return []domain.Meter{}, err
+	}
+	if meters == nil {
+		meters = []domain.Meter{}
+	}
+	return meters, nil
+}
+
+// AddMeterReading records the register value of a meter
+func (s *UtilityService) AddMeterReading(meterID string, reading domain.MeterReading) (*domain.MeterReading, error) {
+	if _, err := s.db.RetrieveMeter(meterID); err != nil {
+		return nil, err
+	}
+	if reading.Value < 0 {
+		return nil, errors.New("meter value cannot be negative")
+	}
+	if reading.Timestamp.IsZero() {
+		reading.Timestamp = time.Now()
+	}
+
+	reading.MeterID = meterID
+	if err := s.db.SaveMeterReading(&reading); err != nil {
+		return nil, err
+	}
+	return &reading, nil
+}
+
+// MeterReadings retrieves the readings of a meter in [from, to]
+func (s *UtilityService) MeterReadings(meterID string, from, to time.Time) ([]domain.MeterReading, error) {
+	readings, err := s.db.RetrieveMeterReadings(meterID, from, to)
+	if err != nil {
+		return []domain.MeterReading{}, err
+	}
+	within := make([]domain.MeterReading, 0, len(readings))
+	for _, r := range readings {
+		if !r.Timestamp.Before(from) && !r.Timestamp.After(to) {
+			within = append(within, r)
+		}
+	}
+	return within, nil
+}
+
+// Consumption reports the energy and water a farm used in [from, to] and
+// what it cost. Facility meters are shared among the facility's farms in
+// proportion to their area.
+func (s *UtilityService) Consumption(farmID string, from, to time.Time) (*domain.ConsumptionReport, error) {
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	facilityID, share := "", 1.0
+	if facility, err := s.db.RetrieveFacilityOfFarm(farmID); err == nil {
+		facilityID = facility.ID.Hex()
+		var totalArea float64
+		for _, id := range facility.FarmIDs {
+			if member, err := s.db.GetFarm(id); err == nil {
+				totalArea += member.TotalArea
+			}
+		}
+		if totalArea > 0 {
+			share = farm.TotalArea / totalArea
+		}
+	}
+
+	meters, err := s.db.RetrieveMeters(farmID, facilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ConsumptionReport{FarmID: farmID, From: from, To: to}
+	tariffs := make(map[string]*domain.Tariff)
+	for _, meter := range meters {
+		tariff, ok := tariffs[meter.TariffID]
+		if !ok {
+			if tariff, err = s.db.RetrieveTariff(meter.TariffID); err != nil {
+				return nil, fmt.Errorf("meter %s: %v", meter.Name, err)
+			}
+			tariffs[meter.TariffID] = tariff
+		}
+		if report.Currency == "" {
+			report.Currency = tariff.Currency
+		} else if tariff.Currency != report.Currency {
+			return nil, fmt.Errorf("meters are priced in both %s and %s", report.Currency, tariff.Currency)
+		}
+
+		readings, err := s.db.RetrieveMeterReadings(meter.ID.Hex(), from, to)
+		if err != nil {
+			return nil, err
+		}
+		quantity, cost, covered := meterUsage(readings, tariff, from, to)
+		if !covered {
+			report.Unmetered = append(report.Unmetered, meter.Name)
+		}
+		if meter.FacilityID != "" {
+			quantity, cost = quantity*share, cost*share
+		}
+
+		usage := &report.Energy
+		if meter.Utility == domain.UtilityWater {
+			usage = &report.Water
+		}
+		usage.Quantity += quantity
+		usage.Cost += cost
+	}
+
+	report.Energy.Quantity, report.Energy.Cost = round2(report.Energy.Quantity), round2(report.Energy.Cost)
+	report.Water.Quantity, report.Water.Cost = round2(report.Water.Quantity), round2(report.Water.Cost)
+	report.Cost = round2(report.Energy.Cost + report.Water.Cost)
+	return report, nil
+}
+
+// CycleReport relates the consumption of the growing cycle that ended with a
+// harvest to the yield of that harvest
+func (s *UtilityService) CycleReport(harvestID string) (*domain.CycleReport, error) {
+	harvest, err := s.db.RetrieveHarvest(harvestID)
+	if err != nil {
+		return nil, err
+	}
+	return s.cycleReport(*harvest)
+}
+
+// CycleReports reports every harvested cycle of a farm, oldest first
+func (s *UtilityService) CycleReports(farmID string) ([]domain.CycleReport, error) {
+	harvests, err := s.db.RetrieveHarvests(farmID)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]domain.CycleReport, 0, len(harvests))
+	for _, harvest := range harvests {
+		report, err := s.cycleReport(harvest)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+func (s *UtilityService) cycleReport(harvest domain.HarvestRecord) (*domain.CycleReport, error) {
+	consumption, err := s.Consumption(harvest.FarmID, harvest.CycleStart, harvest.HarvestedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CycleReport{Harvest: harvest, Consumption: *consumption}
+	if harvest.YieldKg > 0 {
+		report.KWhPerKg = round2(consumption.Energy.Quantity / harvest.YieldKg)
+		report.LitersPerKg = round2(consumption.Water.Quantity / harvest.YieldKg)
+		report.CostPerKg = round2(consumption.Cost / harvest.YieldKg)
+		report.EnergyCostPerKg = round2(consumption.Energy.Cost / harvest.YieldKg)
+		report.WaterCostPerKg = round2(consumption.Water.Cost / harvest.YieldKg)
+	}
+	return report, nil
+}
+
+// meterUsage apportions the consumption between successive readings evenly
+// over time and returns the part that falls in [from, to] with its cost,
+// priced hour by hour. covered is false when the readings do not span the
+// whole range.
+func meterUsage(readings []domain.MeterReading, tariff *domain.Tariff, from, to time.Time) (quantity, cost float64, covered bool) {
+	loc, err := time.LoadLocation(tariff.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	if len(readings) == 0 {
+		return 0, 0, false
+	}
+	covered = !readings[0].Timestamp.After(from) && !readings[len(readings)-1].Timestamp.Before(to)
+
+	for i := 1; i < len(readings); i++ {
+		prev, cur := readings[i-1], readings[i]
+		span := cur.Timestamp.Sub(prev.Timestamp)
+		if span <= 0 {
+			continue
+		}
+		used := cur.Value - prev.Value
+		// A lower register value means the meter was reset or replaced
+		if used < 0 {
+			used = cur.Value
+		}
+		rate := used / span.Hours() // per hour
+
+		start, end := maxTime(prev.Timestamp, from), minTime(cur.Timestamp, to)
+		for t := start; t.Before(end); {
+			local := t.In(loc)
+			hour := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, loc)
+			next := minTime(hour.Add(time.Hour), end)
+			amount := rate * next.Sub(t).Hours()
+			quantity += amount
+			cost += amount * tariff.PriceAt(local)
+			t = next
+		}
+	}
+	return quantity, cost, covered
+}
+
+// validateUtility checks that a meter or tariff measures a known utility
+func validateUtility(utility string) error {
+	if utility != domain.UtilityEnergy && utility != domain.UtilityWater {
+		return fmt.Errorf("utility must be %s or %s", domain.UtilityEnergy, domain.UtilityWater)
+	}
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// round2 rounds to two decimals, as prices are quoted
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
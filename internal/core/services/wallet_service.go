@@ -0,0 +1,203 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/internal/stellar"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stellar/go/keypair"
+)
+
+// walletChallengeTTL is how long a binding challenge can be answered
+const walletChallengeTTL = 10 * time.Minute
+
+// WalletService binds Stellar wallets to users. A user asks for a challenge
+// for an account and signs it with the account's key, proving they control
+// it before shares can be registered to it.
+type WalletService struct {
+	db ports.MongoDB
+}
+
+// NewWalletService creates a new instance of the wallet service
+func NewWalletService(db ports.MongoDB) *WalletService {
+	return &WalletService{db: db}
+}
+
+// SignUp registers a user for the session's account and binds the account
+// as the user's first wallet. Logging in with the account proved control
+// of it, so no challenge is needed.
+func (s *WalletService) SignUp(session *domain.AuthSession, name, email string) (*domain.Wallet, error) {
+	if session.UserID != "" {
+		return nil, errors.New("account is already bound to a user")
+	}
+	if _, err := s.db.RetrieveWallet(session.AccountID); err == nil {
+		return nil, errors.New("account is already bound to a user")
+	}
+
+	userID, err := s.db.RegisterOrdinaryUser(&domain.OrdinaryUser{Name: name, Email: email, CreatedBy: session.AccountID})
+	if err != nil {
+		return nil, err
+	}
+	wallet := &domain.Wallet{UserID: userID, AccountID: session.AccountID, BoundAt: time.Now()}
+	if err := s.db.SaveWallet(wallet); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// Challenge issues the message a user signs to bind an account. Muxed
+// addresses bind their underlying account.
+func (s *WalletService) Challenge(session *domain.AuthSession, userID, address string) (*domain.WalletChallenge, error) {
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBinder(session, userID, accountID); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(walletChallengeTTL).UTC().Truncate(time.Second)
+	challenge := &domain.WalletChallenge{
+		UserID:    userID,
+		AccountID: accountID,
+		Message: fmt.Sprintf("0xFarms wallet binding\nUser: %s\nAccount: %s\nNonce: %s\nExpires: %s",
+			userID, accountID, hex.EncodeToString(nonce), expiresAt.Format(time.RFC3339)),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.SaveWalletChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Bind checks the base64 ed25519 signature of a challenge by the account's
+// key and binds the account to the user. A challenge is used up by the
+// attempt, whether or not the signature holds.
+func (s *WalletService) Bind(session *domain.AuthSession, userID, address, signature string) (*domain.Wallet, error) {
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBinder(session, userID, accountID); err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, errors.New("signature must be base64")
+	}
+
+	challenge, err := s.db.TakeWalletChallenge(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return nil, errors.New("challenge has expired")
+	}
+	if err := keypair.MustParseAddress(accountID).Verify([]byte(challenge.Message), sig); err != nil {
+		return nil, errors.New("signature does not match the account")
+	}
+
+	wallet := &domain.Wallet{UserID: userID, AccountID: accountID, BoundAt: time.Now()}
+	if err := s.db.SaveWallet(wallet); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// checkBinder rejects sessions that may not bind the account to the user.
+// A session acts for the user its account is bound to. A session of an
+// account bound to no user may only bind that account, as the first wallet
+// of a user the account signed up.
+func (s *WalletService) checkBinder(session *domain.AuthSession, userID, accountID string) error {
+	user, err := s.db.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	actingFor := session.UserID
+	if actingFor == "" {
+		// Bound since the session began, such as by signing up
+		if wallet, err := s.db.RetrieveWallet(session.AccountID); err == nil {
+			actingFor = wallet.UserID
+		}
+	}
+	if actingFor != "" {
+		if actingFor != userID {
+			return errors.New("session does not act for this user")
+		}
+		return nil
+	}
+
+	if session.AccountID != accountID || user.CreatedBy != session.AccountID {
+		return errors.New("session does not act for this user")
+	}
+	wallets, err := s.db.RetrieveWallets(userID)
+	if err != nil {
+		return err
+	}
+	if len(wallets) > 0 {
+		return errors.New("log in with a wallet of the user to bind more")
+	}
+	return nil
+}
+
+// Wallets returns the wallets bound to a user
+func (s *WalletService) Wallets(userID string) ([]domain.Wallet, error) {
+	wallets, err := s.db.RetrieveWallets(userID)
+	if err != nil {
+		return nil, err
+	}
+	if wallets == nil {
+		wallets = []domain.Wallet{}
+	}
+	return wallets, nil
+}
+
+// Unbind removes a wallet from a user. Shares already registered to it stay
+// with it; only new registrations need a bound wallet.
+func (s *WalletService) Unbind(userID, address string) error {
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return err
+	}
+	return s.db.DeleteWallet(userID, accountID)
+}
+
+// checkActsFor rejects sessions that do not control the address: the
+// session's own account, or any wallet bound to the session's user
+func checkActsFor(db ports.MongoDB, session *domain.AuthSession, address string) error {
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return err
+	}
+	if accountID == session.AccountID {
+		return nil
+	}
+	if wallet, err := db.RetrieveWallet(accountID); err == nil && session.UserID != "" && wallet.UserID == session.UserID {
+		return nil
+	}
+	return fmt.Errorf("session does not control %s", address)
+}
+
+// checkOwnerAddress rejects addresses shares cannot be registered to: those
+// that are not valid Stellar addresses and those whose account no user has
+// bound
+func checkOwnerAddress(db ports.MongoDB, address string) error {
+	accountID, err := stellar.AccountID(address)
+	if err != nil {
+		return err
+	}
+	if _, err := db.RetrieveWallet(accountID); err != nil {
+		return fmt.Errorf("address %s is not a bound wallet", address)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+)
+
+// bind binds the account of key to the user through the challenge flow
+func bind(wallets *WalletService, session *domain.AuthSession, userID string, key *keypair.Full) error {
+	challenge, err := wallets.Challenge(session, userID, key.Address())
+	if err != nil {
+		return err
+	}
+	signature, err := key.Sign([]byte(challenge.Message))
+	if err != nil {
+		return err
+	}
+	_, err = wallets.Bind(session, userID, key.Address(), base64.StdEncoding.EncodeToString(signature))
+	return err
+}
+
+func TestBindWallet(t *testing.T) {
+	db := newMemoryDB()
+	wallets := NewWalletService(db)
+	first, second, stranger := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+
+	// Signing up binds the account as the new user's first wallet
+	session := &domain.AuthSession{AccountID: first.Address()}
+	wallet, err := wallets.SignUp(session, "investor", "investor@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := wallet.UserID
+	if _, err := wallets.SignUp(session, "again", ""); err == nil {
+		t.Fatal("account signed up twice")
+	}
+	if err := bind(wallets, &domain.AuthSession{AccountID: stranger.Address()}, userID, stranger); err == nil {
+		t.Fatal("unbound account added a wallet to another user")
+	}
+
+	// The user adds more wallets, even from the session it signed up in
+	if err := bind(wallets, session, userID, second); err != nil {
+		t.Fatal(err)
+	}
+	otherID := db.putUser("other")
+	if err := bind(wallets, &domain.AuthSession{AccountID: first.Address(), UserID: userID}, otherID, stranger); err == nil {
+		t.Fatal("session bound a wallet to another user")
+	}
+
+	bound, err := wallets.Wallets(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bound) != 2 {
+		t.Fatalf("user has %d wallets, want 2", len(bound))
+	}
+}
+
+func TestBindWalletStranger(t *testing.T) {
+	db := newMemoryDB()
+	wallets := NewWalletService(db)
+	// A user without wallets, signed up some other way
+	userID := db.putUser("investor")
+	stranger := keypair.MustRandom()
+
+	if err := bind(wallets, &domain.AuthSession{AccountID: stranger.Address()}, userID, stranger); err == nil {
+		t.Fatal("stranger claimed a user without wallets")
+	}
+	if bound, _ := wallets.Wallets(userID); len(bound) != 0 {
+		t.Fatalf("user has wallets %+v, want none", bound)
+	}
+}
+
+func TestBindWalletSignature(t *testing.T) {
+	db := newMemoryDB()
+	wallets := NewWalletService(db)
+	key, other := keypair.MustRandom(), keypair.MustRandom()
+	session := &domain.AuthSession{AccountID: key.Address()}
+	// Signed up by the account, whose binding has not been made yet
+	userID, err := db.RegisterOrdinaryUser(&domain.OrdinaryUser{Name: "investor", CreatedBy: key.Address()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	challenge, err := wallets.Challenge(session, userID, key.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := other.Sign([]byte(challenge.Message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wallets.Bind(session, userID, key.Address(), base64.StdEncoding.EncodeToString(signature)); err == nil {
+		t.Fatal("wallet bound with another key's signature")
+	}
+
+	// The failed attempt used the challenge up
+	signature, err = key.Sign([]byte(challenge.Message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wallets.Bind(session, userID, key.Address(), base64.StdEncoding.EncodeToString(signature)); err == nil {
+		t.Fatal("challenge answered twice")
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"0xFarms-backend/pkg/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultReportingInterval applies to farms and devices without their own
+	defaultReportingInterval = 5 * time.Minute
+	// missedIntervals is how many expected readings may be missed before the
+	// data of a farm or device is considered stale
+	missedIntervals = 3
+)
+
+// reportingSource is a farm, or one device on a farm, expected to report
+type reportingSource struct {
+	farmID   string
+	deviceID string
+	interval time.Duration
+	lastSeen time.Time
+	gap      *domain.DataGap // open while the source is stale
+}
+
+// WatchdogService detects farms and devices that stopped sending readings,
+// records the gaps in their data and raises stale data alerts
+type WatchdogService struct {
+	db     ports.MongoDB
+	alerts *AlertService
+
+	mu      sync.Mutex
+	sources map[string]*reportingSource
+}
+
+// NewWatchdogService creates a new instance of the reporting watchdog
+func NewWatchdogService(db ports.MongoDB, alerts *AlertService) *WatchdogService {
+	return &WatchdogService{
+		db:      db,
+		alerts:  alerts,
+		sources: make(map[string]*reportingSource),
+	}
+}
+
+// Load starts tracking every farm that has reported before and every
+// registered device, and restores the gaps that were still open
+func (w *WatchdogService) Load() error {
+	farms, err := w.db.ListFarms()
+	if err != nil {
+		return err
+	}
+	devices, err := w.db.RetrieveDevices("")
+	if err != nil {
+		return err
+	}
+	gaps, err := w.db.RetrieveOpenDataGaps()
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, farm := range farms {
+		if farm.LastReadingAt.IsZero() {
+			continue
+		}
+		src := w.source(farm.ID, "", intervalOf(farm.ReportingIntervalSeconds))
+		src.lastSeen = farm.LastReadingAt
+	}
+
+	// Device last-seen times are not persisted, give them a full grace
+	// period from start-up
+	now := time.Now()
+	for _, device := range devices {
+		src := w.source(device.FarmID, device.ID.Hex(), intervalOf(device.ReportingIntervalSeconds))
+		src.lastSeen = now
+	}
+
+	for i := range gaps {
+		gap := gaps[i]
+		src := w.source(gap.FarmID, gap.DeviceID, time.Duration(gap.ExpectedIntervalSeconds)*time.Second)
+		src.gap = &gap
+		if src.lastSeen.Before(gap.Start) {
+			src.lastSeen = gap.Start
+		}
+	}
+	return nil
+}
+
+// ReadingAccepted marks the farm, and the device that sent the reading, as
+// reporting. Only devices registered to the farm are tracked, so readings
+// naming unknown devices cannot make the watchdog expect them.
+func (w *WatchdogService) ReadingAccepted(farmID string, farm *domain.VerticalFarm, reading domain.IoTReading) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seen(w.source(farmID, "", intervalOf(farm.ReportingIntervalSeconds)), reading.Timestamp)
+
+	if reading.DeviceID != "" {
+		key := farmID + "/" + reading.DeviceID
+		src, ok := w.sources[key]
+		if !ok {
+			device, err := w.db.RetrieveDevice(reading.DeviceID)
+			if err != nil || device.FarmID != farmID {
+				return
+			}
+			src = w.source(farmID, reading.DeviceID, intervalOf(device.ReportingIntervalSeconds))
+		}
+		w.seen(src, reading.Timestamp)
+	}
+}
+
+// seen records a reading from a source, closing its gap if it was stale.
+// Callers must hold w.mu.
+func (w *WatchdogService) seen(src *reportingSource, at time.Time) {
+	if !at.After(src.lastSeen) {
+		return
+	}
+	src.lastSeen = at
+
+	if src.gap == nil {
+		return
+	}
+	gap := src.gap
+	src.gap = nil
+
+	gap.End = &at
+	if err := w.db.UpdateDataGap(gap); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to close data gap %s: %v", gap.ID.Hex(), err))
+	}
+	if gap.AlertID != "" {
+		if _, err := w.alerts.Resolve(gap.AlertID); err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to resolve stale data alert %s: %v", gap.AlertID, err))
+		}
+	}
+	if src.deviceID == "" {
+		if err := w.db.SetFarmFreshness(src.farmID, false, nil); err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to mark farm %s fresh: %v", src.farmID, err))
+		}
+	}
+}
+
+// Run checks for stale sources every interval until the context is cancelled
+func (w *WatchdogService) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.Check(now)
+		}
+	}
+}
+
+// Check opens a gap and raises an alert for every source that has missed
+// too many readings
+func (w *WatchdogService) Check(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, src := range w.sources {
+		if src.gap != nil || now.Sub(src.lastSeen) <= src.interval*missedIntervals {
+			continue
+		}
+		if err := w.markStale(src, now); err != nil {
+			logger.LogWarning(fmt.Sprintf("Failed to mark farm %s device %q stale: %v", src.farmID, src.deviceID, err))
+		}
+	}
+}
+
+// markStale records a new gap for a source. Callers must hold w.mu.
+func (w *WatchdogService) markStale(src *reportingSource, now time.Time) error {
+	subject := "Farm " + src.farmID
+	severity := domain.SeverityCritical
+	if src.deviceID != "" {
+		subject = "Device " + src.deviceID
+		severity = domain.SeverityWarning
+	}
+
+	alert := &domain.Alert{
+		FarmID:      src.farmID,
+		DeviceID:    src.deviceID,
+		Kind:        domain.AlertKindStaleData,
+		Severity:    severity,
+		Message:     fmt.Sprintf("%s has not reported since %s", subject, src.lastSeen.Format(time.RFC3339)),
+		TriggeredAt: now,
+	}
+	if err := w.alerts.RaiseAlert(alert); err != nil {
+		return err
+	}
+
+	gap := &domain.DataGap{
+		FarmID:                  src.farmID,
+		DeviceID:                src.deviceID,
+		Start:                   src.lastSeen,
+		ExpectedIntervalSeconds: int(src.interval / time.Second),
+		AlertID:                 alert.ID.Hex(),
+	}
+	if err := w.db.SaveDataGap(gap); err != nil {
+		return err
+	}
+	src.gap = gap
+
+	if src.deviceID == "" {
+		return w.db.SetFarmFreshness(src.farmID, true, &now)
+	}
+	return nil
+}
+
+// source returns the tracked source for a farm or device, creating it if
+// needed. Callers must hold w.mu.
+func (w *WatchdogService) source(farmID, deviceID string, interval time.Duration) *reportingSource {
+	key := farmID
+	if deviceID != "" {
+		key += "/" + deviceID
+	}
+
+	src, ok := w.sources[key]
+	if !ok {
+		src = &reportingSource{farmID: farmID, deviceID: deviceID}
+		w.sources[key] = src
+	}
+	src.interval = interval
+	return src
+}
+
+// GetFarmStatus reports the health and data freshness of a farm and its devices
+func (w *WatchdogService) GetFarmStatus(farmID string) (*domain.FarmStatus, error) {
+	farm, err := w.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	status := &domain.FarmStatus{
+		FarmID:        farmID,
+		Status:        farm.Status,
+		CurrentHealth: farm.CurrentHealth,
+		Freshness: domain.DataFreshness{
+			ExpectedIntervalSeconds: int(intervalOf(farm.ReportingIntervalSeconds) / time.Second),
+			Stale:                   farm.DataStale,
+			StaleSince:              farm.StaleSince,
+		},
+		Devices: make([]domain.DataFreshness, 0),
+	}
+	if !farm.LastReadingAt.IsZero() {
+		status.Freshness.LastReadingAt = &farm.LastReadingAt
+		status.Freshness.AgeSeconds = int64(now.Sub(farm.LastReadingAt) / time.Second)
+	}
+
+	for _, src := range w.sources {
+		if src.farmID != farmID || src.deviceID == "" {
+			continue
+		}
+		lastSeen := src.lastSeen
+		freshness := domain.DataFreshness{
+			DeviceID:                src.deviceID,
+			LastReadingAt:           &lastSeen,
+			AgeSeconds:              int64(now.Sub(lastSeen) / time.Second),
+			ExpectedIntervalSeconds: int(src.interval / time.Second),
+			Stale:                   src.gap != nil,
+		}
+		if src.gap != nil {
+			start := src.gap.Start
+			freshness.StaleSince = &start
+		}
+		status.Devices = append(status.Devices, freshness)
+	}
+	return status, nil
+}
+
+// intervalOf converts a configured reporting interval, falling back to the default
+func intervalOf(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultReportingInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestWatchdogTracksRegisteredDevices(t *testing.T) {
+	db := newMemoryDB()
+	own := &domain.Device{FarmID: "farm-1", Name: "sensor", ReportingIntervalSeconds: 60}
+	other := &domain.Device{FarmID: "farm-2", Name: "sensor"}
+	for _, device := range []*domain.Device{own, other} {
+		if err := db.SaveDevice(device); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watchdog := NewWatchdogService(db, nil)
+	farm := &domain.VerticalFarm{ID: "farm-1"}
+
+	for _, deviceID := range []string{own.ID.Hex(), other.ID.Hex(), "unregistered"} {
+		watchdog.ReadingAccepted("farm-1", farm, domain.IoTReading{DeviceID: deviceID, Timestamp: time.Now()})
+	}
+
+	if len(watchdog.sources) != 2 {
+		t.Errorf("tracking %d sources, want the farm and its own device", len(watchdog.sources))
+	}
+	if src, ok := watchdog.sources["farm-1/"+own.ID.Hex()]; !ok || src.interval != time.Minute {
+		t.Errorf("own device source = %+v", src)
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/ports"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// whatIfMaxDays bounds projections that never reach harvest, such as
+// scenarios holding the temperature below the crop's base temperature
+const whatIfMaxDays = 365
+
+// WhatIfService projects how a farm's crop develops under hypothetical
+// conditions. Projections are computed on the fly and never stored.
+type WhatIfService struct {
+	db      ports.MongoDB
+	farms   *FarmManagementSystemService
+	metrics *MetricRegistry
+}
+
+// NewWhatIfService creates a new instance of the what-if service
+func NewWhatIfService(db ports.MongoDB, farms *FarmManagementSystemService, metrics *MetricRegistry) *WhatIfService {
+	return &WhatIfService{db: db, farms: farms, metrics: metrics}
+}
+
+// Simulate projects the farm's health, harvest date and yield day by day
+// under the scenario and under its latest measured conditions, and compares
+// the two. Development follows the crop's degree-day model.
+func (s *WhatIfService) Simulate(farmID string, scenario domain.WhatIfScenario) (*domain.WhatIfResult, error) {
+	if scenario.HorizonDays < 0 || scenario.HorizonDays > whatIfMaxDays {
+		return nil, fmt.Errorf("horizon must be between 0 and %d days", whatIfMaxDays)
+	}
+	setpoints := domain.IoTReading{}
+	for metric, value := range scenario.Setpoints {
+		setpoints.SetValue(metric, value)
+	}
+	if err := s.metrics.ValidateReading(setpoints); err != nil {
+		return nil, err
+	}
+	readings := append([]domain.IoTReading(nil), scenario.Readings...)
+	for _, reading := range readings {
+		if reading.Timestamp.IsZero() {
+			return nil, errors.New("scenario readings need a timestamp")
+		}
+		if err := s.metrics.ValidateReading(reading); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].Timestamp.Before(readings[j].Timestamp) })
+
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := s.farms.getCropSpecification(farm.CropType)
+	if !ok {
+		return nil, errors.New("crop specification not found")
+	}
+
+	now := time.Now()
+	history := cropHistory(farm, spec, now)
+
+	// Metrics without measurements are assumed to be at their optimum
+	baseline := make(map[string]float64)
+	for _, target := range spec.MetricTargets() {
+		baseline[target.Metric] = target.Optimal
+	}
+	if latest := latestReading(farm.IoTData); latest != nil {
+		for metric, value := range latest.MetricValues() {
+			baseline[metric] = value
+		}
+	}
+
+	conditions := make(map[string]float64, len(baseline))
+	for metric, value := range baseline {
+		conditions[metric] = value
+	}
+	for metric, value := range setpoints.MetricValues() {
+		conditions[metric] = value
+	}
+	next := 0
+	scenarioAt := func(t time.Time) map[string]float64 {
+		for ; next < len(readings) && !readings[next].Timestamp.After(t); next++ {
+			for metric, value := range readings[next].MetricValues() {
+				conditions[metric] = value
+			}
+		}
+		return conditions
+	}
+
+	result := &domain.WhatIfResult{
+		FarmID:             farmID,
+		DegreeDaysToDate:   round1(history.degreeDays),
+		RequiredDegreeDays: round1(spec.RequiredDegreeDays()),
+		Baseline:           s.project(farm, spec, history, now, scenario.HorizonDays, func(time.Time) map[string]float64 { return baseline }),
+		Scenario:           s.project(farm, spec, history, now, scenario.HorizonDays, scenarioAt),
+	}
+	if b, sc := result.Baseline.HarvestDate, result.Scenario.HarvestDate; b != nil && sc != nil {
+		shift := round1(sc.Sub(*b).Hours() / 24)
+		result.HarvestShiftDays = &shift
+	}
+	result.YieldChange = round1(result.Scenario.ExpectedYield - result.Baseline.ExpectedYield)
+	return result, nil
+}
+
+// cropState is what has happened to a crop between planting and now
+type cropState struct {
+	days       float64 // elapsed since planting
+	degreeDays float64
+	health     float64 // average measured health
+}
+
+// cropHistory accumulates the degree days of the crop from its readings.
+// Days without temperature readings count as days at the optimum, so a farm
+// without data follows its growth period.
+func cropHistory(farm *domain.VerticalFarm, spec domain.CropSpecification, now time.Time) cropState {
+	state := cropState{
+		days:   math.Max(0, now.Sub(farm.PlantingDate).Hours()/24),
+		health: float64(farm.CurrentHealth),
+	}
+	optimal := spec.OptimalTemp
+	if t, ok := spec.Target(domain.MetricTemperature); ok {
+		optimal = t.Optimal
+	}
+
+	type daily struct {
+		sum   float64
+		count int
+	}
+	temps := make(map[int]*daily)
+	var healthSum float64
+	var healthCount int
+	for _, reading := range farm.IoTData {
+		if reading.Timestamp.Before(farm.PlantingDate) || reading.Timestamp.After(now) {
+			continue
+		}
+		healthSum += float64(reading.CropHealth)
+		healthCount++
+		if temp, ok := reading.Value(domain.MetricTemperature); ok {
+			day := int(reading.Timestamp.Sub(farm.PlantingDate).Hours() / 24)
+			if temps[day] == nil {
+				temps[day] = &daily{}
+			}
+			temps[day].sum += temp
+			temps[day].count++
+		}
+	}
+	if healthCount > 0 {
+		state.health = healthSum / float64(healthCount)
+	}
+
+	for day := 0; float64(day) < state.days; day++ {
+		temp := optimal
+		if d := temps[day]; d != nil {
+			temp = d.sum / float64(d.count)
+		}
+		state.degreeDays += spec.DegreeDays(temp) * math.Min(1, state.days-float64(day))
+	}
+	return state
+}
+
+// project steps the crop forward one day at a time from now under the
+// conditions returned for each day, until harvest or the horizon
+func (s *WhatIfService) project(farm *domain.VerticalFarm, spec domain.CropSpecification, history cropState, now time.Time, horizonDays int, conditionsAt func(time.Time) map[string]float64) domain.Projection {
+	projection := domain.Projection{Health: make([]domain.HealthPoint, 0)}
+	required := spec.RequiredDegreeDays()
+	// Without a usable degree-day model the calendar estimate stands
+	if required <= 0 {
+		harvest := farm.EstimatedHarvestTime
+		projection.HarvestDate = &harvest
+	} else if history.degreeDays >= required {
+		projection.HarvestDate = &now
+	}
+
+	maxDays := horizonDays
+	if maxDays == 0 {
+		maxDays = whatIfMaxDays
+	}
+	degreeDays := history.degreeDays
+	healthDays := history.days * history.health
+	for day := 0; day < maxDays; day++ {
+		t := now.Add(time.Duration(day) * 24 * time.Hour)
+		if projection.HarvestDate != nil && !projection.HarvestDate.After(t) {
+			break
+		}
+
+		conditions := conditionsAt(t)
+		reading := domain.IoTReading{Timestamp: t}
+		for metric, value := range conditions {
+			reading.SetValue(metric, value)
+		}
+		health, ok := s.farms.calculateHealthScore(reading, spec)
+		if !ok {
+			health = farm.CurrentHealth
+		}
+		healthDays += float64(health)
+
+		end := t.Add(24 * time.Hour)
+		if required > 0 && projection.HarvestDate == nil {
+			temp, ok := conditions[domain.MetricTemperature]
+			if !ok {
+				temp = spec.OptimalTemp
+			}
+			gained := spec.DegreeDays(temp)
+			if degreeDays+gained >= required {
+				harvest := t.Add(time.Duration((required - degreeDays) / gained * float64(24*time.Hour)))
+				projection.HarvestDate = &harvest
+				gained = required - degreeDays
+				end = harvest
+			}
+			degreeDays += gained
+		}
+
+		projection.Health = append(projection.Health, domain.HealthPoint{
+			Time:       end,
+			Health:     health,
+			DegreeDays: round1(degreeDays),
+		})
+	}
+
+	if total := history.days + float64(len(projection.Health)); total > 0 {
+		projection.AverageHealth = round1(healthDays / total)
+	}
+	yield := s.farms.calculateExpectedYield(farm, int(math.Round(projection.AverageHealth)), spec)
+	projection.ExpectedYield = round1(yield)
+	return projection
+}
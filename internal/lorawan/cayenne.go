@@ -0,0 +1,92 @@
+package lorawan
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"fmt"
+)
+
+// CayenneLPPDecoder is the name the Cayenne LPP decoder is registered under
+const CayenneLPPDecoder = "cayenne_lpp"
+
+// cayenneType describes how one Cayenne LPP data type is encoded
+type cayenneType struct {
+	name   string
+	size   int     // bytes of data following the channel and type
+	signed bool    // values are two's complement
+	scale  float64 // divisor turning the raw integer into the value
+	metric string  // metric the value maps to by default
+}
+
+// cayenneTypes are the data types of Cayenne Low Power Payload. Types with
+// several values per entry (accelerometer, gyrometer, GPS) are skipped as
+// they describe no farm metric.
+var cayenneTypes = map[byte]cayenneType{
+	0:   {name: "digital_input", size: 1, scale: 1},
+	1:   {name: "digital_output", size: 1, scale: 1},
+	2:   {name: "analog_input", size: 2, signed: true, scale: 100},
+	3:   {name: "analog_output", size: 2, signed: true, scale: 100},
+	100: {name: "generic", size: 4, scale: 1},
+	101: {name: "illuminance", size: 2, scale: 1},
+	102: {name: "presence", size: 1, scale: 1},
+	103: {name: "temperature", size: 2, signed: true, scale: 10, metric: domain.MetricTemperature},
+	104: {name: "humidity", size: 1, scale: 2, metric: domain.MetricHumidity},
+	113: {name: "accelerometer", size: 6},
+	115: {name: "barometer", size: 2, scale: 10},
+	116: {name: "voltage", size: 2, scale: 100},
+	117: {name: "current", size: 2, scale: 1000},
+	118: {name: "frequency", size: 4, scale: 1},
+	120: {name: "percentage", size: 1, scale: 1},
+	121: {name: "altitude", size: 2, signed: true, scale: 1},
+	125: {name: "concentration", size: 2, scale: 1, metric: domain.MetricCO2},
+	128: {name: "power", size: 2, scale: 1},
+	130: {name: "distance", size: 4, scale: 1000},
+	131: {name: "energy", size: 4, scale: 1000},
+	132: {name: "direction", size: 2, scale: 1},
+	133: {name: "unix_time", size: 4, scale: 1},
+	134: {name: "gyrometer", size: 6},
+	136: {name: "gps", size: 9},
+	142: {name: "switch", size: 1, scale: 1},
+}
+
+// CayenneLPP decodes Cayenne Low Power Payload, a sequence of channel, type
+// and big-endian value entries. Temperature, humidity and concentration map
+// to their metrics; other types need a channel mapping on the device.
+type CayenneLPP struct{}
+
+// Decode implements Decoder
+func (CayenneLPP) Decode(fPort int, payload []byte) ([]Measurement, error) {
+	var measurements []Measurement
+	for i := 0; i < len(payload); {
+		if i+2 > len(payload) {
+			return nil, fmt.Errorf("truncated entry at byte %d", i)
+		}
+		channel, code := payload[i], payload[i+1]
+		t, ok := cayenneTypes[code]
+		if !ok {
+			return nil, fmt.Errorf("unknown data type %d on channel %d", code, channel)
+		}
+		data := payload[i+2:]
+		if len(data) < t.size {
+			return nil, fmt.Errorf("truncated %s on channel %d", t.name, channel)
+		}
+		i += 2 + t.size
+
+		if t.scale == 0 {
+			continue
+		}
+		var raw int64
+		for _, b := range data[:t.size] {
+			raw = raw<<8 | int64(b)
+		}
+		if t.signed && raw&(1<<(8*t.size-1)) != 0 {
+			raw -= 1 << (8 * t.size)
+		}
+		measurements = append(measurements, Measurement{
+			Channel: int(channel),
+			Type:    t.name,
+			Metric:  t.metric,
+			Value:   float64(raw) / t.scale,
+		})
+	}
+	return measurements, nil
+}
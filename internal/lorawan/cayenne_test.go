@@ -0,0 +1,100 @@
+package lorawan
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestCayenneLPPDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string // base64, as sent in webhook bodies
+		want    []Measurement
+	}{
+		{
+			name:    "two temperatures",
+			payload: "A2cBEAVnAP8=", // 03 67 0110 05 67 00FF
+			want: []Measurement{
+				{Channel: 3, Type: "temperature", Metric: domain.MetricTemperature, Value: 27.2},
+				{Channel: 5, Type: "temperature", Metric: domain.MetricTemperature, Value: 25.5},
+			},
+		},
+		{
+			name:    "negative temperature, accelerometer skipped",
+			payload: "AWf/1wZxBNL7LgAA", // 01 67 FFD7 06 71 04D2 FB2E 0000
+			want: []Measurement{
+				{Channel: 1, Type: "temperature", Metric: domain.MetricTemperature, Value: -4.1},
+			},
+		},
+		{
+			name:    "temperature, humidity and CO2",
+			payload: "AWf/1wJoYQp9AZA=", // 01 67 FFD7 02 68 61 0A 7D 0190
+			want: []Measurement{
+				{Channel: 1, Type: "temperature", Metric: domain.MetricTemperature, Value: -4.1},
+				{Channel: 2, Type: "humidity", Metric: domain.MetricHumidity, Value: 48.5},
+				{Channel: 10, Type: "concentration", Metric: domain.MetricCO2, Value: 400},
+			},
+		},
+		{
+			name:    "zero values",
+			payload: "AWcAAAJoAA==", // 01 67 0000 02 68 00
+			want: []Measurement{
+				{Channel: 1, Type: "temperature", Metric: domain.MetricTemperature, Value: 0},
+				{Channel: 2, Type: "humidity", Metric: domain.MetricHumidity, Value: 0},
+			},
+		},
+		{
+			name:    "empty",
+			payload: "",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := base64.StdEncoding.DecodeString(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := CayenneLPP{}.Decode(1, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d measurements %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("measurement %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCayenneLPPDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		err     string
+	}{
+		{name: "truncated header", payload: "AWcAAAI=", err: "truncated entry at byte 4"},    // 01 67 0000 02
+		{name: "truncated value", payload: "AWcAAAJoAANn/w==", err: "truncated temperature"}, // ... 03 67 FF
+		{name: "truncated accelerometer", payload: "BnEE0vsu", err: "truncated accelerometer"},
+		{name: "unknown type", payload: "Af8A", err: "unknown data type 255 on channel 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := base64.StdEncoding.DecodeString(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := (CayenneLPP{}).Decode(1, payload); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
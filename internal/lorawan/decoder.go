@@ -0,0 +1,83 @@
+package lorawan
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Measurement is one value decoded from an uplink payload
+type Measurement struct {
+	Channel int     `json:"channel"`
+	Type    string  `json:"type"`             // data type as named by the payload format
+	Metric  string  `json:"metric,omitempty"` // metric the type maps to, empty when it has none
+	Value   float64 `json:"value"`
+}
+
+// Decoder turns the binary payload of a device model into measurements.
+// Decoders are pure functions of the payload so they can be checked against
+// captured uplinks.
+type Decoder interface {
+	Decode(fPort int, payload []byte) ([]Measurement, error)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		CayenneLPPDecoder: CayenneLPP{},
+	}
+)
+
+// Register makes a decoder available to devices under name, replacing any
+// decoder already registered with it
+func Register(name string, decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[name] = decoder
+}
+
+// Lookup returns the decoder registered under name
+func Lookup(name string) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	decoder, ok := decoders[name]
+	return decoder, ok
+}
+
+// Decoders returns the names of the registered decoders
+func Decoders() []string {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	names := make([]string, 0, len(decoders))
+	for name := range decoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToReading builds a reading taken at t from decoded measurements. channels
+// maps channel numbers to metric keys and takes precedence over the metric a
+// measurement's type maps to; measurements mapped to no metric are dropped.
+func ToReading(measurements []Measurement, channels map[string]string, t time.Time) (domain.IoTReading, error) {
+	reading := domain.IoTReading{Timestamp: t}
+	found := false
+	for _, m := range measurements {
+		metric := m.Metric
+		if mapped, ok := channels[strconv.Itoa(m.Channel)]; ok {
+			metric = mapped
+		}
+		if metric == "" {
+			continue
+		}
+		reading.SetValue(metric, m.Value)
+		found = true
+	}
+	if !found {
+		return reading, errors.New("payload carries no mapped metrics")
+	}
+	return reading, nil
+}
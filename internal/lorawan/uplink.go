@@ -0,0 +1,108 @@
+package lorawan
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Uplink is a data message from an end device, as forwarded by a network
+// server webhook
+type Uplink struct {
+	DevEUI     string
+	FPort      int
+	Payload    []byte
+	ReceivedAt time.Time
+}
+
+// theThingsStackUplink is the envelope of The Things Stack v3 uplink webhooks
+type theThingsStackUplink struct {
+	EndDeviceIDs struct {
+		DevEUI string `json:"dev_eui"`
+	} `json:"end_device_ids"`
+	ReceivedAt    time.Time `json:"received_at"`
+	UplinkMessage *struct {
+		FPort      int       `json:"f_port"`
+		FRMPayload []byte    `json:"frm_payload"` // base64 encoded
+		ReceivedAt time.Time `json:"received_at"`
+	} `json:"uplink_message"`
+}
+
+// chirpStackUplink is the envelope of ChirpStack v4 "up" events
+type chirpStackUplink struct {
+	DeviceInfo *struct {
+		DevEUI string `json:"devEui"`
+	} `json:"deviceInfo"`
+	Time  time.Time `json:"time"`
+	FPort int       `json:"fPort"`
+	Data  []byte    `json:"data"` // base64 encoded
+}
+
+// ErrNotUplink is returned for webhook messages that carry no uplink, such
+// as join or acknowledgement events
+var ErrNotUplink = errors.New("message is not an uplink")
+
+// ParseUplink reads a webhook body from The Things Stack or ChirpStack. The
+// receive time defaults to now when the network server does not send one.
+func ParseUplink(body []byte) (Uplink, error) {
+	var uplink Uplink
+
+	var tts theThingsStackUplink
+	if err := json.Unmarshal(body, &tts); err != nil {
+		return uplink, err
+	}
+	if tts.EndDeviceIDs.DevEUI != "" {
+		if tts.UplinkMessage == nil {
+			return uplink, ErrNotUplink
+		}
+		uplink = Uplink{
+			DevEUI:     tts.EndDeviceIDs.DevEUI,
+			FPort:      tts.UplinkMessage.FPort,
+			Payload:    tts.UplinkMessage.FRMPayload,
+			ReceivedAt: tts.UplinkMessage.ReceivedAt,
+		}
+		if uplink.ReceivedAt.IsZero() {
+			uplink.ReceivedAt = tts.ReceivedAt
+		}
+	} else {
+		var cs chirpStackUplink
+		if err := json.Unmarshal(body, &cs); err != nil {
+			return uplink, err
+		}
+		if cs.DeviceInfo == nil || cs.DeviceInfo.DevEUI == "" {
+			return uplink, errors.New("uplink has no device EUI")
+		}
+		uplink = Uplink{
+			DevEUI:     cs.DeviceInfo.DevEUI,
+			FPort:      cs.FPort,
+			Payload:    cs.Data,
+			ReceivedAt: cs.Time,
+		}
+	}
+
+	eui, err := NormalizeEUI(uplink.DevEUI)
+	if err != nil {
+		return uplink, err
+	}
+	uplink.DevEUI = eui
+	if len(uplink.Payload) == 0 {
+		return uplink, ErrNotUplink
+	}
+	if uplink.ReceivedAt.IsZero() {
+		uplink.ReceivedAt = time.Now()
+	}
+	return uplink, nil
+}
+
+// NormalizeEUI returns a 64-bit EUI as 16 upper-case hex digits, accepting
+// the usual separators
+func NormalizeEUI(eui string) (string, error) {
+	eui = strings.NewReplacer("-", "", ":", "", " ", "").Replace(strings.TrimSpace(eui))
+	raw, err := hex.DecodeString(eui)
+	if err != nil || len(raw) != 8 {
+		return "", errors.New("EUI must be 8 bytes of hex")
+	}
+	return strings.ToUpper(eui), nil
+}
@@ -0,0 +1,173 @@
+package lorawan
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+// theThingsStackBody is an uplink webhook of The Things Stack v3
+const theThingsStackBody = `{
+  "end_device_ids": {
+    "device_id": "rack-3-climate",
+    "application_ids": {"application_id": "0xfarms"},
+    "dev_eui": "70B3D57ED005A1B2",
+    "join_eui": "0000000000000000",
+    "dev_addr": "260B1C3D"
+  },
+  "correlation_ids": ["as:up:01HQ3K5V8Y4M2N6P7R9S0T1U2V"],
+  "received_at": "2024-03-02T10:15:31.870473519Z",
+  "uplink_message": {
+    "session_key_id": "AY3k4x1n0qZ7d5o0Xf8rGQ==",
+    "f_port": 1,
+    "f_cnt": 4211,
+    "frm_payload": "AWf/1wJoYQp9AZA=",
+    "rx_metadata": [{
+      "gateway_ids": {"gateway_id": "farm-gw-1", "eui": "B827EBFFFE8C1A2B"},
+      "rssi": -71,
+      "channel_rssi": -71,
+      "snr": 9.5
+    }],
+    "settings": {
+      "data_rate": {"lora": {"bandwidth": 125000, "spreading_factor": 7}},
+      "frequency": "868100000"
+    },
+    "received_at": "2024-03-02T10:15:31.652188104Z"
+  }
+}`
+
+// theThingsStackJoin is a join accept webhook of The Things Stack v3
+const theThingsStackJoin = `{
+  "end_device_ids": {
+    "device_id": "rack-3-climate",
+    "dev_eui": "70B3D57ED005A1B2"
+  },
+  "received_at": "2024-03-02T10:14:02.118375205Z",
+  "join_accept": {
+    "session_key_id": "AY3k4x1n0qZ7d5o0Xf8rGQ==",
+    "received_at": "2024-03-02T10:14:01.991712430Z"
+  }
+}`
+
+// chirpStackBody is an "up" event of ChirpStack v4
+const chirpStackBody = `{
+  "deduplicationId": "3ac7e3c4-4401-4b8d-9386-a5c902f9202d",
+  "time": "2024-03-02T10:20:05.217349+00:00",
+  "deviceInfo": {
+    "tenantName": "0xFarms",
+    "applicationName": "vertical-farms",
+    "deviceProfileName": "cayenne-climate",
+    "deviceName": "rack-1-climate",
+    "devEui": "a84041000181c3d4"
+  },
+  "devAddr": "00f2a1b3",
+  "adr": true,
+  "dr": 5,
+  "fCnt": 88,
+  "fPort": 2,
+  "confirmed": false,
+  "data": "AWcAAAJoAA==",
+  "rxInfo": [{"gatewayId": "b827ebfffe8c1a2b", "rssi": -84, "snr": 7.2}]
+}`
+
+func TestParseUplinkTheThingsStack(t *testing.T) {
+	uplink, err := ParseUplink([]byte(theThingsStackBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uplink.DevEUI != "70B3D57ED005A1B2" || uplink.FPort != 1 {
+		t.Fatalf("uplink = %+v", uplink)
+	}
+	want := time.Date(2024, 3, 2, 10, 15, 31, 652188104, time.UTC)
+	if !uplink.ReceivedAt.Equal(want) {
+		t.Fatalf("received at %s, want the uplink message time %s", uplink.ReceivedAt, want)
+	}
+
+	measurements, err := CayenneLPP{}.Decode(uplink.FPort, uplink.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading, err := ToReading(measurements, map[string]string{"10": domain.MetricCO2}, uplink.ReceivedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for metric, value := range map[string]float64{
+		domain.MetricTemperature: -4.1,
+		domain.MetricHumidity:    48.5,
+		domain.MetricCO2:         400,
+	} {
+		if got, ok := reading.Value(metric); !ok || got != value {
+			t.Errorf("%s = %g (%v), want %g", metric, got, ok, value)
+		}
+	}
+}
+
+func TestParseUplinkChirpStack(t *testing.T) {
+	uplink, err := ParseUplink([]byte(chirpStackBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uplink.DevEUI != "A84041000181C3D4" || uplink.FPort != 2 {
+		t.Fatalf("uplink = %+v", uplink)
+	}
+
+	measurements, err := CayenneLPP{}.Decode(uplink.FPort, uplink.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading, err := ToReading(measurements, nil, uplink.ReceivedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A device reporting 0 °C and 0 % carries both readings
+	for _, metric := range []string{domain.MetricTemperature, domain.MetricHumidity} {
+		if got, ok := reading.Value(metric); !ok || got != 0 {
+			t.Errorf("%s = %g (%v), want a zero reading", metric, got, ok)
+		}
+	}
+	if _, ok := reading.Value(domain.MetricPH); ok {
+		t.Error("reading carries a pH the payload did not send")
+	}
+}
+
+func TestParseUplinkRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{name: "join accept", body: theThingsStackJoin, err: ErrNotUplink},
+		{name: "empty payload", body: `{"deviceInfo": {"devEui": "a84041000181c3d4"}, "fPort": 2}`, err: ErrNotUplink},
+		{name: "missing EUI", body: `{"fPort": 2, "data": "AWcAAA=="}`},
+		{name: "bad EUI", body: `{"deviceInfo": {"devEui": "a84041"}, "fPort": 2, "data": "AWcAAA=="}`},
+		{name: "not JSON", body: `AWcAAA==`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseUplink([]byte(tt.body))
+			if err == nil {
+				t.Fatal("accepted")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestToReadingUnmapped(t *testing.T) {
+	// Illuminance maps to no metric unless the device maps its channel
+	measurements := []Measurement{{Channel: 4, Type: "illuminance", Value: 320}}
+	if _, err := ToReading(measurements, nil, time.Now()); err == nil {
+		t.Fatal("reading built from unmapped measurements")
+	}
+	reading, err := ToReading(measurements, map[string]string{"4": domain.MetricPPFD}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reading.Value(domain.MetricPPFD); !ok || got != 320 {
+		t.Fatalf("ppfd = %g (%v)", got, ok)
+	}
+}
@@ -1,6 +1,9 @@
 package ports
 
-import "0xFarms-backend/internal/core/domain"
+import (
+	"0xFarms-backend/internal/core/domain"
+	"time"
+)
 
 type MongoDB interface {
 	SaveBlog(blog *domain.Blog) (bool, error)
@@ -14,7 +17,137 @@ type MongoDB interface {
 	// New farm-related operations
 	CreateFarm(farm *domain.VerticalFarm) (string, error)
 	GetFarm(id string) (*domain.VerticalFarm, error)
-	UpdateFarm(id string, farm *domain.VerticalFarm) (bool, error)
+	UpdateFarmOwners(farmID string, change func(farm *domain.VerticalFarm) error) error
+	ListFarms() ([]domain.VerticalFarm, error)
+	SetFarmFreshness(id string, stale bool, staleSince *time.Time) error
+	SetFarmStatus(id, status string) error
+	SetFarmReportingInterval(id string, seconds int) error
+	SetFarmReservoir(id string, reservoir *domain.Reservoir) error
+	SetFarmOffering(id string, offering *domain.Offering) error
 	AddIoTReading(farmID string, reading *domain.IoTReading) error
 	GetCropSpecification(cropType string) (domain.CropSpecification, error)
+	SaveCropSpecification(spec *domain.CropSpecification) error
+	// Sensor metric registry operations
+	SaveMetricDefinition(metric *domain.MetricDefinition) error
+	RetrieveMetricDefinitions() ([]domain.MetricDefinition, error)
+	// Reading rollup operations
+	UpsertRollup(farmID, resolution string, bucketStart time.Time, values map[string]float64, health int) error
+	RetrieveRollups(farmID, resolution string, from, to time.Time) ([]domain.ReadingRollup, error)
+	RemoveRollups(farmID string) error
+	// Alerting operations
+	SaveAlertRule(rule *domain.AlertRule) error
+	RetrieveAlertRules() ([]domain.AlertRule, error)
+	RemoveAlertRule(id string) (bool, error)
+	SaveAlert(alert *domain.Alert) error
+	UpdateAlert(alert *domain.Alert) error
+	RetrieveAlert(id string) (*domain.Alert, error)
+	RetrieveAlerts(filters *domain.AlertFilters) ([]domain.Alert, error)
+	// Device and reporting watchdog operations
+	SaveDevice(device *domain.Device) error
+	RetrieveDevice(id string) (*domain.Device, error)
+	RetrieveDeviceByEUI(devEUI string) (*domain.Device, error)
+	RetrieveDevices(farmID string) ([]domain.Device, error)
+	SaveDataGap(gap *domain.DataGap) error
+	UpdateDataGap(gap *domain.DataGap) error
+	RetrieveDataGaps(farmID string, from, to time.Time) ([]domain.DataGap, error)
+	RetrieveOpenDataGaps() ([]domain.DataGap, error)
+	// Actuator command operations
+	SaveCommand(command *domain.ActuatorCommand) error
+	UpdateCommand(command *domain.ActuatorCommand) error
+	RetrieveCommand(id string) (*domain.ActuatorCommand, error)
+	RetrieveCommands(filters *domain.CommandFilters) ([]domain.ActuatorCommand, error)
+	// Control policy operations
+	SaveControlPolicy(policy *domain.ControlPolicy) error
+	UpdateControlPolicy(policy *domain.ControlPolicy) error
+	RetrieveControlPolicy(id string) (*domain.ControlPolicy, error)
+	RetrieveControlPolicies(farmID string) ([]domain.ControlPolicy, error)
+	RemoveControlPolicy(id string) (bool, error)
+	SaveControlDecision(decision *domain.ControlDecision) error
+	RetrieveControlDecisions(farmID string, from, to time.Time) ([]domain.ControlDecision, error)
+	// Dosing recommendation operations
+	SaveRecommendation(recommendation *domain.DosingRecommendation) error
+	UpdateRecommendation(recommendation *domain.DosingRecommendation) error
+	RetrieveRecommendation(id string) (*domain.DosingRecommendation, error)
+	RetrieveRecommendations(farmID string, from, to time.Time) ([]domain.DosingRecommendation, error)
+	RetrieveUnmeasuredRecommendations() ([]domain.DosingRecommendation, error)
+	// Archived reading operations
+	InsertReadings(farmID string, readings []domain.IoTReading) ([]domain.IoTReading, error)
+	RetrieveReadings(farmID string, from, to time.Time) ([]domain.IoTReading, error)
+	StreamReadings(farmID string, from, to time.Time, fn func(domain.IoTReading) error) error
+	MergeRollups(rollups []domain.ReadingRollup) error
+	// Import job operations
+	SaveImportJob(job *domain.ImportJob) error
+	UpdateImportJob(job *domain.ImportJob) error
+	RetrieveImportJob(id string) (*domain.ImportJob, error)
+	RetrieveImportJobs(farmID string, statuses []string) ([]domain.ImportJob, error)
+	SaveImportRejections(rejections []domain.ImportRejection) error
+	RetrieveImportRejections(jobID string) ([]domain.ImportRejection, error)
+	// Utility metering and harvest operations
+	SaveFacility(facility *domain.Facility) error
+	RetrieveFacility(id string) (*domain.Facility, error)
+	RetrieveFacilityOfFarm(farmID string) (*domain.Facility, error)
+	SaveMeter(meter *domain.Meter) error
+	RetrieveMeter(id string) (*domain.Meter, error)
+	RetrieveMeters(farmID, facilityID string) ([]domain.Meter, error)
+	SaveMeterReading(reading *domain.MeterReading) error
+	RetrieveMeterReadings(meterID string, from, to time.Time) ([]domain.MeterReading, error)
+	SaveTariff(tariff *domain.Tariff) error
+	RetrieveTariff(id string) (*domain.Tariff, error)
+	RetrieveTariffs() ([]domain.Tariff, error)
+	SaveHarvest(harvest *domain.HarvestRecord) error
+	RetrieveHarvest(id string) (*domain.HarvestRecord, error)
+	RetrieveHarvests(farmID string) ([]domain.HarvestRecord, error)
+	// Investment operations
+	SaveInvestment(investment *domain.Investment) error
+	UpdateInvestment(investment *domain.Investment) error
+	RetrieveInvestment(id string) (*domain.Investment, error)
+	RetrieveInvestments(farmID, investorID string) ([]domain.Investment, error)
+	MigrateShareUnits() (int, error)
+	// Share market operations
+	SaveShareOrder(order *domain.ShareOrder) error
+	UpdateShareOrder(order *domain.ShareOrder) error
+	RetrieveShareOrder(id string) (*domain.ShareOrder, error)
+	RetrieveShareOrders(farmID, status string) ([]domain.ShareOrder, error)
+	RetrieveShareTrades(farmID string) ([]domain.ShareTrade, error)
+	RetrieveShareTrade(id string) (*domain.ShareTrade, error)
+	UpdateShareTrade(id string, change func(farm *domain.VerticalFarm, trade *domain.ShareTrade) error) (*domain.ShareTrade, error)
+	ExecuteShareTrade(farmID string, orderIDs []string, apply func(farm *domain.VerticalFarm, orders []*domain.ShareOrder) (*domain.ShareTrade, error)) (*domain.ShareTrade, error)
+	// Distribution operations
+	SaveDistribution(distribution *domain.Distribution) error
+	UpdateDistribution(distribution *domain.Distribution) error
+	RetrieveDistribution(id string) (*domain.Distribution, error)
+	RetrieveDistributions(farmID string) ([]domain.Distribution, error)
+	// Ownership history operations
+	RetrieveOwnershipEvents(farmID string, until time.Time) ([]domain.OwnershipEvent, error)
+	BackfillOwnershipEvents() (int, error)
+	// Tokenization operations
+	SaveFarmToken(token *domain.FarmToken) error
+	UpdateFarmToken(token *domain.FarmToken) error
+	RetrieveFarmToken(farmID string) (*domain.FarmToken, error)
+	RetrieveFarmTokens() ([]domain.FarmToken, error)
+	// Wallet operations
+	GetUser(id string) (*domain.OrdinaryUser, error)
+	RegisterOrdinaryUser(user *domain.OrdinaryUser) (string, error)
+	SaveWalletChallenge(challenge *domain.WalletChallenge) error
+	TakeWalletChallenge(userID, accountID string) (*domain.WalletChallenge, error)
+	SaveWallet(wallet *domain.Wallet) error
+	RetrieveWallet(accountID string) (*domain.Wallet, error)
+	RetrieveWallets(userID string) ([]domain.Wallet, error)
+	DeleteWallet(userID, accountID string) error
+	// Web authentication operations
+	SaveAuthSession(session *domain.AuthSession) error
+	RetrieveAuthSession(tokenHash string) (*domain.AuthSession, error)
+	DeleteAuthSession(tokenHash string) error
+	// Payout operations
+	SavePayout(payout *domain.Payout) error
+	UpdatePayout(payout *domain.Payout) error
+	RetrievePayout(id string) (*domain.Payout, error)
+	RetrieveDistributionPayout(distributionID string) (*domain.Payout, error)
+	// Incoming payment operations
+	SaveIncomingPayment(payment *domain.IncomingPayment) (bool, error)
+	UpdateIncomingPayment(payment *domain.IncomingPayment) error
+	RetrieveIncomingPayments(statuses []string) ([]domain.IncomingPayment, error)
+	RetrieveInvestmentPayments(investmentID string) ([]domain.IncomingPayment, error)
+	RetrievePaymentCursor(accountID string) (string, error)
+	SavePaymentCursor(accountID, cursor string) error
 }
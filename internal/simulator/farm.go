@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// allFaults are injected when the config does not restrict the kinds
+var allFaults = []string{FaultStuck, FaultSpike, FaultOffset}
+
+// Farm synthesises the sensor readings of one farm
+type Farm struct {
+	ID       string
+	CropType string
+
+	cfg     Config
+	rng     *rand.Rand
+	start   time.Time
+	sensors []*sensor
+	outage  int // readings left in the current dropout
+}
+
+// NewFarm creates a simulated farm whose sensors vary around the crop's
+// targets. Metrics the crop does not target are simulated around typical
+// values so dashboards have something to show.
+func NewFarm(id, cropType string, targets []domain.MetricTarget, start time.Time, cfg Config, seed int64) *Farm {
+	bases := make(map[string]float64, len(defaultBases)+len(targets))
+	for metric, base := range defaultBases {
+		bases[metric] = base
+	}
+	for _, t := range targets {
+		bases[t.Metric] = t.Optimal
+	}
+	// Nutrient level and EC are the same measurement, only one is reported
+	if !targetsMetric(targets, domain.MetricEC) {
+		delete(bases, domain.MetricEC)
+	}
+
+	metrics := make([]string, 0, len(bases))
+	for metric := range bases {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	farm := &Farm{
+		ID:       id,
+		CropType: cropType,
+		cfg:      cfg,
+		rng:      rand.New(rand.NewSource(seed)),
+		start:    start,
+	}
+	for _, metric := range metrics {
+		farm.sensors = append(farm.sensors, &sensor{metric: metric, model: newModel(metric, bases[metric])})
+	}
+	return farm
+}
+
+// Next returns the reading taken at t. It returns false while the farm is in
+// a simulated dropout and reports nothing.
+func (f *Farm) Next(t time.Time) (domain.IoTReading, bool) {
+	if f.outage > 0 {
+		f.outage--
+		return domain.IoTReading{}, false
+	}
+	if f.cfg.DropoutRate > 0 && f.rng.Float64() < f.cfg.DropoutRate {
+		// Connectivity drops come in bursts
+		f.outage = f.rng.Intn(12)
+		return domain.IoTReading{}, false
+	}
+
+	if f.cfg.FaultRate > 0 && f.rng.Float64() < f.cfg.FaultRate {
+		kinds := f.cfg.FaultKinds
+		if len(kinds) == 0 {
+			kinds = allFaults
+		}
+		target := f.sensors[f.rng.Intn(len(f.sensors))]
+		if target.fault == nil {
+			target.inject(kinds[f.rng.Intn(len(kinds))], f.rng)
+		}
+	}
+
+	reading := domain.IoTReading{Timestamp: t}
+	for _, s := range f.sensors {
+		reading.SetValue(s.metric, s.value(t, f.start, f.cfg, f.rng))
+	}
+	return reading, true
+}
+
+// targetsMetric reports whether the targets include the metric
+func targetsMetric(targets []domain.MetricTarget, metric string) bool {
+	for _, t := range targets {
+		if t.Metric == metric {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package simulator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RunOptions controls the pace of a simulation
+type RunOptions struct {
+	Interval time.Duration // simulated time between two readings of a farm
+	Count    int           // readings per farm, 0 to run until cancelled
+	Start    time.Time     // timestamp of the first readings
+	Rate     float64       // readings per second across all farms, 0 for unthrottled
+	Workers  int           // concurrent senders
+	// Realtime stamps readings with the wall clock and waits Interval between
+	// them instead of replaying simulated time as fast as Rate allows
+	Realtime bool
+}
+
+// Stats summarises a simulation run
+type Stats struct {
+	Sent       int           `json:"sent"`
+	Dropped    int           `json:"dropped"`  // withheld by simulated dropouts
+	Rejected   int           `json:"rejected"` // refused by the sink
+	Elapsed    time.Duration `json:"elapsed"`
+	MaxLatency time.Duration `json:"maxLatency"`
+	AvgLatency time.Duration `json:"avgLatency"`
+	LastError  string        `json:"lastError,omitempty"`
+}
+
+// Throughput returns the readings delivered per second
+func (s Stats) Throughput() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Sent) / s.Elapsed.Seconds()
+}
+
+// collector accumulates stats from concurrent senders
+type collector struct {
+	mu           sync.Mutex
+	stats        Stats
+	totalLatency time.Duration
+}
+
+// record counts one reading, adding its send latency unless it was dropped
+func (c *collector) record(err error, dropped bool, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case dropped:
+		c.stats.Dropped++
+		return
+	case err != nil:
+		c.stats.Rejected++
+		c.stats.LastError = err.Error()
+	default:
+		c.stats.Sent++
+	}
+	c.totalLatency += latency
+	if latency > c.stats.MaxLatency {
+		c.stats.MaxLatency = latency
+	}
+}
+
+// Run sends the farms' readings to the sink until every farm has produced
+// Count readings or ctx is cancelled. Each farm is handled by a single worker
+// so its readings arrive in order.
+func Run(ctx context.Context, sink Sink, farms []*Farm, opts RunOptions) Stats {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.Workers > len(farms) {
+		opts.Workers = len(farms)
+	}
+
+	// A shared ticker paces all workers to the overall rate
+	var pace <-chan time.Time
+	if opts.Rate > 0 && !opts.Realtime {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		pace = ticker.C
+	}
+
+	c := &collector{}
+	began := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		var assigned []*Farm
+		for i := w; i < len(farms); i += opts.Workers {
+			assigned = append(assigned, farms[i])
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if opts.Realtime {
+				runRealtime(ctx, sink, assigned, opts, c)
+			} else {
+				runReplay(ctx, sink, assigned, opts, pace, c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Elapsed = time.Since(began)
+	if delivered := stats.Sent + stats.Rejected; delivered > 0 {
+		stats.AvgLatency = c.totalLatency / time.Duration(delivered)
+	}
+	return stats
+}
+
+// runReplay walks simulated time from Start, one reading per farm per step
+func runReplay(ctx context.Context, sink Sink, farms []*Farm, opts RunOptions, pace <-chan time.Time, c *collector) {
+	for i := 0; opts.Count == 0 || i < opts.Count; i++ {
+		t := opts.Start.Add(time.Duration(i) * opts.Interval)
+		for _, farm := range farms {
+			if pace != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-pace:
+				}
+			} else if ctx.Err() != nil {
+				return
+			}
+			send(sink, farm, t, c)
+		}
+	}
+}
+
+// runRealtime sends one reading per farm every Interval of wall-clock time
+func runRealtime(ctx context.Context, sink Sink, farms []*Farm, opts RunOptions, c *collector) {
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for i := 0; opts.Count == 0 || i < opts.Count; i++ {
+		now := time.Now()
+		for _, farm := range farms {
+			send(sink, farm, now, c)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send produces the farm's reading at t and delivers it unless it is dropped
+func send(sink Sink, farm *Farm, t time.Time, c *collector) {
+	reading, ok := farm.Next(t)
+	if !ok {
+		c.record(nil, true, 0)
+		return
+	}
+
+	began := time.Now()
+	err := sink.SendReading(farm.ID, reading)
+	c.record(err, false, time.Since(began))
+}
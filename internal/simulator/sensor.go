@@ -0,0 +1,159 @@
+package simulator
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Fault kinds that can be injected into a simulated sensor
+const (
+	FaultStuck  = "stuck"  // the sensor repeats its last value
+	FaultSpike  = "spike"  // a single outlier
+	FaultOffset = "offset" // a calibration bias for a while
+)
+
+// Config tunes how readings are synthesised
+type Config struct {
+	Noise       float64  // scales the sensor noise, 1 for realistic noise
+	Drift       float64  // scales the slow drift of pH and nutrients, 1 for realistic drift
+	DropoutRate float64  // chance per reading that the farm goes quiet for a while
+	FaultRate   float64  // chance per reading that a sensor develops a fault
+	FaultKinds  []string // faults to pick from, all kinds when empty
+}
+
+// model describes how one metric behaves over a day
+type model struct {
+	base      float64 // level the metric varies around
+	amplitude float64 // diurnal swing around base, negative when it peaks at night
+	peakHour  float64 // hour of day the swing peaks
+	noise     float64 // standard deviation of the sensor noise
+	drift     float64 // change per day between weekly tank top-ups
+	min, max  float64 // physical limits
+	lights    bool    // only non-zero while the grow lights are on
+}
+
+// lightsOn and lightsOff bound the daily photoperiod of the grow lights
+const (
+	lightsOn  = 6
+	lightsOff = 24
+)
+
+// topUpEvery is how often the simulated technician refreshes the tank,
+// resetting pH and nutrient drift
+const topUpEvery = 7 * 24 * time.Hour
+
+// defaultBases are used for metrics the crop specification does not target
+var defaultBases = map[string]float64{
+	domain.MetricTemperature:     22,
+	domain.MetricHumidity:        65,
+	domain.MetricPH:              6.0,
+	domain.MetricNutrientLevel:   1.2,
+	domain.MetricCO2:             800,
+	domain.MetricPPFD:            400,
+	domain.MetricEC:              1.2,
+	domain.MetricWaterTemp:       20,
+	domain.MetricDissolvedOxygen: 8,
+	domain.MetricVPD:             1.0,
+}
+
+// newModel returns the behaviour of a metric around the given base level
+func newModel(metric string, base float64) model {
+	switch metric {
+	case domain.MetricTemperature:
+		return model{base: base, amplitude: 3, peakHour: 15, noise: 0.2, min: -40, max: 80}
+	case domain.MetricHumidity:
+		return model{base: base, amplitude: -8, peakHour: 15, noise: 1, min: 0, max: 100}
+	case domain.MetricPH:
+		return model{base: base, noise: 0.03, drift: 0.05, min: 0, max: 14}
+	case domain.MetricNutrientLevel, domain.MetricEC:
+		return model{base: base, noise: 0.02, drift: -0.03, min: 0, max: 10}
+	case domain.MetricCO2:
+		return model{base: base, amplitude: -150, peakHour: 15, noise: 20, min: 0, max: 10000}
+	case domain.MetricPPFD:
+		return model{base: base, noise: 10, min: 0, max: 3000, lights: true}
+	case domain.MetricWaterTemp:
+		return model{base: base, amplitude: 1, peakHour: 17, noise: 0.1, min: 0, max: 50}
+	case domain.MetricDissolvedOxygen:
+		return model{base: base, amplitude: -0.5, peakHour: 17, noise: 0.2, min: 0, max: 30}
+	case domain.MetricVPD:
+		return model{base: base, amplitude: 0.3, peakHour: 15, noise: 0.05, min: 0, max: 10}
+	}
+	return model{base: base, noise: math.Abs(base) * 0.01, min: math.Inf(-1), max: math.Inf(1)}
+}
+
+// fault is a fault currently affecting one sensor
+type fault struct {
+	kind      string
+	remaining int // readings left
+	offset    float64
+}
+
+// sensor simulates one metric of a farm
+type sensor struct {
+	metric string
+	model  model
+	walk   float64 // slow random walk on top of the model
+	last   float64
+	fault  *fault
+}
+
+// value returns the sensor's reading at t
+func (s *sensor) value(t time.Time, start time.Time, cfg Config, rng *rand.Rand) float64 {
+	m := s.model
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+
+	v := m.base
+	if m.lights && (hour < lightsOn || hour >= lightsOff) {
+		v = 0
+	}
+	v += m.amplitude * math.Cos(2*math.Pi*(hour-m.peakHour)/24)
+
+	sinceTopUp := t.Sub(start) % topUpEvery
+	v += m.drift * cfg.Drift * sinceTopUp.Hours() / 24
+
+	s.walk += rng.NormFloat64() * m.noise * cfg.Noise * 0.1
+	s.walk *= 0.99 // pull the walk back towards the model
+	v += s.walk + rng.NormFloat64()*m.noise*cfg.Noise
+
+	if s.fault != nil {
+		switch s.fault.kind {
+		case FaultStuck:
+			v = s.last
+		case FaultSpike:
+			v += s.fault.offset
+		case FaultOffset:
+			v += s.fault.offset
+		}
+		s.fault.remaining--
+		if s.fault.remaining <= 0 {
+			s.fault = nil
+		}
+	}
+
+	if m.lights && v < 0 {
+		v = 0
+	}
+	v = math.Max(m.min, math.Min(m.max, v))
+	s.last = v
+	return math.Round(v*1000) / 1000
+}
+
+// inject starts a fault of the given kind on the sensor
+func (s *sensor) inject(kind string, rng *rand.Rand) {
+	scale := math.Max(math.Abs(s.model.base), 1)
+	sign := 1.0
+	if rng.Intn(2) == 0 {
+		sign = -1
+	}
+
+	switch kind {
+	case FaultStuck:
+		s.fault = &fault{kind: kind, remaining: 6 + rng.Intn(19)}
+	case FaultSpike:
+		s.fault = &fault{kind: kind, remaining: 1, offset: sign * scale * (0.3 + rng.Float64()*0.4)}
+	case FaultOffset:
+		s.fault = &fault{kind: kind, remaining: 12 + rng.Intn(37), offset: sign * scale * 0.1}
+	}
+}
@@ -0,0 +1,159 @@
+package simulator
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"0xFarms-backend/internal/ports"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sink is where simulated farms are created and their readings delivered
+type Sink interface {
+	CreateFarm(width, height float64, cropType string) (string, error)
+	FarmCrop(farmID string) (string, error)
+	// CropTargets returns the crop's metric targets, or nil when the sink
+	// cannot tell and typical values should be used
+	CropTargets(cropType string) ([]domain.MetricTarget, error)
+	SendReading(farmID string, reading domain.IoTReading) error
+}
+
+var (
+	_ Sink = (*ServiceSink)(nil)
+	_ Sink = (*HTTPSink)(nil)
+)
+
+// ServiceSink delivers readings straight through the service layer
+type ServiceSink struct {
+	db    ports.MongoDB
+	farms *services.FarmManagementSystemService
+}
+
+// NewServiceSink creates a sink that calls the farm service directly
+func NewServiceSink(db ports.MongoDB, farms *services.FarmManagementSystemService) *ServiceSink {
+	return &ServiceSink{db: db, farms: farms}
+}
+
+// CreateFarm creates a farm through the farm service
+func (s *ServiceSink) CreateFarm(width, height float64, cropType string) (string, error) {
+	farm, err := s.farms.CreateFarm(width, height, cropType)
+	if err != nil {
+		return "", err
+	}
+	return farm.ID, nil
+}
+
+// FarmCrop returns the crop grown on an existing farm
+func (s *ServiceSink) FarmCrop(farmID string) (string, error) {
+	farm, err := s.db.GetFarm(farmID)
+	if err != nil {
+		return "", err
+	}
+	return farm.CropType, nil
+}
+
+// CropTargets reads the crop specification from the database
+func (s *ServiceSink) CropTargets(cropType string) ([]domain.MetricTarget, error) {
+	spec, err := s.db.GetCropSpecification(cropType)
+	if err != nil {
+		return nil, err
+	}
+	return spec.MetricTargets(), nil
+}
+
+// SendReading adds the reading through the farm service
+func (s *ServiceSink) SendReading(farmID string, reading domain.IoTReading) error {
+	return s.farms.AddIoTReading(farmID, reading)
+}
+
+// HTTPSink delivers readings to a running server over its REST API
+type HTTPSink struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPSink creates a sink that posts to the server at baseURL
+func NewHTTPSink(baseURL string) *HTTPSink {
+	return &HTTPSink{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// apiResponse is the envelope every API response comes in
+type apiResponse struct {
+	StatusCode int             `json:"statusCode"`
+	Message    string          `json:"message"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// CreateFarm creates a farm with POST /farms
+func (s *HTTPSink) CreateFarm(width, height float64, cropType string) (string, error) {
+	var farm domain.VerticalFarm
+	body := map[string]interface{}{"width": width, "height": height, "cropType": cropType}
+	if err := s.do(http.MethodPost, "/farms", body, &farm); err != nil {
+		return "", err
+	}
+	return farm.ID, nil
+}
+
+// FarmCrop reads the crop of an existing farm with GET /farms/:id
+func (s *HTTPSink) FarmCrop(farmID string) (string, error) {
+	var farm domain.VerticalFarm
+	if err := s.do(http.MethodGet, "/farms/"+farmID, nil, &farm); err != nil {
+		return "", err
+	}
+	return farm.CropType, nil
+}
+
+// CropTargets returns nil as the API does not expose crop specifications
+func (s *HTTPSink) CropTargets(cropType string) ([]domain.MetricTarget, error) {
+	return nil, nil
+}
+
+// SendReading posts the reading to POST /farms/:id/readings
+func (s *HTTPSink) SendReading(farmID string, reading domain.IoTReading) error {
+	return s.do(http.MethodPost, "/farms/"+farmID+"/readings", reading, nil)
+}
+
+// do sends a request and decodes the data of a successful response into out
+func (s *HTTPSink) do(method, path string, body interface{}, out interface{}) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, s.baseURL+path, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if envelope.Message == "" {
+			envelope.Message = resp.Status
+		}
+		return errors.New(envelope.Message)
+	}
+	if out != nil && len(envelope.Data) > 0 {
+		return json.Unmarshal(envelope.Data, out)
+	}
+	return nil
+}
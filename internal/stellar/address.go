@@ -0,0 +1,32 @@
+package stellar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/strkey"
+)
+
+// AccountID validates a Stellar address and returns the account it belongs
+// to. Account IDs (G…) are returned as they are, muxed accounts (M…) as
+// their underlying account. Checksums are verified, so a mistyped address
+// is rejected rather than pointing at an account nobody controls.
+func AccountID(address string) (string, error) {
+	switch {
+	case strings.HasPrefix(address, "G"):
+		if !strkey.IsValidEd25519PublicKey(address) {
+			return "", fmt.Errorf("invalid Stellar account ID %q", address)
+		}
+		return address, nil
+	case strings.HasPrefix(address, "M"):
+		muxed, err := strkey.DecodeMuxedAccount(address)
+		if err != nil {
+			return "", fmt.Errorf("invalid Stellar muxed account %q", address)
+		}
+		return muxed.AccountID()
+	default:
+		// Not echoed back, in case a secret seed was pasted by mistake
+		return "", errors.New("address is not a Stellar account ID or muxed account")
+	}
+}
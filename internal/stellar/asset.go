@@ -0,0 +1,90 @@
+package stellar
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/pkg/money"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+// tokenDecimals is the decimal places of a Stellar amount
+const tokenDecimals = 7
+
+// assetCodeEncoding writes asset codes with letters and digits only
+var assetCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// AssetCode derives the code of a farm's share token from the farm's ID.
+// Codes are at most 12 characters, too few to hold the ID, so the code is 55
+// bits of a hash of it; should one collide with another farm's, the next
+// attempt gives a different code.
+func AssetCode(farmID string, attempt int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", farmID, attempt)))
+	return "F" + assetCodeEncoding.EncodeToString(sum[:])[:11]
+}
+
+// FarmAsset returns the share token of a farm
+func FarmAsset(code, issuer string) txnbuild.CreditAsset {
+	return txnbuild.CreditAsset{Code: code, Issuer: issuer}
+}
+
+// PaymentAsset returns the asset a currency is paid in: lumens for XLM
+// without an issuer, otherwise the issuer's asset with the currency as its
+// code
+func PaymentAsset(currency, issuer string) (txnbuild.Asset, error) {
+	if currency == "XLM" && issuer == "" {
+		return txnbuild.NativeAsset{}, nil
+	}
+	if issuer == "" {
+		return nil, errors.New("an issuer is required for assets other than XLM")
+	}
+	if _, err := AccountID(issuer); err != nil || !strings.HasPrefix(issuer, "G") {
+		return nil, errors.New("issuer must be a Stellar account ID")
+	}
+	asset := txnbuild.CreditAsset{Code: currency, Issuer: issuer}
+	if _, err := asset.ToXDR(); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// ParseAssetIssuers reads a comma separated list of CODE:ISSUER assets into
+// the issuer of each code
+func ParseAssetIssuers(list string) (map[string]string, error) {
+	issuers := make(map[string]string)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		code, issuer, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("asset %q must be CODE:ISSUER", entry)
+		}
+		if _, err := PaymentAsset(code, issuer); err != nil {
+			return nil, fmt.Errorf("asset %q: %v", entry, err)
+		}
+		issuers[code] = issuer
+	}
+	return issuers, nil
+}
+
+// SharesToAmount converts shares to a token amount. One token is one
+// percentage point of the farm, so balances read as ownership percentages.
+func SharesToAmount(shares domain.Shares) string {
+	return shares.Percent()
+}
+
+// AmountToShares converts a token amount to shares. Fractions below one
+// share unit, which the platform never issues, are dropped.
+func AmountToShares(amount string) (domain.Shares, error) {
+	v, err := money.ParseDecimal(amount, tokenDecimals)
+	if err != nil {
+		return 0, err
+	}
+	return domain.Shares(v / 1000), nil
+}
@@ -0,0 +1,194 @@
+package stellar
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/txnbuild"
+)
+
+// HorizonLedger reads and writes the ledger through a Horizon server
+type HorizonLedger struct {
+	client *horizonclient.Client
+}
+
+// NewHorizonLedger creates a ledger backed by the Horizon server at url
+func NewHorizonLedger(url string) *HorizonLedger {
+	return &HorizonLedger{client: &horizonclient.Client{
+		HorizonURL: url,
+		HTTP:       &http.Client{Timeout: 30 * time.Second},
+	}}
+}
+
+// Account loads an account from Horizon
+func (h *HorizonLedger) Account(accountID string) (*Account, error) {
+	detail, err := h.client.AccountDetail(horizonclient.AccountRequest{AccountID: accountID})
+	if err != nil {
+		if horizonclient.IsNotFoundError(err) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	account := &Account{
+		ID:              detail.AccountID,
+		Sequence:        detail.Sequence,
+		AuthRevocable:   detail.Flags.AuthRevocable,
+		ClawbackEnabled: detail.Flags.AuthClawbackEnabled,
+	}
+	for _, b := range detail.Balances {
+		if b.LiquidityPoolId != "" {
+			continue
+		}
+		account.Balances = append(account.Balances, Balance{
+			Code:            b.Code,
+			Issuer:          b.Issuer,
+			Amount:          b.Balance,
+			ClawbackEnabled: b.IsClawbackEnabled != nil && *b.IsClawbackEnabled,
+		})
+	}
+	return account, nil
+}
+
+// Holders pages through the accounts trusting an asset
+func (h *HorizonLedger) Holders(asset txnbuild.CreditAsset) ([]Holding, error) {
+	page, err := h.client.Accounts(horizonclient.AccountsRequest{Asset: asset.Code + ":" + asset.Issuer, Limit: 200})
+	if err != nil {
+		return nil, err
+	}
+
+	var holdings []Holding
+	for len(page.Embedded.Records) > 0 {
+		for _, record := range page.Embedded.Records {
+			for _, b := range record.Balances {
+				if b.Code == asset.Code && b.Issuer == asset.Issuer {
+					holdings = append(holdings, Holding{AccountID: record.AccountID, Amount: b.Balance})
+				}
+			}
+		}
+		if page, err = h.client.NextAccountsPage(page); err != nil {
+			return nil, err
+		}
+	}
+	return holdings, nil
+}
+
+// Submit sends a transaction to Horizon, reporting the result codes of
+// rejected transactions
+func (h *HorizonLedger) Submit(tx *txnbuild.Transaction) (string, error) {
+	result, err := h.client.SubmitTransaction(tx)
+	if err != nil {
+		if herr := horizonclient.GetError(err); herr != nil {
+			if codes, codesErr := herr.ResultCodes(); codesErr == nil {
+				return "", &SubmitError{TransactionCode: codes.TransactionCode, OperationCodes: codes.OperationCodes}
+			}
+		}
+		return "", err
+	}
+	return result.Hash, nil
+}
+
+// Transaction looks up a transaction on Horizon
+func (h *HorizonLedger) Transaction(hash string) (*TransactionResult, error) {
+	tx, err := h.client.TransactionDetail(hash)
+	if err != nil {
+		if horizonclient.IsNotFoundError(err) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	return &TransactionResult{Hash: tx.Hash, Successful: tx.Successful, Ledger: tx.Ledger, Memo: tx.Memo}, nil
+}
+
+// Payments pages through the payments received by an account, joined with
+// their transactions for the memo
+func (h *HorizonLedger) Payments(accountID, cursor string) ([]Payment, error) {
+	page, err := h.client.Payments(horizonclient.OperationRequest{
+		ForAccount: accountID,
+		Cursor:     cursor,
+		Order:      horizonclient.OrderAsc,
+		Limit:      200,
+		Join:       "transactions",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []Payment
+	for len(page.Embedded.Records) > 0 {
+		for _, record := range page.Embedded.Records {
+			payment, ok := horizonPayment(record)
+			if !ok || payment.To != accountID {
+				continue
+			}
+			payments = append(payments, payment)
+		}
+		if page, err = h.client.NextPaymentsPage(page); err != nil {
+			return nil, err
+		}
+	}
+	return payments, nil
+}
+
+// Payment looks up a payment operation on Horizon, with its transaction
+// for the memo
+func (h *HorizonLedger) Payment(id string) (*Payment, error) {
+	record, err := h.client.OperationDetail(id)
+	if err != nil {
+		if horizonclient.IsNotFoundError(err) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	payment, ok := horizonPayment(record)
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	tx, err := h.client.TransactionDetail(payment.TransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx.MemoType == "text" {
+		payment.Memo = tx.Memo
+	}
+	return &payment, nil
+}
+
+// horizonPayment converts a successful payment operation, reporting false
+// for other operations
+func horizonPayment(record operations.Operation) (Payment, bool) {
+	var p operations.Payment
+	switch op := record.(type) {
+	case operations.Payment:
+		p = op
+	case operations.PathPayment:
+		p = op.Payment
+	case operations.PathPaymentStrictSend:
+		p = op.Payment
+	default:
+		return Payment{}, false
+	}
+	if !p.TransactionSuccessful {
+		return Payment{}, false
+	}
+	payment := Payment{
+		ID:              p.ID,
+		Cursor:          p.PT,
+		TransactionHash: p.TransactionHash,
+		From:            p.From,
+		To:              p.To,
+		AssetCode:       p.Asset.Code,
+		AssetIssuer:     p.Asset.Issuer,
+		Amount:          p.Amount,
+		ReceivedAt:      p.LedgerCloseTime,
+	}
+	if p.Asset.Type == "native" {
+		payment.AssetCode = "XLM"
+	}
+	if p.Transaction != nil && p.Transaction.MemoType == "text" {
+		payment.Memo = p.Transaction.Memo
+	}
+	return payment, true
+}
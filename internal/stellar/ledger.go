@@ -0,0 +1,123 @@
+package stellar
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+// MaxOperations is the most operations a Stellar transaction can carry
+const MaxOperations = 100
+
+// ErrAccountNotFound is returned for accounts that do not exist on the ledger
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrTransactionNotFound is returned for transactions the ledger has no
+// record of, because they were never submitted or never applied
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrPaymentNotFound is returned for operation IDs that are not successful
+// payments on the ledger
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// SubmitError is a transaction the network rejected, with its result
+// codes. OperationCodes has one entry per operation when the transaction
+// failed in its operations, op_success for those that would have applied.
+type SubmitError struct {
+	TransactionCode string
+	OperationCodes  []string
+}
+
+func (e *SubmitError) Error() string {
+	if len(e.OperationCodes) == 0 {
+		return "transaction failed: " + e.TransactionCode
+	}
+	return "transaction failed: " + e.TransactionCode + " " + strings.Join(e.OperationCodes, ",")
+}
+
+// Ledger is the part of a Horizon server the platform uses. HorizonLedger
+// talks to a real server and MemoryLedger stands in for one offline.
+type Ledger interface {
+	// Account returns an account's sequence number, flags and balances
+	Account(accountID string) (*Account, error)
+	// Holders returns the accounts with a trustline to an asset
+	Holders(asset txnbuild.CreditAsset) ([]Holding, error)
+	// Submit sends a signed transaction and returns its hash. Transactions
+	// the network rejects return a *SubmitError.
+	Submit(tx *txnbuild.Transaction) (string, error)
+	// Transaction looks up the outcome of a transaction by hash
+	Transaction(hash string) (*TransactionResult, error)
+	// Payments returns the successful payments an account received after
+	// the cursor, oldest first. An empty cursor starts from the beginning.
+	Payments(accountID, cursor string) ([]Payment, error)
+	// Payment looks up a successful payment by its operation ID
+	Payment(id string) (*Payment, error)
+}
+
+// Account is the state of a ledger account
+type Account struct {
+	ID       string
+	Sequence int64
+	// Issuer flags, which trustlines inherit when they are created
+	AuthRevocable   bool
+	ClawbackEnabled bool
+	Balances        []Balance
+}
+
+// Balance is an account's holding of one asset. Code and Issuer are empty
+// for lumens.
+type Balance struct {
+	Code            string
+	Issuer          string
+	Amount          string
+	ClawbackEnabled bool
+}
+
+// TransactionResult is the outcome of a transaction included in a ledger.
+// Transactions that failed are included too, consuming their sequence
+// number without applying any operation.
+type TransactionResult struct {
+	Hash       string
+	Successful bool
+	Ledger     int32
+	Memo       string
+}
+
+// Payment is a payment received by an account
+type Payment struct {
+	ID              string // operation ID, unique across the network
+	Cursor          string // resumes the payment stream after this payment
+	TransactionHash string
+	From            string
+	To              string
+	// AssetCode is XLM for lumens, which have no issuer
+	AssetCode   string
+	AssetIssuer string
+	Amount      string
+	Memo        string // text memos only
+	ReceivedAt  time.Time
+}
+
+// Holding is the balance of an asset held by an account
+type Holding struct {
+	AccountID string
+	Amount    string
+}
+
+// Trustline returns the account's balance of an asset, and false when the
+// account does not trust it
+func (a *Account) Trustline(asset txnbuild.CreditAsset) (Balance, bool) {
+	for _, b := range a.Balances {
+		if b.Code == asset.Code && b.Issuer == asset.Issuer {
+			return b, true
+		}
+	}
+	return Balance{}, false
+}
+
+// TxnAccount returns the account as the source of a new transaction
+func (a *Account) TxnAccount() *txnbuild.SimpleAccount {
+	return &txnbuild.SimpleAccount{AccountID: a.ID, Sequence: a.Sequence}
+}
@@ -0,0 +1,461 @@
+package stellar
+
+import (
+	"0xFarms-backend/pkg/money"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// nativeKey is the balance key of lumens
+const nativeKey = "native"
+
+// MemoryLedger is an in-memory stand-in for Horizon, for running offline
+// and in tests. It checks sequence numbers, time bounds and signatures, and
+// applies the payment, trustline, clawback, account creation and flag
+// operations the platform uses. A transaction applies all of its operations
+// or none, and is recorded either way for lookup by hash.
+type MemoryLedger struct {
+	passphrase string
+	mu         sync.Mutex
+	accounts   map[string]*memoryAccount
+	results    map[string]*TransactionResult
+	payments   []Payment
+	ledger     int32 // sequence of the last closed ledger, one per transaction
+}
+
+type memoryAccount struct {
+	sequence        int64
+	authRevocable   bool
+	clawbackEnabled bool
+	lines           map[string]*memoryLine
+}
+
+type memoryLine struct {
+	code, issuer string
+	balance      int64 // in stroops
+	clawback     bool
+}
+
+// NewMemoryLedger creates an empty ledger for the network passphrase
+func NewMemoryLedger(passphrase string) *MemoryLedger {
+	return &MemoryLedger{
+		passphrase: passphrase,
+		accounts:   make(map[string]*memoryAccount),
+		results:    make(map[string]*TransactionResult),
+	}
+}
+
+// Fund creates an account holding lumens, or adds lumens to an existing one
+func (l *MemoryLedger) Fund(accountID, lumens string) error {
+	amount, err := money.ParseDecimal(lumens, tokenDecimals)
+	if err != nil {
+		return err
+	}
+	if _, err := keypair.ParseAddress(accountID); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	account := l.accounts[accountID]
+	if account == nil {
+		account = newMemoryAccount()
+		l.accounts[accountID] = account
+	}
+	account.lines[nativeKey].balance += amount
+	return nil
+}
+
+// Account returns a copy of an account's state
+func (l *MemoryLedger) Account(accountID string) (*Account, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stored := l.accounts[accountID]
+	if stored == nil {
+		return nil, ErrAccountNotFound
+	}
+	account := &Account{
+		ID:              accountID,
+		Sequence:        stored.sequence,
+		AuthRevocable:   stored.authRevocable,
+		ClawbackEnabled: stored.clawbackEnabled,
+	}
+	for _, line := range stored.sortedLines() {
+		account.Balances = append(account.Balances, Balance{
+			Code:            line.code,
+			Issuer:          line.issuer,
+			Amount:          money.FormatDecimal(line.balance, tokenDecimals),
+			ClawbackEnabled: line.clawback,
+		})
+	}
+	return account, nil
+}
+
+// Holders returns the accounts trusting an asset, ordered by account ID
+func (l *MemoryLedger) Holders(asset txnbuild.CreditAsset) ([]Holding, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var holdings []Holding
+	for id, account := range l.accounts {
+		if line := account.lines[assetKey(asset)]; line != nil {
+			holdings = append(holdings, Holding{AccountID: id, Amount: money.FormatDecimal(line.balance, tokenDecimals)})
+		}
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].AccountID < holdings[j].AccountID })
+	return holdings, nil
+}
+
+// Submit validates and applies a signed transaction
+func (l *MemoryLedger) Submit(tx *txnbuild.Transaction) (string, error) {
+	hash, err := tx.HashHex(l.passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sourceID := tx.SourceAccount().AccountID
+	source := l.accounts[sourceID]
+	if source == nil {
+		return "", &SubmitError{TransactionCode: "tx_no_source_account"}
+	}
+	if tx.SequenceNumber() != source.sequence+1 {
+		return "", &SubmitError{TransactionCode: "tx_bad_seq"}
+	}
+	if bounds := tx.Timebounds(); bounds.MaxTime != 0 && time.Now().Unix() > bounds.MaxTime {
+		return "", &SubmitError{TransactionCode: "tx_too_late"}
+	}
+	if err := l.checkSignatures(tx); err != nil {
+		return "", err
+	}
+
+	// Operations apply to a copy that replaces the ledger only if all succeed.
+	// Like the network, every operation is tried so each has a result code.
+	// A failed transaction is still included, using up its sequence number.
+	l.ledger++
+	result := &TransactionResult{Hash: hash, Ledger: l.ledger, Memo: memoText(tx.Memo())}
+	l.results[hash] = result
+	accounts := l.clone()
+	codes := make([]string, len(tx.Operations()))
+	failed := false
+	for i, op := range tx.Operations() {
+		codes[i] = "op_success"
+		if err := apply(accounts, opSource(op, sourceID), op); err != nil {
+			codes[i], failed = err.Error(), true
+		}
+	}
+	if failed {
+		l.accounts[sourceID].sequence++
+		return "", &SubmitError{TransactionCode: "tx_failed", OperationCodes: codes}
+	}
+	accounts[sourceID].sequence++
+	l.accounts = accounts
+	result.Successful = true
+	l.recordPayments(tx, hash, sourceID)
+	return hash, nil
+}
+
+// Payments returns the payments an account received after the cursor,
+// which is the position of a payment in the ledger's history
+func (l *MemoryLedger) Payments(accountID, cursor string) ([]Payment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		start = n
+	}
+
+	var payments []Payment
+	for i := start; i < len(l.payments); i++ {
+		if l.payments[i].To == accountID {
+			payments = append(payments, l.payments[i])
+		}
+	}
+	return payments, nil
+}
+
+// Payment looks up a payment in the ledger's history
+func (l *MemoryLedger) Payment(id string) (*Payment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, payment := range l.payments {
+		if payment.ID == id {
+			return &payment, nil
+		}
+	}
+	return nil, ErrPaymentNotFound
+}
+
+// recordPayments adds the payments of an applied transaction to the history
+func (l *MemoryLedger) recordPayments(tx *txnbuild.Transaction, hash, sourceID string) {
+	for i, op := range tx.Operations() {
+		payment, ok := op.(*txnbuild.Payment)
+		if !ok {
+			continue
+		}
+		to, _ := AccountID(payment.Destination)
+		amount, _ := money.ParseDecimal(payment.Amount, tokenDecimals)
+		record := Payment{
+			ID:              fmt.Sprintf("%d%04d", l.ledger, i+1),
+			Cursor:          strconv.Itoa(len(l.payments) + 1),
+			TransactionHash: hash,
+			From:            opSource(op, sourceID),
+			To:              to,
+			AssetCode:       "XLM",
+			Amount:          money.FormatDecimal(amount, tokenDecimals),
+			Memo:            memoText(tx.Memo()),
+			ReceivedAt:      time.Now(),
+		}
+		if !payment.Asset.IsNative() {
+			record.AssetCode, record.AssetIssuer = payment.Asset.GetCode(), payment.Asset.GetIssuer()
+		}
+		l.payments = append(l.payments, record)
+	}
+}
+
+// Transaction looks up a transaction included in the ledger
+func (l *MemoryLedger) Transaction(hash string) (*TransactionResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := l.results[hash]
+	if result == nil {
+		return nil, ErrTransactionNotFound
+	}
+	copied := *result
+	return &copied, nil
+}
+
+// checkSignatures requires a valid signature from the transaction's source
+// and every operation source
+func (l *MemoryLedger) checkSignatures(tx *txnbuild.Transaction) error {
+	hash, err := tx.Hash(l.passphrase)
+	if err != nil {
+		return err
+	}
+	signers := map[string]bool{tx.SourceAccount().AccountID: true}
+	for _, op := range tx.Operations() {
+		if source := op.GetSourceAccount(); source != "" {
+			signers[source] = true
+		}
+	}
+
+	for signer := range signers {
+		kp, err := keypair.ParseAddress(signer)
+		if err != nil {
+			return err
+		}
+		signed := false
+		for _, sig := range tx.Signatures() {
+			if sig.Hint == kp.Hint() && kp.Verify(hash[:], sig.Signature) == nil {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			return &SubmitError{TransactionCode: "tx_bad_auth"}
+		}
+	}
+	return nil
+}
+
+// apply performs one operation on the accounts
+func apply(accounts map[string]*memoryAccount, sourceID string, op txnbuild.Operation) error {
+	source := accounts[sourceID]
+	if source == nil {
+		return errors.New("op_no_source_account")
+	}
+
+	switch op := op.(type) {
+	case *txnbuild.CreateAccount:
+		amount, err := money.ParseDecimal(op.Amount, tokenDecimals)
+		if err != nil {
+			return err
+		}
+		if accounts[op.Destination] != nil {
+			return errors.New("op_already_exists")
+		}
+		if source.lines[nativeKey].balance < amount {
+			return errors.New("op_underfunded")
+		}
+		source.lines[nativeKey].balance -= amount
+		accounts[op.Destination] = newMemoryAccount()
+		accounts[op.Destination].lines[nativeKey].balance = amount
+		return nil
+
+	case *txnbuild.ChangeTrust:
+		code, issuer := op.Line.GetCode(), op.Line.GetIssuer()
+		if op.Line.IsNative() || issuer == sourceID {
+			return errors.New("op_malformed")
+		}
+		key := code + ":" + issuer
+		if op.Limit == "0" {
+			if line := source.lines[key]; line != nil && line.balance > 0 {
+				return errors.New("op_invalid_limit")
+			}
+			delete(source.lines, key)
+			return nil
+		}
+		if source.lines[key] == nil {
+			issuerAccount := accounts[issuer]
+			if issuerAccount == nil {
+				return errors.New("op_no_issuer")
+			}
+			source.lines[key] = &memoryLine{code: code, issuer: issuer, clawback: issuerAccount.clawbackEnabled}
+		}
+		return nil
+
+	case *txnbuild.Payment:
+		amount, err := money.ParseDecimal(op.Amount, tokenDecimals)
+		if err != nil || amount <= 0 {
+			return errors.New("op_malformed")
+		}
+		destinationID, err := AccountID(op.Destination)
+		if err != nil {
+			return errors.New("op_malformed")
+		}
+		destination := accounts[destinationID]
+		if destination == nil {
+			return errors.New("op_no_destination")
+		}
+		key := nativeKey
+		issuer := ""
+		if !op.Asset.IsNative() {
+			issuer = op.Asset.GetIssuer()
+			key = op.Asset.GetCode() + ":" + issuer
+		}
+		// Issuers create an asset by paying it and destroy it when paid.
+		// Checked before any balance moves, so a failed payment leaves the
+		// operations after it unaffected.
+		from, to := source.lines[key], destination.lines[key]
+		if sourceID != issuer {
+			if from == nil {
+				return errors.New("op_src_no_trust")
+			}
+			if from.balance < amount {
+				return errors.New("op_underfunded")
+			}
+		}
+		if destinationID != issuer && to == nil {
+			return errors.New("op_no_trust")
+		}
+		if sourceID != issuer {
+			from.balance -= amount
+		}
+		if destinationID != issuer {
+			to.balance += amount
+		}
+		return nil
+
+	case *txnbuild.Clawback:
+		amount, err := money.ParseDecimal(op.Amount, tokenDecimals)
+		if err != nil || amount <= 0 {
+			return errors.New("op_malformed")
+		}
+		if op.Asset.GetIssuer() != sourceID {
+			return errors.New("op_malformed")
+		}
+		from := accounts[op.From]
+		if from == nil {
+			return errors.New("op_no_trust")
+		}
+		line := from.lines[op.Asset.GetCode()+":"+sourceID]
+		if line == nil {
+			return errors.New("op_no_trust")
+		}
+		if !line.clawback {
+			return errors.New("op_not_clawback_enabled")
+		}
+		if line.balance < amount {
+			return errors.New("op_underfunded")
+		}
+		line.balance -= amount
+		return nil
+
+	case *txnbuild.SetOptions:
+		for _, flag := range op.SetFlags {
+			setFlag(source, flag, true)
+		}
+		for _, flag := range op.ClearFlags {
+			setFlag(source, flag, false)
+		}
+		return nil
+	}
+	return errors.New("op_not_supported")
+}
+
+// memoText returns the text of a text memo, empty for other memos
+func memoText(memo txnbuild.Memo) string {
+	if text, ok := memo.(txnbuild.MemoText); ok {
+		return string(text)
+	}
+	return ""
+}
+
+func setFlag(account *memoryAccount, flag txnbuild.AccountFlag, value bool) {
+	switch flag {
+	case txnbuild.AuthRevocable:
+		account.authRevocable = value
+	case txnbuild.AuthClawbackEnabled:
+		account.clawbackEnabled = value
+	}
+}
+
+func opSource(op txnbuild.Operation, txSource string) string {
+	if source := op.GetSourceAccount(); source != "" {
+		return source
+	}
+	return txSource
+}
+
+func assetKey(asset txnbuild.CreditAsset) string {
+	return asset.Code + ":" + asset.Issuer
+}
+
+func newMemoryAccount() *memoryAccount {
+	return &memoryAccount{lines: map[string]*memoryLine{nativeKey: {}}}
+}
+
+func (l *MemoryLedger) clone() map[string]*memoryAccount {
+	accounts := make(map[string]*memoryAccount, len(l.accounts))
+	for id, account := range l.accounts {
+		copied := *account
+		copied.lines = make(map[string]*memoryLine, len(account.lines))
+		for key, line := range account.lines {
+			lineCopy := *line
+			copied.lines[key] = &lineCopy
+		}
+		accounts[id] = &copied
+	}
+	return accounts
+}
+
+func (a *memoryAccount) sortedLines() []*memoryLine {
+	lines := make([]*memoryLine, 0, len(a.lines))
+	for _, line := range a.lines {
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].issuer != lines[j].issuer {
+			return lines[i].issuer < lines[j].issuer
+		}
+		return lines[i].code < lines[j].code
+	})
+	return lines
+}
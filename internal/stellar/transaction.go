@@ -0,0 +1,66 @@
+package stellar
+
+import (
+	"0xFarms-backend/pkg/money"
+	"errors"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+var errNotTransaction = errors.New("fee bump transactions are not supported")
+
+// transactionTimeout is how long, in seconds, a built transaction stays
+// valid for signing and submission
+const transactionTimeout = 300
+
+// NewTransaction builds a transaction from the account's next sequence
+// number. The memo may be nil.
+func NewTransaction(source *Account, memo txnbuild.Memo, ops ...txnbuild.Operation) (*txnbuild.Transaction, error) {
+	return txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        source.TxnAccount(),
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              txnbuild.MinBaseFee,
+		Memo:                 memo,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewTimeout(transactionTimeout)},
+	})
+}
+
+// NewTrustline builds a transaction in which the account trusts an asset,
+// for the account holder to sign
+func NewTrustline(source *Account, asset txnbuild.CreditAsset) (*txnbuild.Transaction, error) {
+	return NewTransaction(source, nil, &txnbuild.ChangeTrust{
+		Line:  txnbuild.ChangeTrustAssetWrapper{Asset: asset},
+		Limit: txnbuild.MaxTrustlineLimit,
+	})
+}
+
+// NewPurchasePayment builds the payment for a share purchase from the
+// buyer's account, for the buyer to sign. The ID of the investment or trade
+// bought goes in the memo so the payment is matched to it once received.
+func NewPurchasePayment(source *Account, destination string, asset txnbuild.Asset, amount money.Amount, purchaseID string) (*txnbuild.Transaction, error) {
+	if amount <= 0 {
+		return nil, errors.New("payment amount must be positive")
+	}
+	if len(purchaseID) > 28 {
+		return nil, errors.New("purchase ID does not fit in a text memo")
+	}
+	return NewTransaction(source, txnbuild.MemoText(purchaseID), &txnbuild.Payment{
+		Destination: destination,
+		Amount:      amount.String(),
+		Asset:       asset,
+	})
+}
+
+// ParseTransaction decodes a base64 transaction envelope
+func ParseTransaction(envelope string) (*txnbuild.Transaction, error) {
+	generic, err := txnbuild.TransactionFromXDR(envelope)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := generic.Transaction()
+	if !ok {
+		return nil, errNotTransaction
+	}
+	return tx, nil
+}
@@ -0,0 +1,85 @@
+package stellar
+
+import (
+	"0xFarms-backend/pkg/money"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+func TestPurchasePayment(t *testing.T) {
+	buyer, treasury := keypair.MustRandom(), keypair.MustRandom()
+	ledger := NewMemoryLedger(network.TestNetworkPassphrase)
+	for _, key := range []*keypair.Full{buyer, treasury} {
+		if err := ledger.Fund(key.Address(), "100"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	account, err := ledger.Account(buyer.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := NewPurchasePayment(account, treasury.Address(), txnbuild.NativeAsset{}, money.MustParse("12.5"), "65f1c0ffee0123456789abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unsigned payments are refused, the buyer's signature makes them valid
+	if _, err := ledger.Submit(tx); err == nil {
+		t.Fatal("unsigned payment accepted")
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, buyer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.Submit(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := ledger.Payments(treasury.Address(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments) != 1 {
+		t.Fatalf("got %d payments", len(payments))
+	}
+	p := payments[0]
+	if p.From != buyer.Address() || p.Memo != "65f1c0ffee0123456789abcd" || p.AssetCode != "XLM" {
+		t.Fatalf("payment %+v", p)
+	}
+	if amount, err := money.Parse(p.Amount); err != nil || amount != money.MustParse("12.5") {
+		t.Fatalf("amount %s", p.Amount)
+	}
+}
+
+func TestPurchasePaymentRejects(t *testing.T) {
+	account := &Account{ID: keypair.MustRandom().Address()}
+	destination := keypair.MustRandom().Address()
+	if _, err := NewPurchasePayment(account, destination, txnbuild.NativeAsset{}, 0, "investment"); err == nil {
+		t.Fatal("zero payment built")
+	}
+	if _, err := NewPurchasePayment(account, destination, txnbuild.NativeAsset{}, money.MustParse("1"), "an-investment-id-longer-than-a-memo"); err == nil {
+		t.Fatal("memo longer than 28 bytes built")
+	}
+}
+
+func TestAssetCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, farmID := range []string{"65f1c0ffee0123456789abcd", "65f1c0ffee0123456789abce", "75f1c0ffee0123456789abcd"} {
+		for attempt := 0; attempt < 3; attempt++ {
+			code := AssetCode(farmID, attempt)
+			if _, err := (txnbuild.CreditAsset{Code: code, Issuer: keypair.MustRandom().Address()}).ToXDR(); err != nil {
+				t.Fatalf("code %s is not a valid asset code: %v", code, err)
+			}
+			if seen[code] {
+				t.Fatalf("code %s derived twice", code)
+			}
+			seen[code] = true
+		}
+	}
+	if AssetCode("65f1c0ffee0123456789abcd", 0) != AssetCode("65f1c0ffee0123456789abcd", 0) {
+		t.Fatal("asset code is not stable")
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ActuatorHandler struct {
+	actuatorService *services.ActuatorService
+}
+
+// NewActuatorHandler creates a new instance of ActuatorHandler with the given services
+func NewActuatorHandler(actuatorService *services.ActuatorService) *ActuatorHandler {
+	return &ActuatorHandler{
+		actuatorService: actuatorService,
+	}
+}
+
+// commandRequest is an actuator command with how long it may wait for the device
+type commandRequest struct {
+	domain.ActuatorCommand
+	TTLSeconds int `json:"ttlSeconds"`
+}
+
+// EnqueueCommand queues a command for one output of a farm device
+func (h *ActuatorHandler) EnqueueCommand(c *gin.Context) {
+	var req commandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	command, err := h.actuatorService.Enqueue(c.Param("id"), req.ActuatorCommand, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": command})
+}
+
+// CommandHistory returns the commands issued for a farm in ?from=&to=
+func (h *ActuatorHandler) CommandHistory(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	commands, err := h.actuatorService.History(c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": commands})
+}
+
+// PendingCommands hands a device its outstanding commands
+func (h *ActuatorHandler) PendingCommands(c *gin.Context) {
+	commands, err := h.actuatorService.Pending(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": commands})
+}
+
+// AcknowledgeCommand records whether a device executed a command
+func (h *ActuatorHandler) AcknowledgeCommand(c *gin.Context) {
+	var req struct {
+		Executed bool   `json:"executed"`
+		Result   string `json:"result"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	command, err := h.actuatorService.Acknowledge(c.Param("id"), req.Executed, req.Result)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": command})
+}
+
+// CancelCommand withdraws a pending command
+func (h *ActuatorHandler) CancelCommand(c *gin.Context) {
+	var req struct {
+		By string `json:"by"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	command, err := h.actuatorService.Cancel(c.Param("id"), req.By)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": command})
+}
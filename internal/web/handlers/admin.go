@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin returns middleware admitting only requests that send token as
+// a bearer token. Every request is rejected when token is empty.
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth := []byte(c.GetHeader("Authorization"))
+		if token == "" || subtle.ConstantTimeCompare(auth, []byte("Bearer "+token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"statusCode": http.StatusUnauthorized, "message": "Invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertHandler struct {
+	alertService *services.AlertService
+}
+
+// NewAlertHandler creates a new instance of AlertHandler with the given services
+func NewAlertHandler(alertService *services.AlertService) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+	}
+}
+
+// ListAlerts returns alerts, optionally filtered by ?farmId= and ?state=
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	filters := &domain.AlertFilters{
+		FarmID: c.Query("farmId"),
+		State:  c.Query("state"),
+	}
+
+	alerts, err := h.alertService.ListAlerts(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": alerts})
+}
+
+// AcknowledgeAlert marks an open alert as acknowledged
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	var req struct {
+		By string `json:"by"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	alert, err := h.alertService.Acknowledge(c.Param("id"), req.By)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": alert})
+}
+
+// ResolveAlert closes an alert by hand
+func (h *AlertHandler) ResolveAlert(c *gin.Context) {
+	alert, err := h.alertService.Resolve(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": alert})
+}
+
+// ListAlertRules returns every alert rule
+func (h *AlertHandler) ListAlertRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": h.alertService.ListRules()})
+}
+
+// CreateAlertRule adds a new alert rule
+func (h *AlertHandler) CreateAlertRule(c *gin.Context) {
+	var rule domain.AlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	created, err := h.alertService.CreateRule(rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// DeleteAlertRule removes an alert rule
+func (h *AlertHandler) DeleteAlertRule(c *gin.Context) {
+	if err := h.alertService.DeleteRule(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Alert rule removed"})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	authService *services.AuthService
+	passphrase  string
+}
+
+// NewAuthHandler creates a new instance of AuthHandler with the given
+// services. The network passphrase is returned with challenges so clients
+// sign for the right network.
+func NewAuthHandler(authService *services.AuthService, passphrase string) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		passphrase:  passphrase,
+	}
+}
+
+// GetChallenge returns a login challenge for the account query parameter
+func (h *AuthHandler) GetChallenge(c *gin.Context) {
+	envelope, err := h.authService.Challenge(c.Query("account"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{
+		"transaction":        envelope,
+		"network_passphrase": h.passphrase,
+	}})
+}
+
+// Login exchanges a signed challenge for a session token
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req struct {
+		Transaction string `json:"transaction"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	token, session, err := h.authService.Login(req.Transaction)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"statusCode": http.StatusUnauthorized, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"token": token, "session": session}})
+}
+
+// GetSession returns the session of the bearer token
+func (h *AuthHandler) GetSession(c *gin.Context) {
+	session, err := h.authService.Session(bearerToken(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"statusCode": http.StatusUnauthorized, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": session})
+}
+
+// Logout ends the session of the bearer token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authService.Logout(bearerToken(c)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"statusCode": http.StatusUnauthorized, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Logged out"})
+}
+
+// sessionKey is the context key RequireSession keeps the session under
+const sessionKey = "session"
+
+// RequireSession resolves the bearer token of a request to its session and
+// rejects requests without a live one. Handlers behind it read the session
+// with currentSession.
+func (h *AuthHandler) RequireSession(c *gin.Context) {
+	session, err := h.authService.Session(bearerToken(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"statusCode": http.StatusUnauthorized, "message": "Login required"})
+		return
+	}
+
+	c.Set(sessionKey, session)
+	c.Next()
+}
+
+// currentSession returns the session RequireSession resolved for the request
+func currentSession(c *gin.Context) *domain.AuthSession {
+	return c.MustGet(sessionKey).(*domain.AuthSession)
+}
+
+// requireUser rejects the request unless its session acts for userID, that
+// is, the session's account is a wallet bound to the user
+func requireUser(c *gin.Context, userID string) bool {
+	if session := currentSession(c); session.UserID == "" || session.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"statusCode": http.StatusForbidden, "message": "Session does not act for this user"})
+		return false
+	}
+	return true
+}
+
+func bearerToken(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ControlHandler struct {
+	controlService *services.ControlService
+}
+
+// NewControlHandler creates a new instance of ControlHandler with the given services
+func NewControlHandler(controlService *services.ControlService) *ControlHandler {
+	return &ControlHandler{
+		controlService: controlService,
+	}
+}
+
+// CreatePolicy adds a control policy to a farm
+func (h *ControlHandler) CreatePolicy(c *gin.Context) {
+	var policy domain.ControlPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	created, err := h.controlService.CreatePolicy(c.Param("id"), policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// ListPolicies returns the control policies of a farm
+func (h *ControlHandler) ListPolicies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": h.controlService.ListPolicies(c.Param("id"))})
+}
+
+// DeletePolicy removes a control policy
+func (h *ControlHandler) DeletePolicy(c *gin.Context) {
+	if err := h.controlService.DeletePolicy(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Control policy removed"})
+}
+
+// OverridePolicy suspends a control policy on behalf of a technician
+func (h *ControlHandler) OverridePolicy(c *gin.Context) {
+	var req struct {
+		By     string    `json:"by" binding:"required"`
+		Until  time.Time `json:"until" binding:"required"`
+		Reason string    `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	policy, err := h.controlService.Override(c.Param("id"), req.By, req.Reason, req.Until)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": policy})
+}
+
+// ResumePolicy hands control back to an overridden policy
+func (h *ControlHandler) ResumePolicy(c *gin.Context) {
+	var req struct {
+		By string `json:"by"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	policy, err := h.controlService.Resume(c.Param("id"), req.By)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": policy})
+}
+
+// ListDecisions returns the audit trail of a farm's control policies in ?from=&to=
+func (h *ControlHandler) ListDecisions(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	decisions, err := h.controlService.Decisions(c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": decisions})
+}
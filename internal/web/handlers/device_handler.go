@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeviceHandler struct {
+	deviceService   *services.DeviceService
+	watchdogService *services.WatchdogService
+}
+
+// NewDeviceHandler creates a new instance of DeviceHandler with the given services
+func NewDeviceHandler(deviceService *services.DeviceService, watchdogService *services.WatchdogService) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService:   deviceService,
+		watchdogService: watchdogService,
+	}
+}
+
+// RegisterDevice adds a device to a farm
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	var device domain.Device
+	if err := c.ShouldBindJSON(&device); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	created, err := h.deviceService.RegisterDevice(c.Param("id"), device)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// ListDevices returns the devices of a farm
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	devices, err := h.deviceService.ListDevices(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": devices})
+}
+
+// GetFarmStatus returns the health and data freshness of a farm
+func (h *DeviceHandler) GetFarmStatus(c *gin.Context) {
+	status, err := h.watchdogService.GetFarmStatus(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": status})
+}
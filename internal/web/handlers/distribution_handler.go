@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DistributionHandler struct {
+	distributionService *services.DistributionService
+}
+
+// NewDistributionHandler creates a new instance of DistributionHandler with the given services
+func NewDistributionHandler(distributionService *services.DistributionService) *DistributionHandler {
+	return &DistributionHandler{
+		distributionService: distributionService,
+	}
+}
+
+// PreviewDistribution computes the payouts of a harvest without storing them
+func (h *DistributionHandler) PreviewDistribution(c *gin.Context) {
+	var req domain.DistributionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	distribution, err := h.distributionService.Preview(c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": distribution})
+}
+
+// FinalizeDistribution computes and stores the payouts of a harvest
+func (h *DistributionHandler) FinalizeDistribution(c *gin.Context) {
+	var req domain.DistributionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	distribution, err := h.distributionService.Finalize(c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": distribution})
+}
+
+// GetDistribution returns a stored distribution
+func (h *DistributionHandler) GetDistribution(c *gin.Context) {
+	distribution, err := h.distributionService.Distribution(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": distribution})
+}
+
+// ListDistributions returns the distributions of a farm
+func (h *DistributionHandler) ListDistributions(c *gin.Context) {
+	distributions, err := h.distributionService.FarmDistributions(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": distributions})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"0xFarms-backend/pkg/logger"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+// NewExportHandler creates a new instance of ExportHandler with the given services
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportReadings streams the readings of a farm for ?from=&to= as a file in
+// ?format= (csv, ndjson or xlsx). ?metrics= takes a comma separated list of
+// metric keys and ?resolution= (5m, 1h, 1d or auto) exports rollups instead
+// of raw readings.
+func (h *ExportHandler) ExportReadings(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	req := domain.ExportRequest{
+		Format:     c.DefaultQuery("format", domain.ExportCSV),
+		From:       from,
+		To:         to,
+		Resolution: c.Query("resolution"),
+	}
+	if v := c.Query("metrics"); v != "" {
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				req.Metrics = append(req.Metrics, key)
+			}
+		}
+	}
+
+	filename := "readings-" + c.Param("id") + "." + req.Format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Type", services.ExportContentType(req.Format))
+
+	// Large exports outlast the server write timeout, they end when the last
+	// row is written or the client leaves
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	if err := h.exportService.Export(c.Param("id"), req, c.Writer); err != nil {
+		// Once rows have been sent the status can no longer change
+		if c.Writer.Written() {
+			logger.LogWarning(fmt.Sprintf("Export of farm %s failed: %v", c.Param("id"), err))
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+	}
+}
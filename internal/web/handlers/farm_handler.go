@@ -1,6 +1,14 @@
 package handlers
 
-import "0xFarms-backend/internal/core/services"
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
 
 type FarmHandler struct {
 	farmService *services.FarmManagementSystemService
@@ -12,3 +20,182 @@ func NewFarmHandler(farmService *services.FarmManagementSystemService) *FarmHand
 		farmService: farmService,
 	}
 }
+
+// SaveCropSpecification creates or replaces a crop specification
+func (h *FarmHandler) SaveCropSpecification(c *gin.Context) {
+	var spec domain.CropSpecification
+	if err := c.ShouldBindJSON(&spec); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	if err := h.farmService.SaveCropSpecification(spec); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": spec})
+}
+
+type createFarmRequest struct {
+	Width    float64 `json:"width" binding:"required"`
+	Height   float64 `json:"height" binding:"required"`
+	CropType string  `json:"cropType" binding:"required"`
+}
+
+// CreateFarm creates a new vertical farm
+func (h *FarmHandler) CreateFarm(c *gin.Context) {
+	var req createFarmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	farm, err := h.farmService.CreateFarm(req.Width, req.Height, req.CropType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": farm})
+}
+
+// GetFarm returns a farm with its readings rendered in the caller's preferred
+// units, selected with ?units=<preset> and ?unit[<metric>]=<unit>
+func (h *FarmHandler) GetFarm(c *gin.Context) {
+	preferred, err := services.PreferredUnits(c.Query("units"), c.QueryMap("unit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	farm, units, err := h.farmService.GetFarmInUnits(c.Param("id"), preferred)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": farm, "units": units})
+}
+
+// readingRequest is an IoT reading together with the units its values are
+// reported in, keyed by metric
+type readingRequest struct {
+	domain.IoTReading
+	Units map[string]string `json:"units"`
+}
+
+// UnmarshalJSON decodes the reading and its units separately, as the
+// reading's own decoder would otherwise take over the whole document
+func (r *readingRequest) UnmarshalJSON(data []byte) error {
+	if err := r.IoTReading.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	var units struct {
+		Units map[string]string `json:"units"`
+	}
+	if err := json.Unmarshal(data, &units); err != nil {
+		return err
+	}
+	r.Units = units.Units
+	return nil
+}
+
+// AddReading ingests a sensor reading for a farm
+func (h *FarmHandler) AddReading(c *gin.Context) {
+	var req readingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	if req.Timestamp.IsZero() {
+		req.Timestamp = time.Now()
+	}
+
+	if err := h.farmService.IngestReading(c.Param("id"), req.IoTReading, req.Units); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Reading added"})
+}
+
+// SetReportingInterval sets how often a farm is expected to send readings
+func (h *FarmHandler) SetReportingInterval(c *gin.Context) {
+	var req struct {
+		Seconds int `json:"seconds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	if err := h.farmService.SetReportingInterval(c.Param("id"), req.Seconds); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Reporting interval updated"})
+}
+
+// SetStatus moves a farm to a lifecycle status
+func (h *FarmHandler) SetStatus(c *gin.Context) {
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	if err := h.farmService.SetStatus(c.Param("id"), req.Status); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Farm status updated"})
+}
+
+// SetReservoir describes the nutrient tank of a farm
+func (h *FarmHandler) SetReservoir(c *gin.Context) {
+	var reservoir domain.Reservoir
+	if err := c.ShouldBindJSON(&reservoir); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	if err := h.farmService.SetReservoir(c.Param("id"), reservoir); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Reservoir updated"})
+}
+
+// RecordHarvest records the produce taken from a farm at the end of a cycle
+func (h *FarmHandler) RecordHarvest(c *gin.Context) {
+	var harvest domain.HarvestRecord
+	if err := c.ShouldBindJSON(&harvest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recorded, err := h.farmService.RecordHarvest(c.Param("id"), harvest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": recorded})
+}
+
+// ListHarvests returns the harvest records of a farm
+func (h *FarmHandler) ListHarvests(c *gin.Context) {
+	harvests, err := h.farmService.ListHarvests(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": harvests})
+}
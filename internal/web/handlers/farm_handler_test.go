@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"0xFarms-backend/internal/ports"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readingDB holds one lettuce farm and keeps the readings added to it.
+// Operations a test does not use fall through to the nil ports.MongoDB.
+type readingDB struct {
+	ports.MongoDB
+	readings []domain.IoTReading
+}
+
+func (db *readingDB) GetFarm(id string) (*domain.VerticalFarm, error) {
+	return &domain.VerticalFarm{ID: id, CropType: "lettuce", TotalArea: 10}, nil
+}
+
+func (db *readingDB) GetCropSpecification(cropType string) (domain.CropSpecification, error) {
+	return domain.CropSpecification{Name: cropType, OptimalTemp: 20, OptimalHumidity: 60, OptimalPH: 6, NutrientNeeds: 50}, nil
+}
+
+func (db *readingDB) AddIoTReading(farmID string, reading *domain.IoTReading) error {
+	db.readings = append(db.readings, *reading)
+	return nil
+}
+
+func TestAddReadingUnits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := &readingDB{}
+	handler := NewFarmHandler(services.NewFarmManagementSystemService(db, services.NewMetricRegistry(db)))
+	router := gin.New()
+	router.POST("/farms/:id/readings", handler.AddReading)
+
+	body := `{"temperature":75,"humidity":0,"units":{"temperature":"F"}}`
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/farms/farm-1/readings", strings.NewReader(body)))
+	if recorder.Code != http.StatusOK || len(db.readings) != 1 {
+		t.Fatalf("status %d, %d readings stored: %s", recorder.Code, len(db.readings), recorder.Body)
+	}
+
+	reading := db.readings[0]
+	temperature, ok := reading.Value(domain.MetricTemperature)
+	if !ok || math.Abs(temperature-23.889) > 0.01 {
+		t.Errorf("temperature = %v (present %v), want 75 F stored as 23.89 C", temperature, ok)
+	}
+	if humidity, ok := reading.Value(domain.MetricHumidity); !ok || humidity != 0 {
+		t.Errorf("humidity = %v (present %v), want a zero reading kept", humidity, ok)
+	}
+	if reading.Timestamp.IsZero() {
+		t.Error("reading has no timestamp")
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	importService *services.ImportService
+}
+
+// NewImportHandler creates a new instance of ImportHandler with the given services
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportReadings starts importing historical readings from an uploaded CSV or
+// Parquet file. The multipart form carries the file, and optionally format,
+// timezone, timestampFormat and the JSON objects mapping (column to metric,
+// timestamp or deviceId) and units (metric to unit).
+func (h *ImportHandler) ImportReadings(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "file is required"})
+		return
+	}
+
+	job := domain.ImportJob{
+		FileName:        header.Filename,
+		Format:          c.PostForm("format"),
+		Timezone:        c.PostForm("timezone"),
+		TimestampFormat: c.PostForm("timestampFormat"),
+	}
+	for field, target := range map[string]*map[string]string{"mapping": &job.Mapping, "units": &job.Units} {
+		if v := c.PostForm(field); v != "" {
+			if err := json.Unmarshal([]byte(v), target); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "invalid " + field + ": " + err.Error()})
+				return
+			}
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	created, err := h.importService.Create(c.Param("id"), job, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// ListImports returns the import jobs of a farm
+func (h *ImportHandler) ListImports(c *gin.Context) {
+	jobs, err := h.importService.List(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": jobs})
+}
+
+// GetImport returns an import job and its progress
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	job, err := h.importService.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": job})
+}
+
+// GetRejections returns the rows an import job rejected, as a CSV report
+// with ?format=csv
+func (h *ImportHandler) GetRejections(c *gin.Context) {
+	rejections, err := h.importService.Rejections(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": rejections})
+		return
+	}
+
+	// Columns of the original file follow the row number and reason
+	seen := make(map[string]bool)
+	var columns []string
+	for _, r := range rejections {
+		for name := range r.Values {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	c.Header("Content-Disposition", `attachment; filename="rejections-`+c.Param("id")+`.csv"`)
+	c.Header("Content-Type", "text/csv")
+	w := csv.NewWriter(c.Writer)
+	w.Write(append([]string{"row", "reason"}, columns...))
+	for _, r := range rejections {
+		record := []string{strconv.FormatInt(r.Row, 10), r.Reason}
+		for _, name := range columns {
+			record = append(record, r.Values[name])
+		}
+		w.Write(record)
+	}
+	w.Flush()
+}
+
+// CancelImport stops an import job after its current batch
+func (h *ImportHandler) CancelImport(c *gin.Context) {
+	job, err := h.importService.Cancel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": job})
+}
+
+// ResumeImport restarts a failed or cancelled import job where it stopped
+func (h *ImportHandler) ResumeImport(c *gin.Context) {
+	job, err := h.importService.Resume(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": job})
+}
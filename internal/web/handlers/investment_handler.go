@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvestmentHandler struct {
+	investmentService *services.InvestmentService
+}
+
+// NewInvestmentHandler creates a new instance of InvestmentHandler with the given services
+func NewInvestmentHandler(investmentService *services.InvestmentService) *InvestmentHandler {
+	return &InvestmentHandler{
+		investmentService: investmentService,
+	}
+}
+
+// Invest records a pending investment in a farm for the user of the session
+func (h *InvestmentHandler) Invest(c *gin.Context) {
+	var investment domain.Investment
+	if err := c.ShouldBindJSON(&investment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	if investment.InvestorID == "" {
+		investment.InvestorID = currentSession(c).UserID
+	}
+	if !requireUser(c, investment.InvestorID) {
+		return
+	}
+
+	created, err := h.investmentService.Invest(c.Param("id"), investment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// SetOffering opens a farm to investment at the given price
+func (h *InvestmentHandler) SetOffering(c *gin.Context) {
+	var offering domain.Offering
+	if err := c.ShouldBindJSON(&offering); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	updated, err := h.investmentService.SetOffering(c.Param("id"), offering)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": updated})
+}
+
+// CloseOffering stops new investment in a farm
+func (h *InvestmentHandler) CloseOffering(c *gin.Context) {
+	if err := h.investmentService.CloseOffering(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Offering closed"})
+}
+
+// ListFarmInvestments returns the investments in a farm
+func (h *InvestmentHandler) ListFarmInvestments(c *gin.Context) {
+	investments, err := h.investmentService.FarmInvestments(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": investments})
+}
+
+// ListInvestorInvestments returns the investments of an investor
+func (h *InvestmentHandler) ListInvestorInvestments(c *gin.Context) {
+	if !requireUser(c, c.Param("id")) {
+		return
+	}
+
+	investments, err := h.investmentService.InvestorInvestments(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": investments})
+}
+
+// ConfirmInvestment marks an investment as paid, making the investor an
+// owner. Payments on Stellar confirm investments as they are reconciled; this
+// is for operators settling payments made some other way.
+func (h *InvestmentHandler) ConfirmInvestment(c *gin.Context) {
+	investment, err := h.investmentService.Confirm(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": investment})
+}
+
+// RefundInvestment returns an investment and releases its shares
+func (h *InvestmentHandler) RefundInvestment(c *gin.Context) {
+	investment, err := h.investmentService.Refund(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": investment})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"0xFarms-backend/internal/lorawan"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoRaWANHandler struct {
+	lorawanService *services.LoRaWANService
+	token          string
+}
+
+// NewLoRaWANHandler creates a new instance of LoRaWANHandler with the given
+// services. When token is set, webhooks must send it as a bearer token.
+func NewLoRaWANHandler(lorawanService *services.LoRaWANService, token string) *LoRaWANHandler {
+	return &LoRaWANHandler{
+		lorawanService: lorawanService,
+		token:          token,
+	}
+}
+
+// ReceiveUplink ingests an uplink webhook from The Things Stack or ChirpStack.
+// Other network server events, such as joins, are acknowledged and ignored.
+func (h *LoRaWANHandler) ReceiveUplink(c *gin.Context) {
+	if h.token != "" {
+		auth := []byte(c.GetHeader("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("Bearer "+h.token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"statusCode": http.StatusUnauthorized, "message": "Invalid webhook token"})
+			return
+		}
+	}
+
+	// ChirpStack posts every event type to the same URL
+	if event := c.Query("event"); event != "" && event != "up" {
+		c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Event ignored"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	reading, err := h.lorawanService.HandleUplink(body)
+	if errors.Is(err, lorawan.ErrNotUplink) {
+		c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Event ignored"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": reading})
+}
+
+// ListDecoders returns the names of the payload decoders devices can use
+func (h *LoRaWANHandler) ListDecoders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": lorawan.Decoders()})
+}
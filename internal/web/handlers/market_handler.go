@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MarketHandler struct {
+	marketService *services.MarketService
+}
+
+// NewMarketHandler creates a new instance of MarketHandler with the given services
+func NewMarketHandler(marketService *services.MarketService) *MarketHandler {
+	return &MarketHandler{
+		marketService: marketService,
+	}
+}
+
+// PlaceOrder adds an ask or bid to a farm's order book and matches it
+func (h *MarketHandler) PlaceOrder(c *gin.Context) {
+	var order domain.ShareOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	placed, trades, err := h.marketService.PlaceOrder(currentSession(c), c.Param("id"), order)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"order": placed, "trades": trades}})
+}
+
+// GetOrderBook returns the open orders of a farm
+func (h *MarketHandler) GetOrderBook(c *gin.Context) {
+	book, err := h.marketService.OrderBook(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": book})
+}
+
+// CancelOrder takes an open order off the book
+func (h *MarketHandler) CancelOrder(c *gin.Context) {
+	order, err := h.marketService.CancelOrder(currentSession(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": order})
+}
+
+// TransferShares moves shares from an owner to another address
+func (h *MarketHandler) TransferShares(c *gin.Context) {
+	var req struct {
+		OwnerID string        `json:"ownerId"`
+		Address string        `json:"address"`
+		Shares  domain.Shares `json:"shareSize"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	trade, err := h.marketService.Transfer(currentSession(c), c.Param("id"), req.OwnerID, req.Address, req.Shares)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": trade})
+}
+
+// ListTrades returns the trades of a farm
+func (h *MarketHandler) ListTrades(c *gin.Context) {
+	trades, err := h.marketService.Trades(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": trades})
+}
+
+// BuildTradePayment returns an unsigned transaction paying the seller of a
+// pending trade from the given account
+func (h *MarketHandler) BuildTradePayment(c *gin.Context) {
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	envelope, err := h.marketService.PaymentTransaction(c.Param("id"), req.Address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"transaction": envelope}})
+}
+
+// SettleTrade moves the shares of a pending trade to the buyer once the
+// given ledger payment has paid for them
+func (h *MarketHandler) SettleTrade(c *gin.Context) {
+	var req struct {
+		PaymentID string `json:"paymentId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	trade, err := h.marketService.SettleTrade(c.Param("id"), req.PaymentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": trade})
+}
+
+// CancelTrade cancels a pending trade that was never paid for
+func (h *MarketHandler) CancelTrade(c *gin.Context) {
+	trade, err := h.marketService.CancelTrade(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": trade})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricHandler struct {
+	metrics *services.MetricRegistry
+}
+
+// NewMetricHandler creates a new instance of MetricHandler with the given registry
+func NewMetricHandler(metrics *services.MetricRegistry) *MetricHandler {
+	return &MetricHandler{
+		metrics: metrics,
+	}
+}
+
+// ListMetrics returns every registered sensor metric
+func (h *MetricHandler) ListMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": h.metrics.List()})
+}
+
+// RegisterMetric registers a new sensor metric or updates an existing one
+func (h *MetricHandler) RegisterMetric(c *gin.Context) {
+	var metric domain.MetricDefinition
+	if err := c.ShouldBindJSON(&metric); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	if err := h.metrics.Register(metric); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": metric})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OwnershipHandler struct {
+	ownershipService *services.OwnershipService
+}
+
+// NewOwnershipHandler creates a new instance of OwnershipHandler with the given services
+func NewOwnershipHandler(ownershipService *services.OwnershipService) *OwnershipHandler {
+	return &OwnershipHandler{
+		ownershipService: ownershipService,
+	}
+}
+
+// GetCapTable returns the holders of a farm at the RFC3339 at query
+// parameter, now when absent
+func (h *OwnershipHandler) GetCapTable(c *gin.Context) {
+	at := time.Now()
+	if v := c.Query("at"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "invalid at: " + err.Error()})
+			return
+		}
+		at = parsed
+	}
+
+	table, err := h.ownershipService.CapTable(c.Param("id"), at)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": table})
+}
+
+// ListOwnershipEvents returns the ownership history of a farm
+func (h *OwnershipHandler) ListOwnershipEvents(c *gin.Context) {
+	events, err := h.ownershipService.History(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": events})
+}
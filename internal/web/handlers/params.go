@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTimeRange reads the RFC3339 from and to query parameters. to defaults
+// to now and from defaults to defaultSpan before to.
+func parseTimeRange(c *gin.Context, defaultSpan time.Duration) (from, to time.Time, err error) {
+	to = time.Now()
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			return from, to, fmt.Errorf("invalid to: %v", err)
+		}
+	}
+
+	from = to.Add(-defaultSpan)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			return from, to, fmt.Errorf("invalid from: %v", err)
+		}
+	}
+
+	return from, to, nil
+}
+
+// optionalFloat reads a numeric query parameter, returning nil when it is absent
+func optionalFloat(c *gin.Context, name string) (*float64, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+}
+
+// NewPaymentHandler creates a new instance of PaymentHandler with the given services
+func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+// ListPayments returns the recorded incoming payments, filtered by the
+// status query parameter; status=review lists those needing review
+func (h *PaymentHandler) ListPayments(c *gin.Context) {
+	payments, err := h.paymentService.Payments(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": payments})
+}
+
+// BuildPayment returns an unsigned transaction paying for a pending
+// investment from the given account
+func (h *PaymentHandler) BuildPayment(c *gin.Context) {
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	envelope, err := h.paymentService.PaymentTransaction(c.Param("id"), req.Address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"transaction": envelope}})
+}
+
+// SyncPayments reconciles the payments received since the last sync
+func (h *PaymentHandler) SyncPayments(c *gin.Context) {
+	recorded, err := h.paymentService.Sync()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": recorded})
+}
+
+// ImportPayments reconciles the payments with the given ledger operation
+// IDs, reading them from the ledger
+func (h *PaymentHandler) ImportPayments(c *gin.Context) {
+	var req struct {
+		PaymentIDs []string `json:"paymentIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recorded, duplicates, err := h.paymentService.Import(req.PaymentIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"recorded": recorded, "duplicates": duplicates}})
+}
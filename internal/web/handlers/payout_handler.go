@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayoutHandler struct {
+	payoutService *services.PayoutService
+}
+
+// NewPayoutHandler creates a new instance of PayoutHandler with the given services
+func NewPayoutHandler(payoutService *services.PayoutService) *PayoutHandler {
+	return &PayoutHandler{
+		payoutService: payoutService,
+	}
+}
+
+// PreparePayout batches the unpaid lines of a distribution for payment
+func (h *PayoutHandler) PreparePayout(c *gin.Context) {
+	var req struct {
+		// Issuer of the distribution's currency, empty for XLM
+		Issuer string `json:"issuer"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	payout, err := h.payoutService.Prepare(c.Param("id"), req.Issuer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": payout})
+}
+
+// GetDistributionPayout returns the payout of a distribution
+func (h *PayoutHandler) GetDistributionPayout(c *gin.Context) {
+	payout, err := h.payoutService.DistributionPayout(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": payout})
+}
+
+// GetPayout returns a payout with the status of its transactions
+func (h *PayoutHandler) GetPayout(c *gin.Context) {
+	payout, err := h.payoutService.Payout(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": payout})
+}
+
+// SubmitPayout submits the unconfirmed transactions of a payout
+func (h *PayoutHandler) SubmitPayout(c *gin.Context) {
+	payout, err := h.payoutService.Submit(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": payout})
+}
+
+// ReconcilePayout updates a payout from the ledger
+func (h *PayoutHandler) ReconcilePayout(c *gin.Context) {
+	payout, err := h.payoutService.Reconcile(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": payout})
+}
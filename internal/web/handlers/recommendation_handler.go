@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+}
+
+// NewRecommendationHandler creates a new instance of RecommendationHandler with the given services
+func NewRecommendationHandler(recommendationService *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// GetRecommendation recommends doses for a farm's tank without storing the
+// recommendation. The farm's reservoir and latest reading can be overridden
+// with ?tankLiters=, ?concentrateStrength=, ?phDownStrength=, ?phUpStrength=,
+// ?ec=, ?ph= and ?stage=.
+func (h *RecommendationHandler) GetRecommendation(c *gin.Context) {
+	inputs := services.DosingInputs{Stage: c.Query("stage")}
+	numbers := map[string]*float64{
+		"tankLiters":          &inputs.VolumeLiters,
+		"concentrateStrength": &inputs.ConcentrateStrength,
+		"phDownStrength":      &inputs.PHDownStrength,
+		"phUpStrength":        &inputs.PHUpStrength,
+	}
+	for name, field := range numbers {
+		v, err := optionalFloat(c, name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+			return
+		}
+		if v != nil {
+			*field = *v
+		}
+	}
+
+	var err error
+	if inputs.Nutrient, err = optionalFloat(c, "ec"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	if inputs.PH, err = optionalFloat(c, "ph"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recommendation, err := h.recommendationService.Recommend(c.Param("id"), inputs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": recommendation})
+}
+
+// CreateRecommendation recommends doses for a farm's tank and stores the
+// recommendation so a technician can respond to it. The body overrides the
+// farm's reservoir and latest reading like the query of GetRecommendation.
+func (h *RecommendationHandler) CreateRecommendation(c *gin.Context) {
+	var req struct {
+		TankLiters          float64  `json:"tankLiters"`
+		ConcentrateStrength float64  `json:"concentrateStrength"`
+		PHDownStrength      float64  `json:"phDownStrength"`
+		PHUpStrength        float64  `json:"phUpStrength"`
+		EC                  *float64 `json:"ec"`
+		PH                  *float64 `json:"ph"`
+		Stage               string   `json:"stage"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recommendation, err := h.recommendationService.Issue(c.Param("id"), services.DosingInputs{
+		VolumeLiters:        req.TankLiters,
+		ConcentrateStrength: req.ConcentrateStrength,
+		PHDownStrength:      req.PHDownStrength,
+		PHUpStrength:        req.PHUpStrength,
+		Nutrient:            req.EC,
+		PH:                  req.PH,
+		Stage:               req.Stage,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": recommendation})
+}
+
+// RespondToRecommendation records whether a technician followed a recommendation
+func (h *RecommendationHandler) RespondToRecommendation(c *gin.Context) {
+	var req struct {
+		Followed *bool  `json:"followed" binding:"required"`
+		By       string `json:"by"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recommendation, err := h.recommendationService.Respond(c.Param("id"), *req.Followed, req.By)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": recommendation})
+}
+
+// RecommendationHistory returns a farm's recommendations in ?from=&to= and how
+// health changed after the ones that were followed and ignored
+func (h *RecommendationHandler) RecommendationHistory(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recommendations, summary, err := h.recommendationService.History(c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"recommendations": recommendations, "summary": summary}})
+}
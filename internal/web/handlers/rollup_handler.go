@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RollupHandler struct {
+	rollupService *services.RollupService
+}
+
+// NewRollupHandler creates a new instance of RollupHandler with the given services
+func NewRollupHandler(rollupService *services.RollupService) *RollupHandler {
+	return &RollupHandler{
+		rollupService: rollupService,
+	}
+}
+
+// GetRollups returns aggregated readings and data gaps of a farm for
+// ?from=&to=, at the resolution given by ?resolution= or one picked from the range
+func (h *RollupHandler) GetRollups(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	series, err := h.rollupService.Query(c.Param("id"), from, to, c.Query("resolution"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": series})
+}
+
+// RebuildRollups recomputes every rollup of a farm from its stored readings
+func (h *RollupHandler) RebuildRollups(c *gin.Context) {
+	if err := h.rollupService.Rebuild(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Rollups rebuilt"})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// streamHeartbeat keeps idle streams from being closed by proxies
+const streamHeartbeat = 15 * time.Second
+
+type StreamHandler struct {
+	hub            *services.EventHub
+	allowedOrigins []string
+}
+
+// NewStreamHandler creates a new instance of StreamHandler with the given hub.
+// WebSocket connections are accepted from the server's own origin and from
+// allowedOrigins.
+func NewStreamHandler(hub *services.EventHub, allowedOrigins []string) *StreamHandler {
+	return &StreamHandler{
+		hub:            hub,
+		allowedOrigins: allowedOrigins,
+	}
+}
+
+// StreamFarm streams the live events of a farm as Server-Sent Events. Clients
+// resume with the Last-Event-ID header, or ?lastEventId= for clients that
+// cannot set headers.
+func (h *StreamHandler) StreamFarm(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	lastID, _ := strconv.ParseUint(lastEventID, 10, 64)
+
+	farmID := c.Param("id")
+	sub, backlog, reset := h.hub.Subscribe([]string{farmID}, lastID)
+	defer h.hub.Unsubscribe(sub)
+
+	// The server write timeout would cut the stream, it ends when either side leaves
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	if reset {
+		c.Render(-1, sse.Event{Event: domain.EventReset, Data: gin.H{"farmId": farmID}})
+	}
+	for _, event := range backlog {
+		c.Render(-1, toSSE(event))
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind, the client reconnects and resumes
+				return false
+			}
+			c.Render(-1, toSSE(event))
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// toSSE converts a stream event to a Server-Sent Event
+func toSSE(event domain.StreamEvent) sse.Event {
+	return sse.Event{
+		Id:    strconv.FormatUint(event.ID, 10),
+		Event: event.Type,
+		Data:  event,
+	}
+}
+
+// streamCommand is a subscription change sent by WebSocket clients
+type streamCommand struct {
+	Action      string `json:"action"` // subscribe or unsubscribe
+	FarmID      string `json:"farmId"`
+	LastEventID uint64 `json:"lastEventId"`
+}
+
+// StreamWebSocket streams farm events over a WebSocket. Farms given with
+// ?farmId= are subscribed on connect, resuming after ?lastEventId=; more can be
+// added or removed with
+// {"action": "subscribe"|"unsubscribe", "farmId": "...", "lastEventId": n}.
+func (h *StreamHandler) StreamWebSocket(c *gin.Context) {
+	lastID, _ := strconv.ParseUint(c.Query("lastEventId"), 10, 64)
+
+	server := websocket.Server{Handshake: h.checkOrigin, Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		// The server read timeout would cut the connection, clients may only
+		// listen and it ends when either side leaves
+		_ = ws.SetReadDeadline(time.Time{})
+
+		sub, backlog, reset := h.hub.Subscribe(c.QueryArray("farmId"), lastID)
+		defer h.hub.Unsubscribe(sub)
+
+		// Subscription changes are applied by the writer below so a farm's
+		// backlog always goes out before its live events
+		commands := make(chan streamCommand)
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			defer close(commands)
+			for {
+				var cmd streamCommand
+				if err := websocket.JSON.Receive(ws, &cmd); err != nil {
+					return
+				}
+				select {
+				case commands <- cmd:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		send := func(event domain.StreamEvent) bool {
+			_ = ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			return websocket.JSON.Send(ws, event) == nil
+		}
+		sendAll := func(farmID string, backlog []domain.StreamEvent, reset bool) bool {
+			if reset && !send(domain.StreamEvent{Type: domain.EventReset, FarmID: farmID, Timestamp: time.Now()}) {
+				return false
+			}
+			for _, event := range backlog {
+				if !send(event) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if !sendAll("", backlog, reset) {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok || !send(event) {
+					return
+				}
+			case cmd, ok := <-commands:
+				if !ok {
+					return
+				}
+				switch cmd.Action {
+				case "subscribe":
+					backlog, reset := h.hub.AddFarm(sub, cmd.FarmID, cmd.LastEventID)
+					if !sendAll(cmd.FarmID, backlog, reset) {
+						return
+					}
+				case "unsubscribe":
+					h.hub.RemoveFarm(sub, cmd.FarmID)
+				}
+			case <-heartbeat.C:
+				_ = ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				if err := websocket.Message.Send(ws, `{"type":"ping"}`); err != nil {
+					return
+				}
+			}
+		}
+	}}
+
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin rejects WebSocket handshakes from browser pages served by other
+// origins. Clients that send no Origin are not browsers and are accepted.
+func (h *StreamHandler) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(parsed.Host, req.Host) {
+		return nil
+	}
+	for _, allowed := range h.allowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenHandler struct {
+	tokenService *services.TokenService
+}
+
+// NewTokenHandler creates a new instance of TokenHandler with the given services
+func NewTokenHandler(tokenService *services.TokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// TokenizeFarm defines the Stellar asset of a farm's shares
+func (h *TokenHandler) TokenizeFarm(c *gin.Context) {
+	token, err := h.tokenService.Tokenize(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": token})
+}
+
+// GetToken returns the Stellar asset of a farm's shares
+func (h *TokenHandler) GetToken(c *gin.Context) {
+	token, err := h.tokenService.Token(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": token})
+}
+
+// BuildTrustline returns an unsigned transaction trusting a farm's asset
+func (h *TokenHandler) BuildTrustline(c *gin.Context) {
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	envelope, err := h.tokenService.TrustlineTransaction(c.Param("id"), req.Address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"transaction": envelope}})
+}
+
+// SubmitTrustline relays a signed trustline transaction of the session's
+// account to the network
+func (h *TokenHandler) SubmitTrustline(c *gin.Context) {
+	var req struct {
+		Transaction string `json:"transaction"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	hash, err := h.tokenService.SubmitTrustline(currentSession(c), req.Transaction)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"hash": hash}})
+}
+
+// SyncTokens brings a farm's token balances in line with its owners
+func (h *TokenHandler) SyncTokens(c *gin.Context) {
+	report, err := h.tokenService.Sync(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": report})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UtilityHandler struct {
+	utilityService *services.UtilityService
+}
+
+// NewUtilityHandler creates a new instance of UtilityHandler with the given services
+func NewUtilityHandler(utilityService *services.UtilityService) *UtilityHandler {
+	return &UtilityHandler{
+		utilityService: utilityService,
+	}
+}
+
+// CreateFacility groups farms that share energy and water meters
+func (h *UtilityHandler) CreateFacility(c *gin.Context) {
+	var facility domain.Facility
+	if err := c.ShouldBindJSON(&facility); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	created, err := h.utilityService.CreateFacility(facility)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// GetFacility returns a facility
+func (h *UtilityHandler) GetFacility(c *gin.Context) {
+	facility, err := h.utilityService.GetFacility(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": facility})
+}
+
+// CreateTariff adds a utility tariff with optional time-of-use periods
+func (h *UtilityHandler) CreateTariff(c *gin.Context) {
+	var tariff domain.Tariff
+	if err := c.ShouldBindJSON(&tariff); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	created, err := h.utilityService.CreateTariff(tariff)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// ListTariffs returns every tariff
+func (h *UtilityHandler) ListTariffs(c *gin.Context) {
+	tariffs, err := h.utilityService.ListTariffs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": tariffs})
+}
+
+// CreateMeter registers an energy or water meter on a farm or facility
+func (h *UtilityHandler) CreateMeter(c *gin.Context) {
+	var meter domain.Meter
+	if err := c.ShouldBindJSON(&meter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	created, err := h.utilityService.CreateMeter(meter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": created})
+}
+
+// ListMeters returns the meters of a farm, including shared facility meters
+func (h *UtilityHandler) ListMeters(c *gin.Context) {
+	meters, err := h.utilityService.ListMeters(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": meters})
+}
+
+// AddMeterReading records the register value of a meter
+func (h *UtilityHandler) AddMeterReading(c *gin.Context) {
+	var reading domain.MeterReading
+	if err := c.ShouldBindJSON(&reading); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	recorded, err := h.utilityService.AddMeterReading(c.Param("id"), reading)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": recorded})
+}
+
+// GetMeterReadings returns the readings of a meter for ?from=&to=, the last
+// 30 days by default
+func (h *UtilityHandler) GetMeterReadings(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	readings, err := h.utilityService.MeterReadings(c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": readings})
+}
+
+// GetConsumption returns the energy and water a farm used for ?from=&to=,
+// the last 30 days by default, and what it cost
+func (h *UtilityHandler) GetConsumption(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 30*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	report, err := h.utilityService.Consumption(c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": report})
+}
+
+// GetCycleReports returns energy, water and cost per kg for every harvested
+// cycle of a farm
+func (h *UtilityHandler) GetCycleReports(c *gin.Context) {
+	reports, err := h.utilityService.CycleReports(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": reports})
+}
+
+// GetHarvestReport returns energy, water and cost per kg for the cycle a
+// harvest ended
+func (h *UtilityHandler) GetHarvestReport(c *gin.Context) {
+	report, err := h.utilityService.CycleReport(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": report})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletService *services.WalletService
+}
+
+// NewWalletHandler creates a new instance of WalletHandler with the given services
+func NewWalletHandler(walletService *services.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// SignUp registers a user for the session's account, bound as its first
+// wallet
+func (h *WalletHandler) SignUp(c *gin.Context) {
+	var req struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	wallet, err := h.walletService.SignUp(currentSession(c), req.Name, req.Email)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": wallet})
+}
+
+// CreateWalletChallenge issues the message a user signs to bind a wallet
+func (h *WalletHandler) CreateWalletChallenge(c *gin.Context) {
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	challenge, err := h.walletService.Challenge(currentSession(c), c.Param("id"), req.Address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": challenge})
+}
+
+// BindWallet binds a wallet to a user with a signed challenge
+func (h *WalletHandler) BindWallet(c *gin.Context) {
+	var req struct {
+		Address   string `json:"address"`
+		Signature string `json:"signature"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	wallet, err := h.walletService.Bind(currentSession(c), c.Param("id"), req.Address, req.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": wallet})
+}
+
+// ListWallets returns the wallets bound to a user
+func (h *WalletHandler) ListWallets(c *gin.Context) {
+	if !requireUser(c, c.Param("id")) {
+		return
+	}
+
+	wallets, err := h.walletService.Wallets(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": wallets})
+}
+
+// UnbindWallet removes a wallet from a user
+func (h *WalletHandler) UnbindWallet(c *gin.Context) {
+	if !requireUser(c, c.Param("id")) {
+		return
+	}
+
+	if err := h.walletService.Unbind(c.Param("id"), c.Param("address")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Wallet unbound"})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"0xFarms-backend/internal/core/domain"
+	"0xFarms-backend/internal/core/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WhatIfHandler struct {
+	whatIfService *services.WhatIfService
+}
+
+// NewWhatIfHandler creates a new instance of WhatIfHandler with the given services
+func NewWhatIfHandler(whatIfService *services.WhatIfService) *WhatIfHandler {
+	return &WhatIfHandler{
+		whatIfService: whatIfService,
+	}
+}
+
+// SimulateFarm projects the farm's health curve, harvest date and yield under
+// hypothetical setpoints or reading trajectories and compares them with the
+// current baseline. Nothing is stored.
+func (h *WhatIfHandler) SimulateFarm(c *gin.Context) {
+	var scenario domain.WhatIfScenario
+	if err := c.ShouldBindJSON(&scenario); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	result, err := h.whatIfService.Simulate(c.Param("id"), scenario)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": result})
+}
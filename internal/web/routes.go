@@ -7,9 +7,129 @@ import (
 )
 
 // SetupAPIRoutes sets up the API routes for the application.
-func SetupAPIRoutes(r *gin.Engine, blogHandler *handlers.BlogHandler, farmHandler *handlers.FarmHandler) {
+func SetupAPIRoutes(r *gin.Engine, blogHandler *handlers.BlogHandler, farmHandler *handlers.FarmHandler,
+	metricHandler *handlers.MetricHandler, rollupHandler *handlers.RollupHandler, alertHandler *handlers.AlertHandler,
+	deviceHandler *handlers.DeviceHandler, streamHandler *handlers.StreamHandler, actuatorHandler *handlers.ActuatorHandler,
+	controlHandler *handlers.ControlHandler, recommendationHandler *handlers.RecommendationHandler,
+	importHandler *handlers.ImportHandler, exportHandler *handlers.ExportHandler,
+	lorawanHandler *handlers.LoRaWANHandler, whatIfHandler *handlers.WhatIfHandler, utilityHandler *handlers.UtilityHandler,
+	investmentHandler *handlers.InvestmentHandler, marketHandler *handlers.MarketHandler,
+	distributionHandler *handlers.DistributionHandler, ownershipHandler *handlers.OwnershipHandler,
+	tokenHandler *handlers.TokenHandler, walletHandler *handlers.WalletHandler,
+	authHandler *handlers.AuthHandler, payoutHandler *handlers.PayoutHandler,
+	paymentHandler *handlers.PaymentHandler, requireAdmin gin.HandlerFunc) {
 
 	r.GET("/blog/save", blogHandler.SaveBlog)
 	r.GET("/blog/:id/get_one_blog", blogHandler.GetABlog)
 	r.GET("/blog/all_blog", blogHandler.GetAllBlogs)
+
+	r.POST("/crops", farmHandler.SaveCropSpecification)
+
+	r.POST("/farms", farmHandler.CreateFarm)
+	r.GET("/farms/:id", farmHandler.GetFarm)
+	r.POST("/farms/:id/readings", farmHandler.AddReading)
+	r.POST("/farms/:id/readings/import", importHandler.ImportReadings)
+	r.GET("/farms/:id/readings/export", exportHandler.ExportReadings)
+	r.GET("/farms/:id/imports", importHandler.ListImports)
+	r.GET("/imports/:id", importHandler.GetImport)
+	r.GET("/imports/:id/rejections", importHandler.GetRejections)
+	r.POST("/imports/:id/cancel", importHandler.CancelImport)
+	r.POST("/imports/:id/resume", importHandler.ResumeImport)
+	r.PUT("/farms/:id/reporting-interval", farmHandler.SetReportingInterval)
+	r.PUT("/farms/:id/reservoir", farmHandler.SetReservoir)
+	r.PUT("/farms/:id/lifecycle", requireAdmin, farmHandler.SetStatus)
+	r.GET("/farms/:id/recommendations", recommendationHandler.GetRecommendation)
+	r.POST("/farms/:id/recommendations", recommendationHandler.CreateRecommendation)
+	r.GET("/farms/:id/recommendations/history", recommendationHandler.RecommendationHistory)
+	r.POST("/recommendations/:id/response", recommendationHandler.RespondToRecommendation)
+	r.GET("/farms/:id/status", deviceHandler.GetFarmStatus)
+	r.GET("/farms/:id/stream", streamHandler.StreamFarm)
+	r.GET("/stream/ws", streamHandler.StreamWebSocket)
+	r.POST("/farms/:id/devices", deviceHandler.RegisterDevice)
+	r.GET("/farms/:id/devices", deviceHandler.ListDevices)
+	r.POST("/lorawan/uplink", lorawanHandler.ReceiveUplink)
+	r.GET("/lorawan/decoders", lorawanHandler.ListDecoders)
+	r.POST("/farms/:id/commands", actuatorHandler.EnqueueCommand)
+	r.GET("/farms/:id/commands", actuatorHandler.CommandHistory)
+	r.GET("/devices/:id/commands", actuatorHandler.PendingCommands)
+	r.POST("/commands/:id/ack", actuatorHandler.AcknowledgeCommand)
+	r.POST("/commands/:id/cancel", actuatorHandler.CancelCommand)
+	r.POST("/farms/:id/control-policies", controlHandler.CreatePolicy)
+	r.GET("/farms/:id/control-policies", controlHandler.ListPolicies)
+	r.GET("/farms/:id/control-decisions", controlHandler.ListDecisions)
+	r.DELETE("/control-policies/:id", controlHandler.DeletePolicy)
+	r.POST("/control-policies/:id/override", controlHandler.OverridePolicy)
+	r.POST("/control-policies/:id/resume", controlHandler.ResumePolicy)
+	r.GET("/farms/:id/rollups", rollupHandler.GetRollups)
+	r.POST("/farms/:id/rollups/rebuild", rollupHandler.RebuildRollups)
+	r.POST("/farms/:id/simulate", whatIfHandler.SimulateFarm)
+	r.POST("/farms/:id/harvests", requireAdmin, farmHandler.RecordHarvest)
+	r.GET("/farms/:id/harvests", farmHandler.ListHarvests)
+	r.GET("/harvests/:id/report", utilityHandler.GetHarvestReport)
+
+	r.PUT("/farms/:id/offering", requireAdmin, investmentHandler.SetOffering)
+	r.DELETE("/farms/:id/offering", requireAdmin, investmentHandler.CloseOffering)
+	r.POST("/farms/:id/investments", authHandler.RequireSession, investmentHandler.Invest)
+	r.GET("/farms/:id/investments", investmentHandler.ListFarmInvestments)
+	r.GET("/investors/:id/investments", authHandler.RequireSession, investmentHandler.ListInvestorInvestments)
+	r.POST("/investments/:id/confirm", requireAdmin, investmentHandler.ConfirmInvestment)
+	r.POST("/investments/:id/refund", requireAdmin, investmentHandler.RefundInvestment)
+	r.POST("/farms/:id/orders", authHandler.RequireSession, marketHandler.PlaceOrder)
+	r.GET("/farms/:id/orders", marketHandler.GetOrderBook)
+	r.POST("/orders/:id/cancel", authHandler.RequireSession, marketHandler.CancelOrder)
+	r.POST("/farms/:id/transfers", authHandler.RequireSession, marketHandler.TransferShares)
+	r.GET("/farms/:id/trades", marketHandler.ListTrades)
+	r.POST("/trades/:id/payment", marketHandler.BuildTradePayment)
+	r.POST("/trades/:id/settle", authHandler.RequireSession, marketHandler.SettleTrade)
+	r.POST("/trades/:id/cancel", requireAdmin, marketHandler.CancelTrade)
+	r.GET("/farms/:id/cap-table", ownershipHandler.GetCapTable)
+	r.GET("/farms/:id/ownership-events", ownershipHandler.ListOwnershipEvents)
+	r.POST("/farms/:id/token", requireAdmin, tokenHandler.TokenizeFarm)
+	r.GET("/farms/:id/token", tokenHandler.GetToken)
+	r.POST("/farms/:id/token/trustline", tokenHandler.BuildTrustline)
+	r.POST("/farms/:id/token/sync", requireAdmin, tokenHandler.SyncTokens)
+	r.POST("/stellar/trustlines", authHandler.RequireSession, tokenHandler.SubmitTrustline)
+	r.POST("/users", authHandler.RequireSession, walletHandler.SignUp)
+	r.POST("/users/:id/wallets/challenge", authHandler.RequireSession, walletHandler.CreateWalletChallenge)
+	r.POST("/users/:id/wallets", authHandler.RequireSession, walletHandler.BindWallet)
+	r.GET("/users/:id/wallets", authHandler.RequireSession, walletHandler.ListWallets)
+	r.DELETE("/users/:id/wallets/:address", authHandler.RequireSession, walletHandler.UnbindWallet)
+	r.GET("/auth", authHandler.GetChallenge)
+	r.POST("/auth", authHandler.Login)
+	r.GET("/auth/session", authHandler.GetSession)
+	r.DELETE("/auth/session", authHandler.Logout)
+	r.POST("/distributions/:id/payout", requireAdmin, payoutHandler.PreparePayout)
+	r.GET("/distributions/:id/payout", payoutHandler.GetDistributionPayout)
+	r.GET("/payouts/:id", payoutHandler.GetPayout)
+	r.POST("/payouts/:id/submit", requireAdmin, payoutHandler.SubmitPayout)
+	r.POST("/payouts/:id/reconcile", requireAdmin, payoutHandler.ReconcilePayout)
+	r.POST("/investments/:id/payment", paymentHandler.BuildPayment)
+	r.GET("/payments", paymentHandler.ListPayments)
+	r.POST("/payments/sync", requireAdmin, paymentHandler.SyncPayments)
+	r.POST("/payments/import", requireAdmin, paymentHandler.ImportPayments)
+	r.POST("/harvests/:id/distributions/preview", distributionHandler.PreviewDistribution)
+	r.POST("/harvests/:id/distributions", requireAdmin, distributionHandler.FinalizeDistribution)
+	r.GET("/farms/:id/distributions", distributionHandler.ListDistributions)
+	r.GET("/distributions/:id", distributionHandler.GetDistribution)
+
+	r.POST("/facilities", utilityHandler.CreateFacility)
+	r.GET("/facilities/:id", utilityHandler.GetFacility)
+	r.POST("/tariffs", utilityHandler.CreateTariff)
+	r.GET("/tariffs", utilityHandler.ListTariffs)
+	r.POST("/meters", utilityHandler.CreateMeter)
+	r.GET("/farms/:id/meters", utilityHandler.ListMeters)
+	r.POST("/meters/:id/readings", utilityHandler.AddMeterReading)
+	r.GET("/meters/:id/readings", utilityHandler.GetMeterReadings)
+	r.GET("/farms/:id/consumption", utilityHandler.GetConsumption)
+	r.GET("/farms/:id/cycle-costs", utilityHandler.GetCycleReports)
+
+	r.GET("/alerts", alertHandler.ListAlerts)
+	r.POST("/alerts/:id/acknowledge", alertHandler.AcknowledgeAlert)
+	r.POST("/alerts/:id/resolve", alertHandler.ResolveAlert)
+	r.GET("/alert-rules", alertHandler.ListAlertRules)
+	r.POST("/alert-rules", alertHandler.CreateAlertRule)
+	r.DELETE("/alert-rules/:id", alertHandler.DeleteAlertRule)
+
+	r.GET("/metrics", metricHandler.ListMetrics)
+	r.POST("/metrics", metricHandler.RegisterMetric)
 }
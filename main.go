@@ -4,6 +4,7 @@ import (
 	"0xFarms-backend/config"
 	"0xFarms-backend/internal/adapters"
 	"0xFarms-backend/internal/core/services"
+	"0xFarms-backend/internal/stellar"
 	"0xFarms-backend/internal/web"
 	"0xFarms-backend/internal/web/handlers"
 	"0xFarms-backend/pkg/logger"
@@ -13,9 +14,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
 )
 
 func main() {
@@ -23,27 +27,214 @@ func main() {
 	// Initialize the logger
 	logger.InitLogger()
 
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if err := runSimulate(os.Args[2:]); err != nil {
+			log.Fatalf("simulate: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			log.Fatalf("import: %v", err)
+		}
+		return
+	}
+
 	// Load the application configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
-	db, _ := adapters.NewMongoAdapter(cfg.MONGO_URL)
+	db, err := adapters.NewMongoAdapter(cfg.MONGO_URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	// Ownership is read in share units everywhere, serving unmigrated farms
+	// would misstate every owner's stake
+	migrated, err := db.MigrateShareUnits()
+	if err != nil {
+		log.Fatalf("Failed to migrate ownership to share units: %v", err)
+	}
+	if migrated > 0 {
+		logger.LogInfo(fmt.Sprintf("Migrated %d documents to share units", migrated))
+	}
+	// Distributions are allocated from ownership history, farms without it
+	// would pay nobody
+	backfilled, err := db.BackfillOwnershipEvents()
+	if err != nil {
+		log.Fatalf("Failed to backfill ownership history: %v", err)
+	}
+	if backfilled > 0 {
+		logger.LogInfo(fmt.Sprintf("Backfilled ownership history of %d farms", backfilled))
+	}
+	metricRegistry := services.NewMetricRegistry(db)
+	if err := metricRegistry.Load(); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to load registered metrics: %v", err))
+	}
 	blogService := services.NewBlogService(db)
-	farmService := services.NewFarmManagementSystemService(db)
+	farmService := services.NewFarmManagementSystemService(db, metricRegistry)
+	rollupService := services.NewRollupService(db, farmService)
+	importService := services.NewImportService(db, farmService, metricRegistry, rollupService, cfg.IMPORT_DIR)
+	if err := importService.Load(); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to resume import jobs: %v", err))
+	}
+	exportService := services.NewExportService(db, metricRegistry, rollupService)
+	lorawanService := services.NewLoRaWANService(db, farmService)
+	whatIfService := services.NewWhatIfService(db, farmService, metricRegistry)
+	utilityService := services.NewUtilityService(db)
+	investmentService := services.NewInvestmentService(db, farmService)
+	ownershipService := services.NewOwnershipService(db)
+	distributionService := services.NewDistributionService(db, ownershipService)
+	ledger, passphrase := stellarLedger(cfg)
+	issuer := stellarAccount(ledger, cfg.STELLAR_ISSUER_SECRET, "issuer")
+	treasury := stellarAccount(ledger, cfg.STELLAR_TREASURY_SECRET, "treasury")
+	tokenService := services.NewTokenService(db, ledger, issuer, passphrase)
+	walletService := services.NewWalletService(db)
+	authService := services.NewAuthService(db, webAuthSigner(cfg), passphrase, webAuthDomain(cfg))
+	payoutService := services.NewPayoutService(db, ledger, treasury, passphrase)
+	paymentIssuers, err := stellar.ParseAssetIssuers(cfg.STELLAR_PAYMENT_ASSETS)
+	if err != nil {
+		log.Fatalf("Invalid Stellar payment assets: %v", err)
+	}
+	marketService := services.NewMarketService(db, ledger, paymentIssuers, time.Duration(cfg.SHARE_LOCKUP_DAYS)*24*time.Hour)
+	var treasuryAddress string
+	if treasury != nil {
+		treasuryAddress = treasury.Address()
+	}
+	paymentService := services.NewPaymentService(db, ledger, investmentService, treasuryAddress, paymentIssuers)
+	eventHub := services.NewEventHub()
+	alertService := services.NewAlertService(db, metricRegistry, eventHub)
+	if err := alertService.Load(); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to load alert rules: %v", err))
+	}
+	deviceService := services.NewDeviceService(db)
+	actuatorService := services.NewActuatorService(db, eventHub)
+	controlService := services.NewControlService(db, metricRegistry, actuatorService)
+	if err := controlService.Load(); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to load control policies: %v", err))
+	}
+	recommendationService := services.NewRecommendationService(db)
+	if err := recommendationService.Load(); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to load dosing recommendations: %v", err))
+	}
+	watchdogService := services.NewWatchdogService(db, alertService)
+	if err := watchdogService.Load(); err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to load reporting watchdog state: %v", err))
+	}
+	farmService.AddReadingObserver(rollupService)
+	farmService.AddReadingObserver(alertService)
+	farmService.AddReadingObserver(watchdogService)
+	farmService.AddReadingObserver(eventHub)
+	farmService.AddReadingObserver(controlService)
+	farmService.AddReadingObserver(recommendationService)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go watchdogService.Run(ctx, time.Minute)
+	go tokenService.Run(ctx, 10*time.Minute)
+	go paymentService.Run(ctx, time.Minute)
 
 	blogHandler := handlers.NewBlogHandler(blogService)
 	farmHandler := handlers.NewFarmHandler(farmService)
+	metricHandler := handlers.NewMetricHandler(metricRegistry)
+	rollupHandler := handlers.NewRollupHandler(rollupService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	deviceHandler := handlers.NewDeviceHandler(deviceService, watchdogService)
+	streamHandler := handlers.NewStreamHandler(eventHub, splitList(cfg.STREAM_ALLOWED_ORIGINS))
+	actuatorHandler := handlers.NewActuatorHandler(actuatorService)
+	controlHandler := handlers.NewControlHandler(controlService)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService)
+	importHandler := handlers.NewImportHandler(importService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	lorawanHandler := handlers.NewLoRaWANHandler(lorawanService, cfg.LORAWAN_WEBHOOK_TOKEN)
+	whatIfHandler := handlers.NewWhatIfHandler(whatIfService)
+	utilityHandler := handlers.NewUtilityHandler(utilityService)
+	investmentHandler := handlers.NewInvestmentHandler(investmentService)
+	marketHandler := handlers.NewMarketHandler(marketService)
+	distributionHandler := handlers.NewDistributionHandler(distributionService)
+	ownershipHandler := handlers.NewOwnershipHandler(ownershipService)
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	walletHandler := handlers.NewWalletHandler(walletService)
+	authHandler := handlers.NewAuthHandler(authService, passphrase)
+	payoutHandler := handlers.NewPayoutHandler(payoutService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
 	router := gin.Default()
-	web.SetupAPIRoutes(router, blogHandler, farmHandler)
+	web.SetupAPIRoutes(router, blogHandler, farmHandler, metricHandler, rollupHandler, alertHandler, deviceHandler,
+		streamHandler, actuatorHandler, controlHandler, recommendationHandler, importHandler, exportHandler,
+		lorawanHandler, whatIfHandler, utilityHandler, investmentHandler, marketHandler, distributionHandler, ownershipHandler,
+		tokenHandler, walletHandler, authHandler, payoutHandler, paymentHandler, handlers.RequireAdmin(cfg.ADMIN_TOKEN))
 
 	// Define the server port
 	PORT := fmt.Sprintf(":%s", cfg.PORT)
 	gin.SetMode(gin.ReleaseMode)
 	gracefulShutdown(router, PORT)
+	importService.Shutdown()
 
 }
 
+// stellarLedger connects to the configured Horizon server, or starts an
+// in-memory ledger when none is configured
+func stellarLedger(cfg config.Config) (stellar.Ledger, string) {
+	passphrase := cfg.STELLAR_NETWORK_PASSPHRASE
+	if passphrase == "" {
+		passphrase = network.TestNetworkPassphrase
+	}
+	if cfg.STELLAR_HORIZON_URL != "" {
+		return stellar.NewHorizonLedger(cfg.STELLAR_HORIZON_URL), passphrase
+	}
+	logger.LogInfo("No Horizon server configured, using an in-memory Stellar ledger")
+	return stellar.NewMemoryLedger(passphrase), passphrase
+}
+
+// stellarAccount loads the platform account with the given role from its
+// secret seed. On an in-memory ledger a missing secret is replaced by a
+// funded random account; against Horizon the role is disabled.
+func stellarAccount(ledger stellar.Ledger, secret, role string) *keypair.Full {
+	if secret != "" {
+		kp, err := keypair.ParseFull(secret)
+		if err != nil {
+			log.Fatalf("Invalid Stellar %s secret: %v", role, err)
+		}
+		return kp
+	}
+
+	memory, ok := ledger.(*stellar.MemoryLedger)
+	if !ok {
+		logger.LogWarning(fmt.Sprintf("No Stellar %s secret configured, its features are disabled", role))
+		return nil
+	}
+	kp := keypair.MustRandom()
+	if err := memory.Fund(kp.Address(), "10000"); err != nil {
+		log.Fatalf("Failed to fund in-memory Stellar %s: %v", role, err)
+	}
+	logger.LogInfo(fmt.Sprintf("Using in-memory Stellar %s %s", role, kp.Address()))
+	return kp
+}
+
+// webAuthSigner returns the key signing login challenges. A generated key
+// keeps working sessions across restarts, but invalidates the challenges
+// outstanding at the time.
+func webAuthSigner(cfg config.Config) *keypair.Full {
+	if cfg.STELLAR_WEB_AUTH_SECRET == "" {
+		signer := keypair.MustRandom()
+		logger.LogInfo(fmt.Sprintf("No web auth secret configured, signing login challenges as %s", signer.Address()))
+		return signer
+	}
+	signer, err := keypair.ParseFull(cfg.STELLAR_WEB_AUTH_SECRET)
+	if err != nil {
+		log.Fatalf("Invalid Stellar web auth secret: %v", err)
+	}
+	return signer
+}
+
+// webAuthDomain returns the home domain of login challenges
+func webAuthDomain(cfg config.Config) string {
+	if cfg.STELLAR_HOME_DOMAIN == "" {
+		return "localhost"
+	}
+	return cfg.STELLAR_HOME_DOMAIN
+}
+
 func gracefulShutdown(router *gin.Engine, port string) {
 	// Create a channel to listen for OS signals
 	quit := make(chan os.Signal, 1)
@@ -82,3 +273,14 @@ func gracefulShutdown(router *gin.Engine, port string) {
 	}
 	log.Println("Server exiting")
 }
+
+// splitList splits a comma separated config value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,226 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decimals is the number of decimal places amounts are kept to, matching
+// the precision of Stellar assets
+const Decimals = 7
+
+// Scale is the number of minor units in one whole unit of currency
+const Scale = 10_000_000
+
+// Amount is an exact decimal sum of money, stored as an integer number of
+// minor units. It renders in JSON as a decimal string so no precision is
+// lost to floating point.
+type Amount int64
+
+// Parse reads a decimal amount such as "12.50" or "-3"
+func Parse(s string) (Amount, error) {
+	v, err := ParseDecimal(s, Decimals)
+	return Amount(v), err
+}
+
+// MustParse parses a constant amount, panicking if it is malformed
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// FromInt returns the amount of whole units
+func FromInt(units int64) Amount {
+	return Amount(units * Scale)
+}
+
+// String renders the amount with trailing zeros removed
+func (a Amount) String() string {
+	return FormatDecimal(int64(a), Decimals)
+}
+
+// Float64 returns the amount as a float, for display and estimates only
+func (a Amount) Float64() float64 {
+	return float64(a) / Scale
+}
+
+// MulDiv returns a*num/den rounded half away from zero, computed without
+// intermediate overflow
+func (a Amount) MulDiv(num, den int64) (Amount, error) {
+	if den == 0 {
+		return 0, errors.New("division by zero")
+	}
+	product := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	d := big.NewInt(den)
+	quotient, remainder := new(big.Int).QuoRem(product, d, new(big.Int))
+	// Round half away from zero
+	if new(big.Int).Abs(new(big.Int).Mul(remainder, big.NewInt(2))).Cmp(new(big.Int).Abs(d)) >= 0 {
+		if (product.Sign() < 0) != (d.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, errors.New("amount overflows")
+	}
+	return Amount(quotient.Int64()), nil
+}
+
+// Allocate splits the amount in proportion to the weights. Shares are
+// rounded down and the minor units left over go one each to the largest
+// remainders, earlier weights first on ties, so the parts always add up to
+// the amount.
+func (a Amount) Allocate(weights []int64) ([]Amount, error) {
+	if a < 0 {
+		return nil, errors.New("cannot allocate a negative amount")
+	}
+	total := new(big.Int)
+	for _, w := range weights {
+		if w < 0 {
+			return nil, errors.New("weights cannot be negative")
+		}
+		total.Add(total, big.NewInt(w))
+	}
+	if total.Sign() == 0 {
+		return nil, errors.New("weights add up to zero")
+	}
+
+	parts := make([]Amount, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	left := int64(a)
+	for i, w := range weights {
+		product := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(w))
+		quotient, remainder := product.QuoRem(product, total, new(big.Int))
+		parts[i] = Amount(quotient.Int64())
+		remainders[i] = remainder
+		left -= quotient.Int64()
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]].Cmp(remainders[order[j]]) > 0
+	})
+	for _, i := range order[:left] {
+		parts[i]++
+	}
+	return parts, nil
+}
+
+// MarshalJSON renders the amount as a decimal string
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number, reading the
+// number's text exactly rather than through a float
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s, err := jsonDecimal(data)
+	if err != nil {
+		return err
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// jsonDecimal returns the text of a JSON number or string
+func jsonDecimal(data []byte) (string, error) {
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	if s == "null" {
+		return "0", nil
+	}
+	return s, nil
+}
+
+// UnmarshalDecimalJSON reads a JSON number or string into an integer with
+// the given number of decimal places
+func UnmarshalDecimalJSON(data []byte, places int) (int64, error) {
+	s, err := jsonDecimal(data)
+	if err != nil {
+		return 0, err
+	}
+	return ParseDecimal(s, places)
+}
+
+// ParseDecimal reads a decimal number into an integer scaled by 10^places.
+// More decimal places than that are an error rather than rounded away.
+func ParseDecimal(s string, places int) (int64, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	frac = strings.TrimRight(frac, "0")
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	if len(frac) > places {
+		return 0, fmt.Errorf("%q has more than %d decimal places", s, places)
+	}
+
+	scale := int64(math.Pow10(places))
+	var w, f int64
+	var err error
+	if whole != "" {
+		if w, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+	if frac != "" {
+		f, _ = strconv.ParseInt(frac+strings.Repeat("0", places-len(frac)), 10, 64)
+	}
+	if w > (math.MaxInt64-f)/scale {
+		return 0, fmt.Errorf("%q is too large", s)
+	}
+
+	v := w*scale + f
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// FormatDecimal renders an integer scaled by 10^places as a decimal number
+// without trailing zeros
+func FormatDecimal(v int64, places int) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign, u = "-", uint64(-v)
+	}
+	scale := uint64(math.Pow10(places))
+	whole, frac := u/scale, u%scale
+	if frac == 0 {
+		return sign + strconv.FormatUint(whole, 10)
+	}
+	fracText := strconv.FormatUint(frac, 10)
+	fracText = strings.Repeat("0", places-len(fracText)) + fracText
+	return sign + strconv.FormatUint(whole, 10) + "." + strings.TrimRight(fracText, "0")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
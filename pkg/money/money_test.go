@@ -0,0 +1,179 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		places  int
+		want    int64
+		wantErr bool
+	}{
+		{name: "whole", in: "3", places: 7, want: 30_000_000},
+		{name: "fraction", in: "12.50", places: 7, want: 125_000_000},
+		{name: "negative", in: "-3", places: 7, want: -30_000_000},
+		{name: "plus sign", in: "+1.5", places: 7, want: 15_000_000},
+		{name: "no whole part", in: ".5", places: 7, want: 5_000_000},
+		{name: "no fraction digits", in: "7.", places: 7, want: 70_000_000},
+		{name: "smallest unit", in: "0.0000001", places: 7, want: 1},
+		{name: "trailing zeros past places", in: "1.00000000", places: 7, want: 10_000_000},
+		{name: "surrounding space", in: " 2.5 ", places: 4, want: 25_000},
+		{name: "largest", in: "922337203685.4775807", places: 7, want: math.MaxInt64},
+		{name: "too many places", in: "0.00000001", places: 7, wantErr: true},
+		{name: "too large", in: "922337203685.4775808", places: 7, wantErr: true},
+		{name: "empty", in: "", places: 7, wantErr: true},
+		{name: "sign only", in: "-", places: 7, wantErr: true},
+		{name: "letters", in: "abc", places: 7, wantErr: true},
+		{name: "two points", in: "1.2.3", places: 7, wantErr: true},
+		{name: "exponent", in: "1e5", places: 7, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDecimal(tt.in, tt.places)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseDecimal(%q) = %d, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDecimal(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Amount
+		num, den int64
+		want     Amount
+		wantErr  bool
+	}{
+		{name: "exact", amount: 12, num: 1, den: 3, want: 4},
+		{name: "rounds down below half", amount: 10, num: 1, den: 3, want: 3},
+		{name: "rounds up above half", amount: 20, num: 1, den: 3, want: 7},
+		{name: "half rounds away from zero", amount: 5, num: 1, den: 2, want: 3},
+		{name: "negative half rounds away from zero", amount: -5, num: 1, den: 2, want: -3},
+		{name: "negative divisor", amount: 1, num: 1, den: -2, want: -1},
+		{name: "no intermediate overflow", amount: math.MaxInt64, num: 3, den: 3, want: math.MaxInt64},
+		{name: "overflow", amount: math.MaxInt64, num: 2, den: 1, wantErr: true},
+		{name: "division by zero", amount: 1, num: 1, den: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.amount.MulDiv(tt.num, tt.den)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MulDiv = %d, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("%d*%d/%d = %d, want %d", tt.amount, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Amount
+		weights []int64
+		want    []Amount
+		wantErr bool
+	}{
+		{name: "even", amount: 9, weights: []int64{1, 1, 1}, want: []Amount{3, 3, 3}},
+		{name: "ties go to earlier weights", amount: 10, weights: []int64{1, 1, 1}, want: []Amount{4, 3, 3}},
+		{name: "largest remainder first", amount: 100, weights: []int64{1, 2}, want: []Amount{33, 67}},
+		{name: "several left over", amount: 11, weights: []int64{3, 3, 3, 1}, want: []Amount{4, 3, 3, 1}},
+		{name: "zero weight gets nothing", amount: 7, weights: []int64{0, 1}, want: []Amount{0, 7}},
+		{name: "zero amount", amount: 0, weights: []int64{1, 2}, want: []Amount{0, 0}},
+		{name: "negative amount", amount: -1, weights: []int64{1}, wantErr: true},
+		{name: "negative weight", amount: 1, weights: []int64{2, -1}, wantErr: true},
+		{name: "zero weights", amount: 1, weights: []int64{0, 0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.amount.Allocate(tt.weights)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Allocate = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+			var sum Amount
+			for _, part := range got {
+				sum += part
+			}
+			if sum != tt.amount {
+				t.Errorf("parts add up to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	data, err := json.Marshal(MustParse("12.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"12.5"` {
+		t.Errorf("marshalled as %s, want \"12.5\"", data)
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{name: "string", in: `"12.5"`, want: 125_000_000},
+		{name: "number", in: `12.5`, want: 125_000_000},
+		{name: "number past float precision", in: `0.1000001`, want: 1_000_001},
+		{name: "null", in: `null`, want: 0},
+		{name: "too many places", in: `"0.00000001"`, wantErr: true},
+		{name: "not a number", in: `"twelve"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Amount
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("unmarshalled %s as %d, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("unmarshalled %s as %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
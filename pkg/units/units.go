@@ -0,0 +1,134 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Dimension names
+const (
+	Temperature       = "temperature"
+	Conductivity      = "conductivity"
+	MixingRatio       = "mixing_ratio"
+	RelativeHumidity  = "relative_humidity"
+	Pressure          = "pressure"
+	MassConcentration = "mass_concentration"
+	PPFD              = "ppfd"
+	PH                = "ph"
+)
+
+// AbsoluteHumidity is the unit symbol for absolute humidity in grams of water
+// vapour per cubic metre. Converting it to relative humidity needs the air
+// temperature, so it is handled by AbsoluteToRelative and RelativeToAbsolute
+// instead of Convert.
+const AbsoluteHumidity = "g/m3"
+
+// unit converts to its dimension's base unit as base = value*scale + offset
+type unit struct {
+	scale  float64
+	offset float64
+}
+
+// dimensions lists the units of every dimension, keyed by lower-cased symbol.
+// Some symbols such as "ppm" appear in several dimensions, so callers that
+// know the dimension should use ConvertWithin.
+var dimensions = map[string]map[string]unit{
+	Temperature: {
+		"c": {1, 0},
+		"f": {5.0 / 9.0, -32 * 5.0 / 9.0},
+		"k": {1, -273.15},
+	},
+	// Nutrient strength on the EC scale. The normalized nutrient "ratio" the
+	// crop specifications use is 1.0 per mS/cm; TDS meters report ppm on the
+	// 500 (NaCl) or 700 (442) conversion scale.
+	Conductivity: {
+		"ms/cm":  {1, 0},
+		"ratio":  {1, 0},
+		"ec":     {1, 0},
+		"ds/m":   {1, 0},
+		"us/cm":  {0.001, 0},
+		"ppm":    {1.0 / 500, 0},
+		"ppm500": {1.0 / 500, 0},
+		"ppm700": {1.0 / 700, 0},
+	},
+	MixingRatio: {
+		"ppm": {1, 0},
+		"ppb": {0.001, 0},
+		"%":   {10000, 0},
+	},
+	RelativeHumidity: {
+		"%":        {1, 0},
+		"%rh":      {1, 0},
+		"fraction": {100, 0},
+	},
+	Pressure: {
+		"kpa":  {1, 0},
+		"pa":   {0.001, 0},
+		"hpa":  {0.1, 0},
+		"mbar": {0.1, 0},
+		"psi":  {6.894757, 0},
+	},
+	MassConcentration: {
+		"mg/l": {1, 0},
+		"ppm":  {1, 0}, // in water 1 ppm is 1 mg/L
+		"g/l":  {1000, 0},
+	},
+	PPFD: {
+		"umol/m2/s": {1, 0},
+		"µmol/m2/s": {1, 0},
+	},
+	PH: {
+		"ph": {1, 0},
+	},
+}
+
+var dimensionOrder = []string{Temperature, Conductivity, MixingRatio, RelativeHumidity, Pressure, MassConcentration, PPFD, PH}
+
+// Convert converts a value between two units, using the first dimension that
+// contains both symbols
+func Convert(value float64, from, to string) (float64, error) {
+	for _, d := range dimensionOrder {
+		if v, err := ConvertWithin(d, value, from, to); err == nil {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+}
+
+// ConvertWithin converts a value between two units of the given dimension
+func ConvertWithin(dimension string, value float64, from, to string) (float64, error) {
+	fu, ok := dimensions[dimension][strings.ToLower(from)]
+	if !ok {
+		return 0, fmt.Errorf("unit %s is not a %s unit", from, dimension)
+	}
+	tu, ok := dimensions[dimension][strings.ToLower(to)]
+	if !ok {
+		return 0, fmt.Errorf("unit %s is not a %s unit", to, dimension)
+	}
+	if strings.EqualFold(from, to) {
+		return value, nil
+	}
+
+	base := value*fu.scale + fu.offset
+	return (base - tu.offset) / tu.scale, nil
+}
+
+// saturationVapourDensity returns the water vapour density of saturated air in
+// g/m3 at the given temperature in Celsius (Magnus formula)
+func saturationVapourDensity(tempC float64) float64 {
+	pressure := 6.112 * math.Exp(17.67*tempC/(tempC+243.5)) // hPa
+	return pressure * 100 * 2.1674 / (273.15 + tempC)
+}
+
+// AbsoluteToRelative converts absolute humidity in g/m3 to relative humidity
+// in percent at the given air temperature in Celsius
+func AbsoluteToRelative(absolute, tempC float64) float64 {
+	return absolute / saturationVapourDensity(tempC) * 100
+}
+
+// RelativeToAbsolute converts relative humidity in percent to absolute
+// humidity in g/m3 at the given air temperature in Celsius
+func RelativeToAbsolute(relative, tempC float64) float64 {
+	return relative / 100 * saturationVapourDensity(tempC)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"0xFarms-backend/config"
+	"0xFarms-backend/internal/adapters"
+	"0xFarms-backend/internal/core/services"
+	"0xFarms-backend/internal/simulator"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// runSimulate implements the simulate subcommand, which creates farms or picks
+// existing ones and feeds them synthetic sensor readings
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	target := fs.String("target", "service", "where readings go: service (straight to the database) or http")
+	url := fs.String("url", "http://localhost:8080", "server base URL for the http target")
+	farmCount := fs.Int("farms", 1, "number of farms to create")
+	crops := fs.String("crops", "lettuce", "comma-separated crop types, assigned to new farms in turn")
+	farmIDs := fs.String("farm-ids", "", "comma-separated existing farm IDs to simulate instead of creating farms")
+	width := fs.Float64("width", 10, "width of new farms in meters")
+	height := fs.Float64("height", 5, "height of new farms in meters")
+	interval := fs.Duration("interval", 5*time.Minute, "time between two readings of a farm")
+	count := fs.Int("count", 288, "readings per farm, 0 to run until interrupted")
+	start := fs.String("start", "", "RFC3339 timestamp of the first reading (default: so the last reading is now)")
+	rate := fs.Float64("rate", 0, "readings per second across all farms, 0 for unthrottled")
+	workers := fs.Int("workers", 4, "concurrent senders")
+	realtime := fs.Bool("realtime", false, "stamp readings with the wall clock and wait -interval between them")
+	noise := fs.Float64("noise", 1, "sensor noise multiplier")
+	drift := fs.Float64("drift", 1, "pH and nutrient drift multiplier")
+	dropout := fs.Float64("dropout", 0.01, "chance per reading that a farm goes quiet for a while")
+	faults := fs.Float64("faults", 0.005, "chance per reading that a sensor develops a fault")
+	faultKinds := fs.String("fault-kinds", "", "comma-separated faults to inject: stuck, spike, offset (default all)")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "random seed, fix it for repeatable runs")
+	fs.Parse(args)
+
+	if *interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	var sink simulator.Sink
+	switch *target {
+	case "service":
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return err
+		}
+		db, err := adapters.NewMongoAdapter(cfg.MONGO_URL)
+		if err != nil {
+			return err
+		}
+		metricRegistry := services.NewMetricRegistry(db)
+		if err := metricRegistry.Load(); err != nil {
+			return err
+		}
+		alertService := services.NewAlertService(db, metricRegistry, nil)
+		if err := alertService.Load(); err != nil {
+			return err
+		}
+		// Feed the derived data dashboards read, the full pipeline runs
+		// behind the http target
+		farmService := services.NewFarmManagementSystemService(db, metricRegistry)
+		farmService.AddReadingObserver(services.NewRollupService(db, farmService))
+		farmService.AddReadingObserver(alertService)
+		sink = simulator.NewServiceSink(db, farmService)
+	case "http":
+		sink = simulator.NewHTTPSink(*url)
+	default:
+		return fmt.Errorf("unknown target %q", *target)
+	}
+
+	simCfg := simulator.Config{
+		Noise:       *noise,
+		Drift:       *drift,
+		DropoutRate: *dropout,
+		FaultRate:   *faults,
+	}
+	if *faultKinds != "" {
+		simCfg.FaultKinds = strings.Split(*faultKinds, ",")
+		for _, kind := range simCfg.FaultKinds {
+			switch kind {
+			case simulator.FaultStuck, simulator.FaultSpike, simulator.FaultOffset:
+			default:
+				return fmt.Errorf("unknown fault kind %q", kind)
+			}
+		}
+	}
+
+	opts := simulator.RunOptions{
+		Interval: *interval,
+		Count:    *count,
+		Rate:     *rate,
+		Workers:  *workers,
+		Realtime: *realtime,
+		Start:    time.Now(),
+	}
+	if *start != "" {
+		t, err := time.Parse(time.RFC3339, *start)
+		if err != nil {
+			return fmt.Errorf("invalid start: %v", err)
+		}
+		opts.Start = t
+	} else if !*realtime && *count > 0 {
+		opts.Start = time.Now().Add(-time.Duration(*count-1) * *interval)
+	}
+
+	// Create the farms, or look up the crops of the existing ones
+	type plan struct{ id, crop string }
+	var plans []plan
+	if *farmIDs != "" {
+		for _, id := range strings.Split(*farmIDs, ",") {
+			crop, err := sink.FarmCrop(id)
+			if err != nil {
+				return fmt.Errorf("farm %s: %v", id, err)
+			}
+			plans = append(plans, plan{id, crop})
+		}
+	} else {
+		cropTypes := strings.Split(*crops, ",")
+		for i := 0; i < *farmCount; i++ {
+			crop := cropTypes[i%len(cropTypes)]
+			id, err := sink.CreateFarm(*width, *height, crop)
+			if err != nil {
+				return fmt.Errorf("creating %s farm: %v", crop, err)
+			}
+			log.Printf("Created %s farm %s", crop, id)
+			plans = append(plans, plan{id, crop})
+		}
+	}
+	if len(plans) == 0 {
+		return errors.New("no farms to simulate")
+	}
+
+	farms := make([]*simulator.Farm, 0, len(plans))
+	for i, p := range plans {
+		targets, err := sink.CropTargets(p.crop)
+		if err != nil {
+			return fmt.Errorf("crop %s: %v", p.crop, err)
+		}
+		farms = append(farms, simulator.NewFarm(p.id, p.crop, targets, opts.Start, simCfg, *seed+int64(i)))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("Simulating %d farms through the %s target", len(farms), *target)
+	stats := simulator.Run(ctx, sink, farms, opts)
+	log.Printf("Sent %d readings (%d dropped, %d rejected) in %s, %.1f readings/s, latency avg %s max %s",
+		stats.Sent, stats.Dropped, stats.Rejected, stats.Elapsed.Round(time.Millisecond), stats.Throughput(),
+		stats.AvgLatency.Round(time.Microsecond), stats.MaxLatency.Round(time.Microsecond))
+	if stats.LastError != "" {
+		log.Printf("Last rejection: %s", stats.LastError)
+	}
+	return nil
+}